@@ -0,0 +1,183 @@
+package graphql
+
+import (
+	"context"
+	"goblog/app/models/article"
+	"goblog/app/models/tag"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// ArticleResolver 文章
+type ArticleResolver struct {
+	article article.Article
+}
+
+// ID 文章 ID
+func (a *ArticleResolver) ID() gql.ID {
+	return gql.ID(a.article.GetStringID())
+}
+
+// Title 标题
+func (a *ArticleResolver) Title() string {
+	return a.article.Title
+}
+
+// Slug 链接中使用的 slug
+func (a *ArticleResolver) Slug() string {
+	return a.article.Slug
+}
+
+// Body Markdown 正文
+func (a *ArticleResolver) Body() string {
+	return a.article.Body
+}
+
+// BodyHTML 渲染后的 HTML
+func (a *ArticleResolver) BodyHTML() string {
+	return string(a.article.RenderedBody())
+}
+
+// Status 状态
+func (a *ArticleResolver) Status() string {
+	return a.article.Status
+}
+
+// PublishedAt 发布时间
+func (a *ArticleResolver) PublishedAt() *gql.Time {
+	if a.article.PublishedAt == nil {
+		return nil
+	}
+	return &gql.Time{Time: *a.article.PublishedAt}
+}
+
+// CreatedAt 创建时间
+func (a *ArticleResolver) CreatedAt() gql.Time {
+	return gql.Time{Time: a.article.CreatedAt}
+}
+
+// UpdatedAt 更新时间
+func (a *ArticleResolver) UpdatedAt() gql.Time {
+	return gql.Time{Time: a.article.UpdatedAt}
+}
+
+// URL 文章链接
+func (a *ArticleResolver) URL() string {
+	return a.article.Link()
+}
+
+// Tags 标签名称
+func (a *ArticleResolver) Tags() []string {
+	return tag.Names(a.article.Tags)
+}
+
+// CommentCount 已通过的评论数量
+func (a *ArticleResolver) CommentCount() int32 {
+	return int32(a.article.CommentCount)
+}
+
+// CommentsClosed 是否关闭评论
+func (a *ArticleResolver) CommentsClosed() bool {
+	return a.article.CommentsClosed
+}
+
+// Author 作者，同一请求中的作者合并为一次查询
+func (a *ArticleResolver) Author(ctx context.Context) (*UserResolver, error) {
+	if a.article.User.ID > 0 {
+		return &UserResolver{user: a.article.User}, nil
+	}
+	return loadUser(ctx, a.article.UserID)
+}
+
+// Category 分类，同一请求中的分类合并为一次查询
+func (a *ArticleResolver) Category(ctx context.Context) (*CategoryResolver, error) {
+	return loadCategory(ctx, a.article.CategoryID)
+}
+
+// ArticleEdgeResolver 文章列表中的一条
+type ArticleEdgeResolver struct {
+	node *ArticleResolver
+}
+
+// Cursor 游标
+func (e *ArticleEdgeResolver) Cursor() string {
+	return encodeCursor(e.node.article.ID)
+}
+
+// Node 文章
+func (e *ArticleEdgeResolver) Node() *ArticleResolver {
+	return e.node
+}
+
+// ArticleConnectionResolver 文章列表
+type ArticleConnectionResolver struct {
+	articles []article.Article
+	hasNext  bool
+	filter   article.CursorFilter
+
+	// count 批量统计的文章总数，为 nil 时按 filter 统计
+	count func() (int64, error)
+}
+
+// articleConnection 按游标读取一页已发布的文章
+func articleConnection(ctx context.Context, filter article.CursorFilter, args pageArgs) (*ArticleConnectionResolver, error) {
+	afterID, err := args.afterID()
+	if err != nil {
+		return nil, err
+	}
+
+	// 多读取一条，用于判断是否还有下一页
+	limit := args.limit()
+	if err := fromContext(ctx).spend(limit + 1); err != nil {
+		return nil, err
+	}
+	articles, err := article.GetByCursor(filter, afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	return newArticleConnection(ctx, filter, articles, limit), nil
+}
+
+// newArticleConnection 由多读取了一条的文章创建列表，并登记作者和分类以便批量加载
+func newArticleConnection(ctx context.Context, filter article.CursorFilter, articles []article.Article, limit int) *ArticleConnectionResolver {
+	conn := &ArticleConnectionResolver{filter: filter}
+	if len(articles) > limit {
+		articles, conn.hasNext = articles[:limit], true
+	}
+	conn.articles = articles
+
+	data := fromContext(ctx)
+	for _, a := range articles {
+		data.users.Queue(a.UserID)
+		data.categories.Queue(a.CategoryID)
+	}
+	return conn
+}
+
+// Edges 当前页的文章
+func (c *ArticleConnectionResolver) Edges() []*ArticleEdgeResolver {
+	edges := make([]*ArticleEdgeResolver, len(c.articles))
+	for i := range c.articles {
+		edges[i] = &ArticleEdgeResolver{node: &ArticleResolver{article: c.articles[i]}}
+	}
+	return edges
+}
+
+// PageInfo 分页信息
+func (c *ArticleConnectionResolver) PageInfo() PageInfoResolver {
+	p := PageInfoResolver{hasNext: c.hasNext}
+	if len(c.articles) > 0 {
+		p.endID = c.articles[len(c.articles)-1].ID
+	}
+	return p
+}
+
+// TotalCount 符合条件的文章总数，只在查询该字段时统计
+func (c *ArticleConnectionResolver) TotalCount() (int32, error) {
+	if c.count != nil {
+		count, err := c.count()
+		return int32(count), err
+	}
+	count, err := article.CountByCursorFilter(c.filter)
+	return int32(count), err
+}
@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"goblog/app/models/article"
+	"goblog/pkg/dataloader"
+	"sync"
+)
+
+// articleBatch 同一列表中的作者或分类的文章列表，列表中所有节点的 articles 字段合并为一次查询
+type articleBatch struct {
+	data   *requestData
+	column string
+	ids    []uint64
+
+	mu     sync.Mutex
+	pages  map[pageKey]*dataloader.Loader
+	counts *dataloader.Loader
+}
+
+// pageKey 分页参数相同的文章列表才能合并查询
+type pageKey struct {
+	afterID uint64
+	limit   int
+}
+
+// newArticleBatch 为列表中的作者（article.GroupByUser）或分类（article.GroupByCategory）创建批量加载
+func newArticleBatch(ctx context.Context, column string, ids []uint64) *articleBatch {
+	b := &articleBatch{
+		data:   fromContext(ctx),
+		column: column,
+		ids:    ids,
+		pages:  map[pageKey]*dataloader.Loader{},
+	}
+	b.counts = dataloader.New(func(ids []uint64) (map[uint64]interface{}, error) {
+		counts, err := article.CountInGroups(b.column, ids)
+		found := make(map[uint64]interface{}, len(counts))
+		for id, count := range counts {
+			found[id] = count
+		}
+		return found, err
+	})
+	b.counts.Queue(ids...)
+	return b
+}
+
+// connection 读取 id 对应的作者或分类的一页文章
+func (b *articleBatch) connection(ctx context.Context, id uint64, filter article.CursorFilter, args pageArgs) (*ArticleConnectionResolver, error) {
+	afterID, err := args.afterID()
+	if err != nil {
+		return nil, err
+	}
+
+	// 多读取一条，用于判断是否还有下一页
+	limit := args.limit()
+	v, err := b.page(afterID, limit+1).Load(id)
+	if err != nil {
+		return nil, err
+	}
+	articles, _ := v.([]article.Article)
+
+	conn := newArticleConnection(ctx, filter, articles, limit)
+	conn.count = func() (int64, error) {
+		v, err := b.counts.Load(id)
+		count, _ := v.(int64)
+		return count, err
+	}
+	return conn, nil
+}
+
+// page 分页参数对应的加载器，第一次使用时创建，并登记列表中所有的 ID
+func (b *articleBatch) page(afterID uint64, limit int) *dataloader.Loader {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := pageKey{afterID: afterID, limit: limit}
+	if loader, ok := b.pages[key]; ok {
+		return loader
+	}
+
+	loader := dataloader.New(func(ids []uint64) (map[uint64]interface{}, error) {
+		if err := b.data.spend(len(ids) * limit); err != nil {
+			return nil, err
+		}
+		articles, err := article.GetByCursorInGroups(b.column, ids, afterID, limit)
+		found := make(map[uint64]interface{}, len(ids))
+		for _, a := range articles {
+			groupID := a.UserID
+			if b.column == article.GroupByCategory {
+				groupID = a.CategoryID
+			}
+			list, _ := found[groupID].([]article.Article)
+			found[groupID] = append(list, a)
+		}
+		return found, err
+	})
+	loader.Queue(b.ids...)
+	b.pages[key] = loader
+	return loader
+}
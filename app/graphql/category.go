@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// CategoryResolver 分类
+type CategoryResolver struct {
+	category category.Category
+
+	// articles 所在列表的批量加载，单独获取的分类为 nil
+	articles *articleBatch
+}
+
+// loadCategory 通过加载器读取分类，不存在时返回 nil
+func loadCategory(ctx context.Context, id uint64) (*CategoryResolver, error) {
+	v, err := fromContext(ctx).categories.Load(id)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return &CategoryResolver{category: v.(category.Category)}, nil
+}
+
+// ID 分类 ID
+func (c *CategoryResolver) ID() gql.ID {
+	return gql.ID(c.category.GetStringID())
+}
+
+// Name 名称
+func (c *CategoryResolver) Name() string {
+	return c.category.Name
+}
+
+// Slug 链接中使用的 slug
+func (c *CategoryResolver) Slug() string {
+	return c.category.Slug
+}
+
+// URL 分类链接
+func (c *CategoryResolver) URL() string {
+	return c.category.Link()
+}
+
+// Articles 分类下已发布的文章，同一列表中的分类合并为一次查询
+func (c *CategoryResolver) Articles(ctx context.Context, args pageArgs) (*ArticleConnectionResolver, error) {
+	filter := article.CursorFilter{CategoryID: c.category.ID}
+	if c.articles != nil {
+		return c.articles.connection(ctx, c.category.ID, filter, args)
+	}
+	return articleConnection(ctx, filter, args)
+}
+
+// CategoryEdgeResolver 分类列表中的一条
+type CategoryEdgeResolver struct {
+	node *CategoryResolver
+}
+
+// Cursor 游标
+func (e *CategoryEdgeResolver) Cursor() string {
+	return encodeCursor(e.node.category.ID)
+}
+
+// Node 分类
+func (e *CategoryEdgeResolver) Node() *CategoryResolver {
+	return e.node
+}
+
+// CategoryConnectionResolver 分类列表
+type CategoryConnectionResolver struct {
+	categories []category.Category
+	hasNext    bool
+	articles   *articleBatch
+}
+
+// Edges 当前页的分类
+func (c *CategoryConnectionResolver) Edges() []*CategoryEdgeResolver {
+	edges := make([]*CategoryEdgeResolver, len(c.categories))
+	for i := range c.categories {
+		edges[i] = &CategoryEdgeResolver{node: &CategoryResolver{category: c.categories[i], articles: c.articles}}
+	}
+	return edges
+}
+
+// PageInfo 分页信息
+func (c *CategoryConnectionResolver) PageInfo() PageInfoResolver {
+	p := PageInfoResolver{hasNext: c.hasNext}
+	if len(c.categories) > 0 {
+		p.endID = c.categories[len(c.categories)-1].ID
+	}
+	return p
+}
+
+// TotalCount 分类总数
+func (c *CategoryConnectionResolver) TotalCount() (int32, error) {
+	count, err := category.Count()
+	return int32(count), err
+}
@@ -0,0 +1,91 @@
+package graphql
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// maxFirst 每页最多的条数
+const maxFirst = 100
+
+// errInvalidCursor 游标无法解析
+var errInvalidCursor = errors.New("无效的游标")
+
+// errInvalidID ID 无法解析
+var errInvalidID = errors.New("无效的 ID")
+
+// pageArgs 列表的分页参数
+type pageArgs struct {
+	First int32
+	After *string
+}
+
+// limit 每页条数，取值 1～100
+func (args pageArgs) limit() int {
+	switch {
+	case args.First < 1:
+		return 1
+	case args.First > maxFirst:
+		return maxFirst
+	}
+	return int(args.First)
+}
+
+// afterID 解析 after 游标中的 ID，没有游标时为 0
+func (args pageArgs) afterID() (uint64, error) {
+	if args.After == nil || len(*args.After) == 0 {
+		return 0, nil
+	}
+	return decodeCursor(*args.After)
+}
+
+// encodeCursor 游标为 Base64 编码的 ID，客户端应将其视为不透明的字符串
+func encodeCursor(id uint64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("cursor:" + strconv.FormatUint(id, 10)))
+}
+
+// decodeCursor 解析游标
+func decodeCursor(cursor string) (uint64, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(b), "cursor:") {
+		return 0, errInvalidCursor
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(string(b), "cursor:"), 10, 64)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	return id, nil
+}
+
+// parseID 解析 GraphQL ID
+func parseID(id gql.ID) (uint64, error) {
+	n, err := strconv.ParseUint(string(id), 10, 64)
+	if err != nil || n == 0 {
+		return 0, errInvalidID
+	}
+	return n, nil
+}
+
+// PageInfoResolver 分页信息
+type PageInfoResolver struct {
+	hasNext bool
+	endID   uint64
+}
+
+// HasNextPage 是否还有下一页
+func (p PageInfoResolver) HasNextPage() bool {
+	return p.hasNext
+}
+
+// EndCursor 最后一条的游标，列表为空时为 null
+func (p PageInfoResolver) EndCursor() *string {
+	if p.endID == 0 {
+		return nil
+	}
+	cursor := encodeCursor(p.endID)
+	return &cursor
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"goblog/app/models/accesstoken"
+	"goblog/app/models/category"
+	"goblog/app/models/user"
+	"goblog/pkg/dataloader"
+	"sync"
+)
+
+// maxCost 一次请求最多读取的列表条数，嵌套列表的条数乘以父列表的节点数量，如 users(first: 50) 下的 articles(first: 20) 约为 50 × 20 条
+const maxCost = 2000
+
+// errTooComplex 超过了 maxCost
+var errTooComplex = errors.New("查询过于复杂，请减少列表的数量或每页条数")
+
+// contextKey 请求数据在 context 中的键
+type contextKey struct{}
+
+// requestData 一次请求内共享的数据
+type requestData struct {
+	viewer user.User
+	token  accesstoken.AccessToken
+
+	users      *dataloader.Loader
+	categories *dataloader.Loader
+
+	mu   sync.Mutex
+	cost int
+}
+
+// newContext 为一次请求创建加载器，缓存只在该请求内有效
+func newContext(ctx context.Context, viewer user.User, token accesstoken.AccessToken) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestData{
+		viewer: viewer,
+		token:  token,
+		users: dataloader.New(func(ids []uint64) (map[uint64]interface{}, error) {
+			users, err := user.GetByIDs(ids)
+			found := make(map[uint64]interface{}, len(users))
+			for _, u := range users {
+				found[u.ID] = u
+			}
+			return found, err
+		}),
+		categories: dataloader.New(func(ids []uint64) (map[uint64]interface{}, error) {
+			categories, err := category.GetByIDs(ids)
+			found := make(map[uint64]interface{}, len(categories))
+			for _, c := range categories {
+				found[c.ID] = c
+			}
+			return found, err
+		}),
+	})
+}
+
+// fromContext 读取请求数据
+func fromContext(ctx context.Context) *requestData {
+	return ctx.Value(contextKey{}).(*requestData)
+}
+
+// spend 读取列表前累计读取的条数，超过 maxCost 时返回错误，不再查询
+func (data *requestData) spend(cost int) error {
+	data.mu.Lock()
+	defer data.mu.Unlock()
+
+	if data.cost+cost > maxCost {
+		return errTooComplex
+	}
+	data.cost += cost
+	return nil
+}
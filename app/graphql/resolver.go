@@ -0,0 +1,371 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/user"
+	"goblog/app/policies"
+	"goblog/app/requests"
+	"goblog/pkg/types"
+	"sort"
+	"strings"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"gorm.io/gorm"
+)
+
+var (
+	// errTokenRequired 修改数据需要携带 write 权限范围的令牌
+	errTokenRequired = errors.New("需要拥有 write 权限范围的访问令牌")
+	// errUnauthorized 用户的角色没有权限
+	errUnauthorized = errors.New("未授权操作")
+	// errUnverified 未验证邮箱的用户不能发布文章
+	errUnverified = errors.New("请先验证您的邮箱")
+	// errArticleNotFound 文章不存在，或当前用户无权查看
+	errArticleNotFound = errors.New("文章未找到")
+)
+
+// Resolver 查询和修改的入口
+type Resolver struct{}
+
+// Viewer 令牌所属的用户
+func (r *Resolver) Viewer(ctx context.Context) *UserResolver {
+	viewer := fromContext(ctx).viewer
+	if viewer.ID == 0 {
+		return nil
+	}
+	return &UserResolver{user: viewer}
+}
+
+// Article 通过 ID 或 slug 获取文章，不存在时返回 null
+func (r *Resolver) Article(ctx context.Context, args struct {
+	ID   *gql.ID
+	Slug *string
+}) (*ArticleResolver, error) {
+	var _article article.Article
+	var err error
+	switch {
+	case args.ID != nil:
+		id, errID := parseID(*args.ID)
+		if errID != nil {
+			return nil, errID
+		}
+		_article, err = article.Get(types.Uint64ToString(id))
+	case args.Slug != nil:
+		_article, err = article.GetBySlug(*args.Slug)
+	default:
+		return nil, errors.New("需要提供 id 或 slug")
+	}
+
+	if err == gorm.ErrRecordNotFound || (err == nil && !canView(ctx, _article)) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ArticleResolver{article: _article}, nil
+}
+
+// Articles 已发布的文章列表
+func (r *Resolver) Articles(ctx context.Context, args struct {
+	pageArgs
+	Q          *string
+	CategoryID *gql.ID
+	AuthorID   *gql.ID
+}) (*ArticleConnectionResolver, error) {
+	var filter article.CursorFilter
+	var err error
+	if args.Q != nil {
+		filter.Keyword = strings.TrimSpace(*args.Q)
+	}
+	if args.CategoryID != nil {
+		if filter.CategoryID, err = parseID(*args.CategoryID); err != nil {
+			return nil, err
+		}
+	}
+	if args.AuthorID != nil {
+		if filter.UserID, err = parseID(*args.AuthorID); err != nil {
+			return nil, err
+		}
+	}
+	return articleConnection(ctx, filter, args.pageArgs)
+}
+
+// Category 通过 ID 或 slug 获取分类，不存在时返回 null
+func (r *Resolver) Category(ctx context.Context, args struct {
+	ID   *gql.ID
+	Slug *string
+}) (*CategoryResolver, error) {
+	switch {
+	case args.ID != nil:
+		id, err := parseID(*args.ID)
+		if err != nil {
+			return nil, err
+		}
+		return loadCategory(ctx, id)
+	case args.Slug != nil:
+		_category, err := category.GetBySlug(*args.Slug)
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &CategoryResolver{category: _category}, nil
+	}
+	return nil, errors.New("需要提供 id 或 slug")
+}
+
+// Categories 分类列表
+func (r *Resolver) Categories(ctx context.Context, args pageArgs) (*CategoryConnectionResolver, error) {
+	afterID, err := args.afterID()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := args.limit()
+	if err := fromContext(ctx).spend(limit + 1); err != nil {
+		return nil, err
+	}
+	categories, err := category.GetByCursor(afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	conn := &CategoryConnectionResolver{}
+	if len(categories) > limit {
+		categories, conn.hasNext = categories[:limit], true
+	}
+	conn.categories = categories
+
+	// 分类下的文章列表合并为一次查询
+	ids := make([]uint64, len(categories))
+	for i, c := range categories {
+		ids[i] = c.ID
+	}
+	conn.articles = newArticleBatch(ctx, article.GroupByCategory, ids)
+	return conn, nil
+}
+
+// User 通过 ID 获取用户，不存在时返回 null
+func (r *Resolver) User(ctx context.Context, args struct{ ID gql.ID }) (*UserResolver, error) {
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return loadUser(ctx, id)
+}
+
+// Users 用户列表
+func (r *Resolver) Users(ctx context.Context, args pageArgs) (*UserConnectionResolver, error) {
+	afterID, err := args.afterID()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := args.limit()
+	if err := fromContext(ctx).spend(limit + 1); err != nil {
+		return nil, err
+	}
+	users, err := user.GetByCursor(afterID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	conn := &UserConnectionResolver{}
+	if len(users) > limit {
+		users, conn.hasNext = users[:limit], true
+	}
+	conn.users = users
+
+	// 用户发布的文章列表合并为一次查询
+	ids := make([]uint64, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	conn.articles = newArticleBatch(ctx, article.GroupByUser, ids)
+	return conn, nil
+}
+
+// articleInput 创建和更新文章的参数
+type articleInput struct {
+	Title          *string
+	Body           *string
+	Status         *string
+	PublishedAt    *gql.Time
+	CategoryID     *gql.ID
+	Tags           *[]string
+	CommentsClosed *bool
+}
+
+// CreateArticle 发布文章，与 ArticlesController 相同，需要 article.create 权限且已验证邮箱
+func (r *Resolver) CreateArticle(ctx context.Context, args struct{ Input articleInput }) (*ArticlePayloadResolver, error) {
+	// 1. 检查权限
+	viewer, err := writer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !policies.AllowsUser(viewer, "article.create") {
+		return nil, errUnauthorized
+	}
+	if !viewer.IsVerified() {
+		return nil, errUnverified
+	}
+
+	// 2. 验证参数
+	_article := article.Article{UserID: viewer.ID, Status: article.StatusDraft}
+	if errs := args.Input.apply(&_article); len(errs) > 0 {
+		return &ArticlePayloadResolver{errs: errs}, nil
+	}
+
+	// 3. 创建文章
+	if err := _article.Create(); err != nil {
+		return nil, err
+	}
+	return articlePayload(_article)
+}
+
+// UpdateArticle 修改文章，与 ArticlesController 相同，需要 article.update 权限
+func (r *Resolver) UpdateArticle(ctx context.Context, args struct {
+	ID    gql.ID
+	Input articleInput
+}) (*ArticlePayloadResolver, error) {
+	// 1. 读取文章并检查权限
+	viewer, err := writer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id, err := parseID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	_article, err := article.Get(types.Uint64ToString(id))
+	if err == gorm.ErrRecordNotFound {
+		return nil, errArticleNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !policies.AllowsUser(viewer, "article.update", _article) {
+		return nil, errUnauthorized
+	}
+
+	// 2. 验证参数
+	if errs := args.Input.apply(&_article); len(errs) > 0 {
+		return &ArticlePayloadResolver{errs: errs}, nil
+	}
+
+	// 3. 更新文章，提供了标签时一并更新标签
+	if _, err := _article.Update(viewer.ID); err != nil {
+		return nil, err
+	}
+	return articlePayload(_article)
+}
+
+// apply 转换为 requests.ArticleInput 后写入文章并验证，与 REST API 使用相同的规则
+func (input articleInput) apply(_article *article.Article) map[string][]string {
+	data := requests.ArticleInput{
+		Title:          input.Title,
+		Body:           input.Body,
+		Status:         input.Status,
+		CommentsClosed: input.CommentsClosed,
+	}
+	if input.PublishedAt != nil {
+		publishedAt := input.PublishedAt.Time
+		data.PublishedAt = &publishedAt
+	}
+	if input.Tags != nil {
+		data.Tags = *input.Tags
+		if data.Tags == nil {
+			data.Tags = []string{}
+		}
+	}
+	if input.CategoryID != nil {
+		categoryID, err := parseID(*input.CategoryID)
+		if err != nil {
+			return map[string][]string{"category_id": {"分类不存在"}}
+		}
+		data.CategoryID = &categoryID
+	}
+	return data.Apply(_article)
+}
+
+// ArticlePayloadResolver 创建或更新文章的结果，验证失败时 article 为 null
+type ArticlePayloadResolver struct {
+	article *ArticleResolver
+	errs    map[string][]string
+}
+
+// articlePayload 重新读取文章（包括生成的 slug、作者和标签）
+func articlePayload(_article article.Article) (*ArticlePayloadResolver, error) {
+	_article, err := article.Get(_article.GetStringID())
+	if err != nil {
+		return nil, err
+	}
+	return &ArticlePayloadResolver{article: &ArticleResolver{article: _article}}, nil
+}
+
+// Article 创建或更新后的文章
+func (p *ArticlePayloadResolver) Article() *ArticleResolver {
+	return p.article
+}
+
+// Errors 字段的验证错误，字段名与 ArticleInput 一致，如 published_at 为 publishedAt
+func (p *ArticlePayloadResolver) Errors() []*FieldErrorResolver {
+	fields := make([]string, 0, len(p.errs))
+	for field := range p.errs {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	errs := make([]*FieldErrorResolver, len(fields))
+	for i, field := range fields {
+		errs[i] = &FieldErrorResolver{field: camelCase(field), messages: p.errs[field]}
+	}
+	return errs
+}
+
+// FieldErrorResolver 字段的验证错误
+type FieldErrorResolver struct {
+	field    string
+	messages []string
+}
+
+// Field 字段名
+func (e *FieldErrorResolver) Field() string {
+	return e.field
+}
+
+// Messages 错误信息
+func (e *FieldErrorResolver) Messages() []string {
+	return e.messages
+}
+
+// writer 修改数据的用户，需要携带 write 权限范围的令牌
+func writer(ctx context.Context) (user.User, error) {
+	data := fromContext(ctx)
+	if data.viewer.ID == 0 || !data.token.Can("write") {
+		return user.User{}, errTokenRequired
+	}
+	return data.viewer, nil
+}
+
+// canView 已发布的文章所有人可见，其他状态只有可编辑的用户可见
+func canView(ctx context.Context, _article article.Article) bool {
+	if _article.IsPublished() {
+		return true
+	}
+	viewer := fromContext(ctx).viewer
+	return viewer.ID > 0 && policies.AllowsUser(viewer, "article.update", _article)
+}
+
+// camelCase 将验证错误的字段名转换为 GraphQL 的命名，如 category_id 转换为 categoryId
+func camelCase(s string) string {
+	parts := strings.Split(s, "_")
+	for i := 1; i < len(parts); i++ {
+		if len(parts[i]) > 0 {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,156 @@
+// Package graphql 文章、作者和分类的 GraphQL 接口，列表使用游标分页，作者和分类按请求批量加载
+package graphql
+
+import (
+	"context"
+	"goblog/app/models/accesstoken"
+	"goblog/app/models/user"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// schemaString GraphQL schema，# 注释会作为字段说明出现在内省结果中
+const schemaString = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+# RFC 3339 格式的时间
+scalar Time
+
+type Query {
+	# 令牌所属的用户，未携带令牌时为 null
+	viewer: User
+	# 通过 ID 或 slug 获取文章，未发布的文章只有可编辑的用户能获取
+	article(id: ID, slug: String): Article
+	# 已发布的文章，最新的在前
+	articles(first: Int = 20, after: String, q: String, categoryId: ID, authorId: ID): ArticleConnection!
+	# 通过 ID 或 slug 获取分类
+	category(id: ID, slug: String): Category
+	categories(first: Int = 20, after: String): CategoryConnection!
+	user(id: ID!): User
+	users(first: Int = 20, after: String): UserConnection!
+}
+
+type Mutation {
+	# 发布文章，需要 write 权限范围的令牌，status 默认为 draft
+	createArticle(input: ArticleInput!): ArticlePayload!
+	# 修改文章，只修改提供的字段
+	updateArticle(id: ID!, input: ArticleInput!): ArticlePayload!
+}
+
+type Article {
+	id: ID!
+	title: String!
+	slug: String!
+	# Markdown 正文
+	body: String!
+	# 渲染后的 HTML
+	bodyHtml: String!
+	status: String!
+	publishedAt: Time
+	createdAt: Time!
+	updatedAt: Time!
+	url: String!
+	tags: [String!]!
+	commentCount: Int!
+	commentsClosed: Boolean!
+	author: User
+	category: Category
+}
+
+type User {
+	id: ID!
+	name: String!
+	url: String!
+	createdAt: Time!
+	articles(first: Int = 20, after: String): ArticleConnection!
+}
+
+type Category {
+	id: ID!
+	name: String!
+	slug: String!
+	url: String!
+	articles(first: Int = 20, after: String): ArticleConnection!
+}
+
+type PageInfo {
+	hasNextPage: Boolean!
+	# 最后一条的游标，作为下一页的 after 参数
+	endCursor: String
+}
+
+type ArticleEdge {
+	cursor: String!
+	node: Article!
+}
+
+type ArticleConnection {
+	edges: [ArticleEdge!]!
+	pageInfo: PageInfo!
+	totalCount: Int!
+}
+
+type UserEdge {
+	cursor: String!
+	node: User!
+}
+
+type UserConnection {
+	edges: [UserEdge!]!
+	pageInfo: PageInfo!
+	totalCount: Int!
+}
+
+type CategoryEdge {
+	cursor: String!
+	node: Category!
+}
+
+type CategoryConnection {
+	edges: [CategoryEdge!]!
+	pageInfo: PageInfo!
+	totalCount: Int!
+}
+
+input ArticleInput {
+	title: String
+	body: String
+	# draft、published、scheduled 或 archived
+	status: String
+	# 定时发布的时间
+	publishedAt: Time
+	categoryId: ID
+	tags: [String!]
+	commentsClosed: Boolean
+}
+
+# 字段的验证错误
+type FieldError {
+	field: String!
+	messages: [String!]!
+}
+
+type ArticlePayload {
+	article: Article
+	errors: [FieldError!]!
+}
+`
+
+// Schema 解析后的 schema，字段与 Resolver 的方法不匹配时启动即报错
+// 嵌套深度由 MaxDepth 限制，读取的列表条数由 maxCost 限制
+var Schema = gql.MustParseSchema(schemaString, &Resolver{}, gql.MaxDepth(10))
+
+// Params GraphQL 请求参数
+type Params struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// Execute 执行查询，viewer 和 token 为令牌认证的用户和令牌，未认证时为零值
+func Execute(ctx context.Context, viewer user.User, token accesstoken.AccessToken, params Params) *gql.Response {
+	return Schema.Exec(newContext(ctx, viewer, token), params.Query, params.OperationName, params.Variables)
+}
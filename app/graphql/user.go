@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"context"
+	"goblog/app/models/article"
+	"goblog/app/models/user"
+
+	gql "github.com/graph-gophers/graphql-go"
+)
+
+// UserResolver 用户，只包含可以公开的字段
+type UserResolver struct {
+	user user.User
+
+	// articles 所在列表的批量加载，单独获取的用户为 nil
+	articles *articleBatch
+}
+
+// loadUser 通过加载器读取用户，不存在时返回 nil
+func loadUser(ctx context.Context, id uint64) (*UserResolver, error) {
+	v, err := fromContext(ctx).users.Load(id)
+	if err != nil || v == nil {
+		return nil, err
+	}
+	return &UserResolver{user: v.(user.User)}, nil
+}
+
+// ID 用户 ID
+func (u *UserResolver) ID() gql.ID {
+	return gql.ID(u.user.GetStringID())
+}
+
+// Name 用户名
+func (u *UserResolver) Name() string {
+	return u.user.Name
+}
+
+// URL 用户主页链接
+func (u *UserResolver) URL() string {
+	return u.user.Link()
+}
+
+// CreatedAt 注册时间
+func (u *UserResolver) CreatedAt() gql.Time {
+	return gql.Time{Time: u.user.CreatedAt}
+}
+
+// Articles 用户发布的文章，同一列表中的用户合并为一次查询
+func (u *UserResolver) Articles(ctx context.Context, args pageArgs) (*ArticleConnectionResolver, error) {
+	filter := article.CursorFilter{UserID: u.user.ID}
+	if u.articles != nil {
+		return u.articles.connection(ctx, u.user.ID, filter, args)
+	}
+	return articleConnection(ctx, filter, args)
+}
+
+// UserEdgeResolver 用户列表中的一条
+type UserEdgeResolver struct {
+	node *UserResolver
+}
+
+// Cursor 游标
+func (e *UserEdgeResolver) Cursor() string {
+	return encodeCursor(e.node.user.ID)
+}
+
+// Node 用户
+func (e *UserEdgeResolver) Node() *UserResolver {
+	return e.node
+}
+
+// UserConnectionResolver 用户列表
+type UserConnectionResolver struct {
+	users    []user.User
+	hasNext  bool
+	articles *articleBatch
+}
+
+// Edges 当前页的用户
+func (c *UserConnectionResolver) Edges() []*UserEdgeResolver {
+	edges := make([]*UserEdgeResolver, len(c.users))
+	for i := range c.users {
+		edges[i] = &UserEdgeResolver{node: &UserResolver{user: c.users[i], articles: c.articles}}
+	}
+	return edges
+}
+
+// PageInfo 分页信息
+func (c *UserConnectionResolver) PageInfo() PageInfoResolver {
+	p := PageInfoResolver{hasNext: c.hasNext}
+	if len(c.users) > 0 {
+		p.endID = c.users[len(c.users)-1].ID
+	}
+	return p
+}
+
+// TotalCount 用户总数
+func (c *UserConnectionResolver) TotalCount() (int32, error) {
+	count, err := user.Count()
+	return int32(count), err
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/usersession"
+	"goblog/app/requests"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// AccountController 账号设置
+type AccountController struct {
+	BaseController
+}
+
+// Edit 账号设置页面
+func (*AccountController) Edit(w http.ResponseWriter, r *http.Request) {
+	view.Render(w, r, view.D{
+		"User": auth.User(r),
+	}, "account.edit")
+}
+
+// UpdateEmail 修改邮箱，需验证当前密码，新邮箱需重新验证
+func (ac *AccountController) UpdateEmail(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+	email := strings.TrimSpace(r.PostFormValue("email"))
+
+	// 1. 表单验证
+	data := _user
+	data.Email = email
+	errs := requests.ValidateEmailForm(data)
+	if !_user.ComparePassword(r.PostFormValue("password")) {
+		errs["password"] = append(errs["password"], "密码错误")
+	}
+
+	if len(errs) > 0 {
+		view.Render(w, r, view.D{
+			"User":        _user,
+			"Email":       email,
+			"EmailErrors": errs,
+		}, "account.edit")
+		return
+	}
+
+	// 2. 修改邮箱并发送验证邮件
+	if err := _user.ChangeEmail(email); err != nil {
+		ac.ResponseForSQLError(w, err)
+		return
+	}
+	if err := sendVerificationEmail(&_user); err != nil {
+		log.Println("发送验证邮件失败：", err)
+	}
+
+	flash.Success(r, "邮箱已修改，验证邮件已发送至 "+email+"，请查收")
+	http.Redirect(w, r, route.Name2URL("account.edit"), http.StatusFound)
+}
+
+// UpdatePassword 修改密码，需验证当前密码，可同时退出其他设备的登录
+func (ac *AccountController) UpdatePassword(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+
+	// 1. 表单验证
+	data := _user
+	data.Password = r.PostFormValue("new_password")
+	data.PasswordConfirm = r.PostFormValue("password_confirm")
+	errs := requests.ValidatePasswordResetForm(data)
+	if !_user.ComparePassword(r.PostFormValue("password")) {
+		errs["current_password"] = append(errs["current_password"], "密码错误")
+	}
+
+	if len(errs) > 0 {
+		view.Render(w, r, view.D{
+			"User":           _user,
+			"PasswordErrors": errs,
+		}, "account.edit")
+		return
+	}
+
+	// 2. 修改密码
+	if err := _user.ChangePassword(data.Password); err != nil {
+		ac.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 3. 退出其他设备
+	message := "密码已修改"
+	if r.PostFormValue("logout_others") == "1" {
+		current, _ := auth.CurrentDevice(r)
+		rowsAffected, err := usersession.RevokeOtherDevices(_user.ID, current.ID)
+		if err != nil {
+			ac.ResponseForSQLError(w, err)
+			return
+		}
+		message += fmt.Sprintf("，已退出其他 %d 个设备的登录", rowsAffected)
+	}
+
+	flash.Success(r, message)
+	http.Redirect(w, r, route.Name2URL("account.edit"), http.StatusFound)
+}
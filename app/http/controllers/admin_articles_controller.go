@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/user"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/pagination"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+)
+
+// AdminArticlesController 后台文章管理
+type AdminArticlesController struct {
+	BaseController
+}
+
+// Index 文章列表，?q=标题&status=状态&category_id=分类ID&user_id=作者ID&sort=字段&order=asc|desc
+func (aac *AdminArticlesController) Index(w http.ResponseWriter, r *http.Request) {
+	q := pagination.NewQuery(r, route.Name2URL("admin.articles"), article.AdminSortable, "status", "category_id", "user_id")
+
+	articles, pagerData, err := article.GetForAdmin(r, q, 20)
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	categories, err := category.All()
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+	categoryNames := map[uint64]string{}
+	for _, _category := range categories {
+		categoryNames[_category.ID] = _category.Name
+	}
+
+	authors, err := user.All()
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"Articles":      articles,
+		"CategoryNames": categoryNames,
+		"Authors":       authors,
+		"Query":         q,
+		"PagerData":     pagerData,
+	}, "admin.articles", "admin._nav")
+}
+
+// Bulk 批量操作：删除（delete）、修改分类（category）和修改状态（status）
+func (aac *AdminArticlesController) Bulk(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取勾选的文章
+	ids := parseIDs(r)
+	if len(ids) == 0 {
+		flash.Warning(r, "请勾选要操作的文章")
+		redirectToList(w, r, route.Name2URL("admin.articles"))
+		return
+	}
+
+	// 2. 执行操作
+	switch r.PostFormValue("action") {
+	case "delete":
+		articles, err := article.GetByIDs(ids)
+		if err != nil {
+			aac.ResponseForSQLError(w, err)
+			return
+		}
+		for _, _article := range articles {
+			if _, err := _article.Delete(); err != nil {
+				aac.ResponseForSQLError(w, err)
+				return
+			}
+		}
+		flash.Success(r, fmt.Sprintf("已删除 %d 篇文章", len(articles)))
+
+	case "category":
+		cid, err := strconv.ParseUint(r.PostFormValue("category_id"), 10, 64)
+		if err != nil {
+			flash.Warning(r, "请选择分类")
+			break
+		}
+		if _, err := category.Get(r.PostFormValue("category_id")); err != nil {
+			aac.ResponseForSQLError(w, err)
+			return
+		}
+		n, err := article.SetCategory(ids, cid)
+		if err != nil {
+			aac.ResponseForSQLError(w, err)
+			return
+		}
+		flash.Success(r, fmt.Sprintf("已修改 %d 篇文章的分类", n))
+
+	case "status":
+		// 定时发布需要单独设置发布时间，不支持批量设置
+		status := r.PostFormValue("status")
+		if status != article.StatusDraft && status != article.StatusPublished && status != article.StatusArchived {
+			flash.Warning(r, "请选择状态")
+			break
+		}
+		articles, err := article.GetByIDs(ids)
+		if err != nil {
+			aac.ResponseForSQLError(w, err)
+			return
+		}
+		for _, _article := range articles {
+			_article.SetStatus(status, nil)
+			if _, err := _article.Update(auth.User(r).ID); err != nil {
+				aac.ResponseForSQLError(w, err)
+				return
+			}
+		}
+		flash.Success(r, fmt.Sprintf("已修改 %d 篇文章的状态", len(articles)))
+
+	default:
+		flash.Warning(r, "请选择批量操作")
+	}
+
+	redirectToList(w, r, route.Name2URL("admin.articles"))
+}
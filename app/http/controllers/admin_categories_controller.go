@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/pkg/flash"
+	"goblog/pkg/pagination"
+	"goblog/pkg/route"
+	"goblog/pkg/types"
+	"goblog/pkg/view"
+	"net/http"
+)
+
+// AdminCategoriesController 后台分类管理
+type AdminCategoriesController struct {
+	BaseController
+}
+
+// Index 分类列表，?q=名称&sort=字段&order=asc|desc
+func (acc *AdminCategoriesController) Index(w http.ResponseWriter, r *http.Request) {
+	q := pagination.NewQuery(r, route.Name2URL("admin.categories"), category.AdminSortable)
+
+	categories, pagerData, err := category.GetForAdmin(r, q, 20)
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	ids := make([]uint64, 0, len(categories))
+	for _, _category := range categories {
+		ids = append(ids, _category.ID)
+	}
+	articleCounts, err := article.CountByCategory(ids)
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"Categories":    categories,
+		"ArticleCounts": articleCounts,
+		"Query":         q,
+		"PagerData":     pagerData,
+	}, "admin.categories", "admin._nav")
+}
+
+// Bulk 批量操作：删除（delete），仍有文章的分类不会被删除，需先在文章管理中修改其文章的分类
+func (acc *AdminCategoriesController) Bulk(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取勾选的分类
+	ids := parseIDs(r)
+	if len(ids) == 0 || r.PostFormValue("action") != "delete" {
+		flash.Warning(r, "请勾选要删除的分类")
+		redirectToList(w, r, route.Name2URL("admin.categories"))
+		return
+	}
+
+	// 2. 删除没有文章的分类
+	articleCounts, err := article.CountByCategory(ids)
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	deleted, skipped := 0, 0
+	for _, id := range ids {
+		if articleCounts[id] > 0 {
+			skipped++
+			continue
+		}
+		_category, err := category.Get(types.Uint64ToString(id))
+		if err != nil {
+			continue
+		}
+		if _, err := _category.Delete(); err != nil {
+			acc.ResponseForSQLError(w, err)
+			return
+		}
+		deleted++
+	}
+
+	if skipped > 0 {
+		flash.Warning(r, fmt.Sprintf("已删除 %d 个分类，%d 个分类下仍有文章未删除", deleted, skipped))
+	} else {
+		flash.Success(r, fmt.Sprintf("已删除 %d 个分类", deleted))
+	}
+	redirectToList(w, r, route.Name2URL("admin.categories"))
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/comment"
+	"goblog/app/models/loginattempt"
+	"goblog/app/models/user"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// AdminController 管理后台控制器
+type AdminController struct {
+	BaseController
+}
+
+// Dashboard 后台首页，显示数据统计、最近注册的用户、最近的文章和最近的登录失败记录
+func (adc *AdminController) Dashboard(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 统计数据
+	userCount, err := user.Count()
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+	articleCounts, err := article.CountByStatus()
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+	categoryCount, err := category.Count()
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+	pendingComments, err := comment.CountByStatus(comment.StatusPending)
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+
+	var articleCount int64
+	for _, n := range articleCounts {
+		articleCount += n
+	}
+
+	// 2. 最近的用户和文章
+	recentUsers, err := user.Recent(5)
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+	recentArticles, err := article.Recent(5)
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 3. 最近的登录失败
+	failedLogins, err := loginattempt.Recent(10)
+	if err != nil {
+		adc.ResponseForSQLError(w, err)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"UserCount":       userCount,
+		"ArticleCount":    articleCount,
+		"ArticleCounts":   articleCounts,
+		"CategoryCount":   categoryCount,
+		"PendingComments": pendingComments,
+		"RecentUsers":     recentUsers,
+		"RecentArticles":  recentArticles,
+		"FailedLogins":    failedLogins,
+	}, "admin.dashboard", "admin._nav")
+}
+
+// parseIDs 读取批量操作表单中勾选的 ID，忽略格式不正确的值
+func parseIDs(r *http.Request) []uint64 {
+	r.ParseForm()
+
+	var ids []uint64
+	for _, v := range r.PostForm["ids"] {
+		if id, err := strconv.ParseUint(v, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// redirectToList 批量操作完成后返回列表页，保留原有的筛选、排序和分页参数
+func redirectToList(w http.ResponseWriter, r *http.Request, listURL string) {
+	target := r.PostFormValue("redirect")
+	if !strings.HasPrefix(target, listURL) {
+		target = listURL
+	}
+	http.Redirect(w, r, target, http.StatusFound)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"goblog/app/models/role"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+)
+
+// AdminRolesController 后台角色管理
+type AdminRolesController struct {
+	BaseController
+}
+
+// Index 角色列表
+func (arc *AdminRolesController) Index(w http.ResponseWriter, r *http.Request) {
+	roles, err := role.All()
+	if err != nil {
+		arc.ResponseForSQLError(w, err)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"Roles": roles,
+	}, "admin.roles", "admin._nav")
+}
+
+// TwoFactor 设置角色是否要求启用两步验证，require=1|0
+func (arc *AdminRolesController) TwoFactor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(route.GetRouteVariable("id", r), 10, 64)
+	if err != nil {
+		arc.ResponseForSQLError(w, err)
+		return
+	}
+
+	_role, err := role.Get(id)
+	if err != nil {
+		arc.ResponseForSQLError(w, err)
+		return
+	}
+
+	required := r.PostFormValue("require") == "1"
+	if err := _role.SetRequireTwoFactor(required); err != nil {
+		arc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if required {
+		flash.Success(r, "已要求「"+_role.DisplayName+"」启用两步验证")
+	} else {
+		flash.Success(r, "已取消「"+_role.DisplayName+"」的两步验证要求")
+	}
+	http.Redirect(w, r, route.Name2URL("admin.roles"), http.StatusFound)
+}
@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/article"
+	"goblog/app/models/comment"
+	"goblog/app/models/role"
+	"goblog/app/models/user"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/pagination"
+	"goblog/pkg/route"
+	"goblog/pkg/types"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+)
+
+// AdminUsersController 后台用户管理
+type AdminUsersController struct {
+	BaseController
+}
+
+// Index 用户列表，?q=关键字&role=角色ID&banned=1|0&sort=字段&order=asc|desc
+func (amu *AdminUsersController) Index(w http.ResponseWriter, r *http.Request) {
+	q := pagination.NewQuery(r, route.Name2URL("admin.users"), user.AdminSortable, "role", "banned")
+
+	users, pagerData, err := user.GetForAdmin(r, q, 20)
+	if err != nil {
+		amu.ResponseForSQLError(w, err)
+		return
+	}
+
+	roles, err := role.All()
+	if err != nil {
+		amu.ResponseForSQLError(w, err)
+		return
+	}
+	roleNames := map[uint64]string{}
+	for _, _role := range roles {
+		roleNames[_role.ID] = _role.DisplayName
+	}
+
+	view.Render(w, r, view.D{
+		"Users":     users,
+		"Roles":     roles,
+		"RoleNames": roleNames,
+		"Query":     q,
+		"PagerData": pagerData,
+	}, "admin.users", "admin._nav")
+}
+
+// Bulk 批量操作：封禁（ban）、解封（unban）、修改角色（role）和删除（delete），不能操作自己
+func (amu *AdminUsersController) Bulk(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取勾选的用户，排除当前用户
+	currentID := auth.User(r).ID
+	var ids []uint64
+	for _, id := range parseIDs(r) {
+		if id != currentID {
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		flash.Warning(r, "请勾选要操作的用户，不能操作自己的账号")
+		redirectToList(w, r, route.Name2URL("admin.users"))
+		return
+	}
+
+	// 2. 执行操作
+	switch r.PostFormValue("action") {
+	case "ban", "unban":
+		banned := r.PostFormValue("action") == "ban"
+		n, err := user.SetBanned(ids, banned)
+		if err != nil {
+			amu.ResponseForSQLError(w, err)
+			return
+		}
+		if banned {
+			flash.Success(r, fmt.Sprintf("已封禁 %d 个用户", n))
+		} else {
+			flash.Success(r, fmt.Sprintf("已解封 %d 个用户", n))
+		}
+
+	case "role":
+		roleID, err := strconv.ParseUint(r.PostFormValue("role_id"), 10, 64)
+		if err != nil {
+			flash.Warning(r, "请选择角色")
+			break
+		}
+		n, err := user.SetRole(ids, roleID)
+		if err == user.ErrRoleNotFound {
+			flash.Warning(r, err.Error())
+			break
+		}
+		if err != nil {
+			amu.ResponseForSQLError(w, err)
+			return
+		}
+		flash.Success(r, fmt.Sprintf("已修改 %d 个用户的角色", n))
+
+	case "delete":
+		deleted, skipped, err := deleteUsers(ids)
+		if err != nil {
+			amu.ResponseForSQLError(w, err)
+			return
+		}
+		if skipped > 0 {
+			flash.Warning(r, fmt.Sprintf("已删除 %d 个用户，%d 个用户仍有文章未删除，可改为封禁", deleted, skipped))
+		} else {
+			flash.Success(r, fmt.Sprintf("已删除 %d 个用户", deleted))
+		}
+
+	default:
+		flash.Warning(r, "请选择批量操作")
+	}
+
+	redirectToList(w, r, route.Name2URL("admin.users"))
+}
+
+// deleteUsers 删除用户，仍有文章的用户跳过，其评论转为游客评论
+func deleteUsers(ids []uint64) (deleted int, skipped int, err error) {
+	for _, id := range ids {
+		_user, err := user.Get(types.Uint64ToString(id))
+		if err != nil {
+			continue
+		}
+
+		count, err := article.CountByUser(_user.ID)
+		if err != nil {
+			return deleted, skipped, err
+		}
+		if count > 0 {
+			skipped++
+			continue
+		}
+
+		if err := comment.DetachUser(_user); err != nil {
+			return deleted, skipped, err
+		}
+		if _, err := _user.Delete(); err != nil {
+			return deleted, skipped, err
+		}
+		deleted++
+	}
+	return deleted, skipped, nil
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"goblog/app/http/resources"
+	"goblog/app/models/article"
+	"goblog/app/policies"
+	"goblog/app/requests"
+	"goblog/pkg/auth"
+	"goblog/pkg/pagination"
+	"goblog/pkg/response"
+	"goblog/pkg/route"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// APIArticlesController 文章 API
+type APIArticlesController struct {
+	APIController
+}
+
+// Index 已发布的文章列表，?q=标题&category_id=&user_id=&sort=&order=&page=&per_page=
+func (aac *APIArticlesController) Index(w http.ResponseWriter, r *http.Request) {
+	q := pagination.NewQuery(r, route.Name2URL("api.articles.index"), article.APISortable, "category_id", "user_id", "per_page")
+
+	articles, pagerData, err := article.GetForAPI(r, q, aac.perPage(r))
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resources.Collection{Data: resources.Articles(articles), Meta: pagerData})
+}
+
+// Show 文章详情，未发布的文章只有可编辑该文章的用户可见
+func (aac *APIArticlesController) Show(w http.ResponseWriter, r *http.Request) {
+	_article, err := article.Get(route.GetRouteVariable("id", r))
+	if err == nil && !_article.IsPublished() && !policies.AllowsUser(auth.TokenUser(r), "article.update", _article) {
+		err = gorm.ErrRecordNotFound
+	}
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resources.Item{Data: resources.Article(_article)})
+}
+
+// Store 创建文章，未指定状态时保存为草稿
+func (aac *APIArticlesController) Store(w http.ResponseWriter, r *http.Request) {
+	// 1. 检查权限，未验证邮箱的用户不能发布文章
+	_user := auth.TokenUser(r)
+	if !policies.AllowsUser(_user, "article.create") {
+		aac.ResponseForUnauthorized(w)
+		return
+	}
+	if !_user.IsVerified() {
+		response.Error(w, http.StatusForbidden, "请先验证您的邮箱")
+		return
+	}
+
+	// 2. 解析并验证请求
+	var input requests.ArticleInput
+	if !aac.decode(w, r, &input) {
+		return
+	}
+	_article := article.Article{UserID: _user.ID, Status: article.StatusDraft}
+	if errs := input.Apply(&_article); len(errs) > 0 {
+		response.ValidationError(w, errs)
+		return
+	}
+
+	// 3. 创建文章
+	if err := _article.Create(); err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	aac.respond(w, http.StatusCreated, _article)
+}
+
+// Update 更新文章，只修改请求中提供的字段
+func (aac *APIArticlesController) Update(w http.ResponseWriter, r *http.Request) {
+	// 1. 读取文章并检查权限
+	_article, err := article.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+	if !policies.AllowsUser(auth.TokenUser(r), "article.update", _article) {
+		aac.ResponseForUnauthorized(w)
+		return
+	}
+
+	// 2. 解析并验证请求
+	var input requests.ArticleInput
+	if !aac.decode(w, r, &input) {
+		return
+	}
+	if errs := input.Apply(&_article); len(errs) > 0 {
+		response.ValidationError(w, errs)
+		return
+	}
+
+	// 3. 更新文章，提供了标签时一并更新标签
+	if _, err := _article.Update(auth.TokenUser(r).ID); err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	aac.respond(w, http.StatusOK, _article)
+}
+
+// Delete 删除文章
+func (aac *APIArticlesController) Delete(w http.ResponseWriter, r *http.Request) {
+	_article, err := article.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+	if !policies.AllowsUser(auth.TokenUser(r), "article.delete", _article) {
+		aac.ResponseForUnauthorized(w)
+		return
+	}
+
+	if _, err := _article.Delete(); err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+// respond 重新读取文章（包括生成的 slug、作者和标签）后输出
+func (aac *APIArticlesController) respond(w http.ResponseWriter, status int, _article article.Article) {
+	_article, err := article.Get(_article.GetStringID())
+	if err != nil {
+		aac.ResponseForSQLError(w, err)
+		return
+	}
+
+	if status == http.StatusCreated {
+		w.Header().Set("Location", route.Name2URL("api.articles.show", "id", _article.GetStringID()))
+	}
+	response.JSON(w, status, resources.Item{Data: resources.Article(_article)})
+}
@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"goblog/app/http/resources"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/policies"
+	"goblog/app/requests"
+	"goblog/pkg/auth"
+	"goblog/pkg/pagination"
+	"goblog/pkg/response"
+	"goblog/pkg/route"
+	"net/http"
+)
+
+// APICategoriesController 分类 API
+type APICategoriesController struct {
+	APIController
+}
+
+// categoryInput 创建和更新分类的请求体
+type categoryInput struct {
+	Name string `json:"name"`
+}
+
+// Index 分类列表，?q=名称&sort=&order=&page=&per_page=
+func (acc *APICategoriesController) Index(w http.ResponseWriter, r *http.Request) {
+	q := pagination.NewQuery(r, route.Name2URL("api.categories.index"), category.AdminSortable, "per_page")
+
+	categories, pagerData, err := category.GetForAdmin(r, q, acc.perPage(r))
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resources.Collection{Data: resources.Categories(categories), Meta: pagerData})
+}
+
+// Show 分类详情
+func (acc *APICategoriesController) Show(w http.ResponseWriter, r *http.Request) {
+	_category, err := category.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, resources.Item{Data: resources.Category(_category)})
+}
+
+// Store 创建分类
+func (acc *APICategoriesController) Store(w http.ResponseWriter, r *http.Request) {
+	if !policies.AllowsUser(auth.TokenUser(r), "category.create") {
+		acc.ResponseForUnauthorized(w)
+		return
+	}
+
+	var input categoryInput
+	if !acc.decode(w, r, &input) {
+		return
+	}
+	_category := category.Category{Name: input.Name}
+	if errs := requests.ValidateCategoryForm(_category); len(errs) > 0 {
+		response.ValidationError(w, errs)
+		return
+	}
+
+	if err := _category.Create(); err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", route.Name2URL("api.categories.show", "id", _category.GetStringID()))
+	response.JSON(w, http.StatusCreated, resources.Item{Data: resources.Category(_category)})
+}
+
+// Update 修改分类名称
+func (acc *APICategoriesController) Update(w http.ResponseWriter, r *http.Request) {
+	if !policies.AllowsUser(auth.TokenUser(r), "category.update") {
+		acc.ResponseForUnauthorized(w)
+		return
+	}
+
+	_category, err := category.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	var input categoryInput
+	if !acc.decode(w, r, &input) {
+		return
+	}
+
+	// 名称未修改时无需验证，否则会被判定为名称已存在
+	if input.Name != _category.Name {
+		_category.Name = input.Name
+		if errs := requests.ValidateCategoryForm(_category); len(errs) > 0 {
+			response.ValidationError(w, errs)
+			return
+		}
+		if _, err := _category.Update(); err != nil {
+			acc.ResponseForSQLError(w, err)
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, resources.Item{Data: resources.Category(_category)})
+}
+
+// Delete 删除分类，仍有文章的分类不能删除
+func (acc *APICategoriesController) Delete(w http.ResponseWriter, r *http.Request) {
+	if !policies.AllowsUser(auth.TokenUser(r), "admin.access") {
+		acc.ResponseForUnauthorized(w)
+		return
+	}
+
+	_category, err := category.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+
+	articleCounts, err := article.CountByCategory([]uint64{_category.ID})
+	if err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+	if articleCounts[_category.ID] > 0 {
+		response.Error(w, http.StatusConflict, "分类下仍有文章，请先修改这些文章的分类")
+		return
+	}
+
+	if _, err := _category.Delete(); err != nil {
+		acc.ResponseForSQLError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
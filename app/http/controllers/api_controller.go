@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"encoding/json"
+	"goblog/pkg/response"
+	"log"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// APIController API 控制器基类，所有响应均为 JSON
+type APIController struct {
+}
+
+// ResponseForSQLError 数据未找到时返回 404，其他数据库错误返回 500
+func (APIController) ResponseForSQLError(w http.ResponseWriter, err error) {
+	if err == gorm.ErrRecordNotFound {
+		response.Error(w, http.StatusNotFound, "资源未找到")
+	} else {
+		log.Println("API 数据库错误：", err)
+		response.Error(w, http.StatusInternalServerError, "服务器内部错误")
+	}
+}
+
+// ResponseForUnauthorized 用户的角色没有权限
+func (APIController) ResponseForUnauthorized(w http.ResponseWriter) {
+	response.Error(w, http.StatusForbidden, "未授权操作")
+}
+
+// decode 解析 JSON 请求体，失败时返回 400 并返回 false
+func (APIController) decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(v); err != nil {
+		response.Error(w, http.StatusBadRequest, "请求体不是有效的 JSON")
+		return false
+	}
+	return true
+}
+
+// perPage 每页条数，?per_page= 取值 1～100，默认 20
+func (APIController) perPage(r *http.Request) int {
+	n, err := strconv.Atoi(r.URL.Query().Get("per_page"))
+	if err != nil || n <= 0 {
+		return 20
+	}
+	if n > 100 {
+		return 100
+	}
+	return n
+}
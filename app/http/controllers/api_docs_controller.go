@@ -0,0 +1,205 @@
+package controllers
+
+import (
+	"encoding/json"
+	"goblog/app/http/resources"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/user"
+	"goblog/app/requests"
+	"goblog/pkg/config"
+	"goblog/pkg/openapi"
+	"goblog/pkg/pagination"
+	"goblog/pkg/response"
+	"goblog/pkg/route"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// APIDocsController API 文档
+type APIDocsController struct {
+	APIController
+}
+
+// apiSpec 生成的文档，路由在启动后不再变化，只需生成一次
+var apiSpec struct {
+	once sync.Once
+	body []byte
+	err  error
+}
+
+// Spec 输出 OpenAPI 文档
+func (adc *APIDocsController) Spec(w http.ResponseWriter, r *http.Request) {
+	apiSpec.once.Do(func() {
+		doc, err := APISpec(route.Router())
+		if err == nil {
+			apiSpec.body, err = json.Marshal(doc)
+		}
+		apiSpec.err = err
+	})
+
+	if apiSpec.err != nil {
+		log.Println("生成 API 文档失败：", apiSpec.err)
+		response.Error(w, http.StatusInternalServerError, "服务器内部错误")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Write(apiSpec.body)
+}
+
+// APISpec 根据已注册的路由生成 API 的 OpenAPI 文档
+func APISpec(router *mux.Router) (*openapi.Document, error) {
+	g := openapi.Generator{
+		Info: openapi.Info{
+			Title:       "GoBlog API",
+			Version:     "v1",
+			Description: "使用个人访问令牌认证，请求头为 Authorization: Bearer 令牌。",
+		},
+		Meta:      pagination.ViewData{},
+		Endpoints: apiEndpoints,
+	}
+	if url := config.GetString("app.url"); len(url) > 0 {
+		g.Servers = []openapi.Server{{URL: url}}
+	}
+	return g.Generate(router)
+}
+
+// apiEndpoints API 接口说明，键为路由名称，新增 API 路由时需在此添加
+var apiEndpoints = map[string]openapi.Endpoint{
+	// 文章
+	"api.articles.index": {
+		Summary: "已发布的文章列表",
+		Tag:     "articles",
+		Scope:   "read",
+		Query: append(listParams(article.APISortable),
+			openapi.QueryParam("category_id", "按分类筛选", &openapi.Schema{Type: "integer", Format: "int64"}),
+			openapi.QueryParam("user_id", "按作者筛选", &openapi.Schema{Type: "integer", Format: "int64"}),
+		),
+		Response: resources.ArticleResource{},
+		List:     true,
+	},
+	"api.articles.show": {
+		Summary:  "文章详情，未发布的文章只有可编辑的用户能查看",
+		Tag:      "articles",
+		Scope:    "read",
+		Response: resources.ArticleResource{},
+	},
+	"api.articles.store": {
+		Summary:  "发布文章，status 默认为 draft",
+		Tag:      "articles",
+		Scope:    "write",
+		Request:  requests.ArticleInput{},
+		Rules:    requests.APIArticleRules(),
+		Response: resources.ArticleResource{},
+		Status:   http.StatusCreated,
+	},
+	"api.articles.update": {
+		Summary:  "修改文章，只修改请求中提供的字段",
+		Tag:      "articles",
+		Scope:    "write",
+		Request:  requests.ArticleInput{},
+		Rules:    requests.ArticleRules(),
+		Partial:  true,
+		Response: resources.ArticleResource{},
+	},
+	"api.articles.delete": {
+		Summary: "删除文章",
+		Tag:     "articles",
+		Scope:   "write",
+	},
+
+	// 分类
+	"api.categories.index": {
+		Summary:  "分类列表",
+		Tag:      "categories",
+		Scope:    "read",
+		Query:    listParams(category.AdminSortable),
+		Response: resources.CategoryResource{},
+		List:     true,
+	},
+	"api.categories.show": {
+		Summary:  "分类详情",
+		Tag:      "categories",
+		Scope:    "read",
+		Response: resources.CategoryResource{},
+	},
+	"api.categories.store": {
+		Summary:  "创建分类",
+		Tag:      "categories",
+		Scope:    "admin",
+		Request:  categoryInput{},
+		Rules:    requests.CategoryRules(),
+		Response: resources.CategoryResource{},
+		Status:   http.StatusCreated,
+	},
+	"api.categories.update": {
+		Summary:  "修改分类名称",
+		Tag:      "categories",
+		Scope:    "admin",
+		Request:  categoryInput{},
+		Rules:    requests.CategoryRules(),
+		Response: resources.CategoryResource{},
+	},
+	"api.categories.delete": {
+		Summary: "删除分类，仍有文章的分类返回 409",
+		Tag:     "categories",
+		Scope:   "admin",
+	},
+
+	// 用户
+	"api.user": {
+		Summary:  "令牌所属的用户",
+		Tag:      "users",
+		Scope:    "read",
+		Response: resources.UserResource{},
+	},
+	"api.users.index": {
+		Summary:  "用户列表，邮箱等字段只对管理员输出",
+		Tag:      "users",
+		Scope:    "read",
+		Query:    listParams(user.APISortable),
+		Response: resources.UserResource{},
+		List:     true,
+	},
+	"api.users.show": {
+		Summary:  "用户详情，邮箱等字段只对本人和管理员输出",
+		Tag:      "users",
+		Scope:    "read",
+		Response: resources.UserResource{},
+	},
+	"api.users.store": {
+		Summary:  "创建用户",
+		Tag:      "users",
+		Scope:    "admin",
+		Request:  userInput{},
+		Rules:    requests.RegistrationRules(),
+		Response: resources.UserResource{},
+		Status:   http.StatusCreated,
+	},
+	"api.users.update": {
+		Summary:  "修改用户的邮箱、角色或封禁状态",
+		Tag:      "users",
+		Scope:    "admin",
+		Request:  userInput{},
+		Rules:    requests.EmailRules(),
+		Partial:  true,
+		Response: resources.UserResource{},
+	},
+	"api.users.delete": {
+		Summary: "删除用户，仍有文章的用户返回 409",
+		Tag:     "users",
+		Scope:   "admin",
+	},
+}
+
+// listParams 列表接口的搜索、排序和分页参数
+func listParams(sortable []string) []openapi.Parameter {
+	return append([]openapi.Parameter{
+		openapi.QueryParam("q", "搜索关键字", &openapi.Schema{Type: "string"}),
+		openapi.QueryParam("page", "页码", &openapi.Schema{Type: "integer", Format: "int32"}),
+		openapi.QueryParam("per_page", "每页条数，最多 100", &openapi.Schema{Type: "integer", Format: "int32"}),
+	}, openapi.SortParams(sortable)...)
+}
@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"goblog/app/http/resources"
+	"goblog/app/models/role"
+	"goblog/app/models/user"
+	"goblog/app/policies"
+	"goblog/app/requests"
+	"goblog/pkg/auth"
+	"goblog/pkg/pagination"
+	"goblog/pkg/response"
+	"goblog/pkg/route"
+	"net/http"
+)
+
+// APIUsersController 用户 API，邮箱等私密字段只对本人和管理员输出
+type APIUsersController struct {
+	APIController
+}
+
+// userInput 创建和更新用户的请求体，更新时只支持修改邮箱、角色和封禁状态
+type userInput struct {
+	Name     string  `json:"name"`
+	Email    *string `json:"email"`
+	Password string  `json:"password"`
+	RoleID   *uint64 `json:"role_id"`
+	Banned   *bool   `json:"banned"`
+}
+
+// Index 用户列表，?q=名称&sort=&order=&page=&per_page=
+func (auc *APIUsersController) Index(w http.ResponseWriter, r *http.Request) {
+	q := pagination.NewQuery(r, route.Name2URL("api.users.index"), user.APISortable, "per_page")
+
+	users, pagerData, err := user.GetForAPI(r, q, auc.perPage(r))
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+
+	private := policies.AllowsUser(auth.TokenUser(r), "admin.access")
+	response.JSON(w, http.StatusOK, resources.Collection{Data: resources.Users(users, private), Meta: pagerData})
+}
+
+// Show 用户详情
+func (auc *APIUsersController) Show(w http.ResponseWriter, r *http.Request) {
+	_user, err := user.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+
+	current := auth.TokenUser(r)
+	private := current.ID == _user.ID || policies.AllowsUser(current, "admin.access")
+	response.JSON(w, http.StatusOK, resources.Item{Data: resources.User(_user, private)})
+}
+
+// Me 令牌所属的用户
+func (auc *APIUsersController) Me(w http.ResponseWriter, r *http.Request) {
+	response.JSON(w, http.StatusOK, resources.Item{Data: resources.User(auth.TokenUser(r), true)})
+}
+
+// Store 创建用户，需要管理后台权限
+func (auc *APIUsersController) Store(w http.ResponseWriter, r *http.Request) {
+	if !policies.AllowsUser(auth.TokenUser(r), "admin.access") {
+		auc.ResponseForUnauthorized(w)
+		return
+	}
+
+	// 1. 解析并验证请求，与注册表单使用相同的规则
+	var input userInput
+	if !auc.decode(w, r, &input) {
+		return
+	}
+	_user := user.User{
+		Name:            input.Name,
+		Password:        input.Password,
+		PasswordConfirm: input.Password,
+	}
+	if input.Email != nil {
+		_user.Email = *input.Email
+	}
+	errs := requests.ValidateRegistrationForm(_user)
+	if input.RoleID != nil {
+		if _, err := role.Get(*input.RoleID); err != nil {
+			errs["role_id"] = append(errs["role_id"], "角色不存在")
+		}
+		_user.RoleID = *input.RoleID
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, errs)
+		return
+	}
+
+	// 2. 创建用户
+	if err := _user.Create(); err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", route.Name2URL("api.users.show", "id", _user.GetStringID()))
+	response.JSON(w, http.StatusCreated, resources.Item{Data: resources.User(_user, true)})
+}
+
+// Update 修改用户的邮箱、角色或封禁状态，不能修改自己的角色和封禁状态
+func (auc *APIUsersController) Update(w http.ResponseWriter, r *http.Request) {
+	// 1. 检查权限
+	current := auth.TokenUser(r)
+	if !policies.AllowsUser(current, "admin.access") {
+		auc.ResponseForUnauthorized(w)
+		return
+	}
+	_user, err := user.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 2. 解析并验证请求
+	var input userInput
+	if !auc.decode(w, r, &input) {
+		return
+	}
+	errs := map[string][]string{}
+	if input.Email != nil && *input.Email != _user.Email {
+		errs = requests.ValidateEmailForm(user.User{Email: *input.Email})
+	}
+	if input.RoleID != nil {
+		if _, err := role.Get(*input.RoleID); err != nil {
+			errs["role_id"] = append(errs["role_id"], "角色不存在")
+		}
+	}
+	if (input.RoleID != nil || input.Banned != nil) && _user.ID == current.ID {
+		errs["user"] = append(errs["user"], "不能修改自己的角色和封禁状态")
+	}
+	if len(errs) > 0 {
+		response.ValidationError(w, errs)
+		return
+	}
+
+	// 3. 更新，邮箱修改后需要重新验证
+	if input.Email != nil && *input.Email != _user.Email {
+		err = _user.ChangeEmail(*input.Email)
+	}
+	if err == nil && input.RoleID != nil {
+		_, err = user.SetRole([]uint64{_user.ID}, *input.RoleID)
+	}
+	if err == nil && input.Banned != nil {
+		_, err = user.SetBanned([]uint64{_user.ID}, *input.Banned)
+	}
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+
+	_user, err = user.Get(_user.GetStringID())
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+	response.JSON(w, http.StatusOK, resources.Item{Data: resources.User(_user, true)})
+}
+
+// Delete 删除用户，仍有文章的用户不能删除，可改为封禁
+func (auc *APIUsersController) Delete(w http.ResponseWriter, r *http.Request) {
+	current := auth.TokenUser(r)
+	if !policies.AllowsUser(current, "admin.access") {
+		auc.ResponseForUnauthorized(w)
+		return
+	}
+	_user, err := user.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+	if _user.ID == current.ID {
+		response.Error(w, http.StatusConflict, "不能删除自己的账号")
+		return
+	}
+
+	_, skipped, err := deleteUsers([]uint64{_user.ID})
+	if err != nil {
+		auc.ResponseForSQLError(w, err)
+		return
+	}
+	if skipped > 0 {
+		response.Error(w, http.StatusConflict, "用户仍有文章，不能删除，可改为封禁")
+		return
+	}
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -3,12 +3,18 @@ package controllers
 import (
 	"fmt"
 	"goblog/app/models/article"
+	"goblog/app/models/comment"
+	"goblog/app/models/tag"
 	"goblog/app/policies"
 	"goblog/app/requests"
 	"goblog/pkg/auth"
 	"goblog/pkg/route"
 	"goblog/pkg/view"
 	"net/http"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 // ArticlesController 处理静态页面
@@ -16,27 +22,87 @@ type ArticlesController struct {
 	BaseController
 }
 
-// Show 文章详情页面
+// Show 文章详情页面，已生成 slug 的文章 301 跳转到 slug 链接
 func (ac *ArticlesController) Show(w http.ResponseWriter, r *http.Request) {
 
 	// 1. 获取 URL 参数
 	id := route.GetRouteVariable("id", r)
 
 	// 2. 读取对应的文章数据
-	article, err := article.Get(id)
+	_article, err := article.Get(id)
 
 	// 3. 如果出现错误
 	if err != nil {
 		ac.ResponseForSQLError(w, err)
+	} else if len(_article.Slug) > 0 {
+		http.Redirect(w, r, _article.Link(), http.StatusMovedPermanently)
 	} else {
-		// ---  4. 读取成功，显示文章 ---
-		view.Render(w, view.D{
-			"Article":          article,
-			"CanModifyArticle": policies.CanModifyArticle(article),
-		}, "articles.show", "articles._article_meta")
+		ac.show(w, r, _article)
+	}
+}
+
+// ShowBySlug 通过 slug 访问的文章详情页面，旧 slug 301 跳转到新链接
+func (ac *ArticlesController) ShowBySlug(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取 URL 参数
+	s := route.GetRouteVariable("slug", r)
+
+	// 2. 读取对应的文章数据
+	_article, err := article.GetBySlug(s)
+
+	// 3. 标题修改过的文章，旧 slug 跳转到新链接
+	if err == gorm.ErrRecordNotFound {
+		if _old, err := article.GetByOldSlug(s); err == nil {
+			http.Redirect(w, r, _old.Link(), http.StatusMovedPermanently)
+			return
+		}
+	}
+
+	// 4. 如果出现错误
+	if err != nil {
+		ac.ResponseForSQLError(w, err)
+	} else {
+		ac.show(w, r, _article)
+	}
+}
+
+// show 显示文章详情
+func (ac *ArticlesController) show(w http.ResponseWriter, r *http.Request, _article article.Article) {
+	if !_article.IsPublished() && !policies.Allows(r, "article.update", _article) {
+		// 未发布的文章仅可编辑该文章的用户可见
+		ac.ResponseForSQLError(w, gorm.ErrRecordNotFound)
+		return
+	}
+
+	// 回复某条评论，?reply_to=评论ID
+	data := view.D{}
+	if replyTo := r.URL.Query().Get("reply_to"); len(replyTo) > 0 {
+		if _, err := strconv.ParseUint(replyTo, 10, 64); err == nil {
+			if _comment, err := comment.Get(replyTo); err == nil && _comment.ArticleID == _article.ID && _comment.IsApproved() {
+				data["ReplyTo"] = _comment
+			}
+		}
+	}
+
+	if err := renderArticle(w, r, _article, data); err != nil {
+		ac.ResponseForSQLError(w, err)
 	}
 }
 
+// renderArticle 渲染文章详情页及其评论，data 为额外的模板数据（如评论表单的错误信息）
+// 编辑、删除等按钮由模板中的 can 函数按权限显示
+func renderArticle(w http.ResponseWriter, r *http.Request, _article article.Article, data view.D) error {
+	comments, err := comment.GetApprovedTree(_article.ID)
+	if err != nil {
+		return err
+	}
+
+	data["Article"] = _article
+	data["Comments"] = comments
+	view.Render(w, r, data, "articles.show", "articles._article_meta", "articles._comments")
+	return nil
+}
+
 // Index 文章列表页
 func (ac *ArticlesController) Index(w http.ResponseWriter, r *http.Request) {
 
@@ -48,7 +114,7 @@ func (ac *ArticlesController) Index(w http.ResponseWriter, r *http.Request) {
 	} else {
 
 		// ---  2. 加载模板 ---
-		view.Render(w, view.D{
+		view.Render(w, r, view.D{
 			"Articles":  articles,
 			"PagerData": pagerData,
 		}, "articles.index", "articles._article_meta")
@@ -57,18 +123,22 @@ func (ac *ArticlesController) Index(w http.ResponseWriter, r *http.Request) {
 
 // Create 文章创建页面
 func (*ArticlesController) Create(w http.ResponseWriter, r *http.Request) {
-	view.Render(w, view.D{}, "articles.create", "articles._form_field")
+	view.Render(w, r, view.D{
+		"Article": article.Article{Status: article.StatusDraft},
+	}, "articles.create", "articles._form_field")
 }
 
 // Store 文章创建页面
 func (*ArticlesController) Store(w http.ResponseWriter, r *http.Request) {
 	// 1. 初始化数据
-	currentUser := auth.User()
+	currentUser := auth.User(r)
 	_article := article.Article{
 		Title:  r.PostFormValue("title"),
 		Body:   r.PostFormValue("body"),
 		UserID: currentUser.ID,
 	}
+	fillArticleStatus(&_article, r)
+	_article.SetTags(tag.ParseNames(r.PostFormValue("tags")))
 
 	// 2. 表单验证
 	errors := requests.ValidateArticleForm(_article)
@@ -76,16 +146,14 @@ func (*ArticlesController) Store(w http.ResponseWriter, r *http.Request) {
 	// 3. 检测错误
 	if len(errors) == 0 {
 		// 创建文章
-		_article.Create()
-		if _article.ID > 0 {
-			indexURL := route.Name2URL("articles.show", "id", _article.GetStringID())
-			http.Redirect(w, r, indexURL, http.StatusFound)
+		if err := _article.Create(); err == nil {
+			http.Redirect(w, r, _article.Link(), http.StatusFound)
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
 			fmt.Fprint(w, "创建文章失败，请联系管理员")
 		}
 	} else {
-		view.Render(w, view.D{
+		view.Render(w, r, view.D{
 			"Article": _article,
 			"Errors":  errors,
 		}, "articles.create", "articles._form_field")
@@ -107,11 +175,11 @@ func (ac *ArticlesController) Edit(w http.ResponseWriter, r *http.Request) {
 	} else {
 
 		// 检查权限
-		if !policies.CanModifyArticle(_article) {
+		if !policies.Allows(r, "article.update", _article) {
 			ac.ResponseForUnauthorized(w, r)
 		} else {
 			// 4. 读取成功，显示编辑文章表单
-			view.Render(w, view.D{
+			view.Render(w, r, view.D{
 				"Article": _article,
 				"Errors":  view.D{},
 			}, "articles.edit", "articles._form_field")
@@ -135,20 +203,22 @@ func (ac *ArticlesController) Update(w http.ResponseWriter, r *http.Request) {
 		// 4. 未出现错误
 
 		// 检查权限
-		if !policies.CanModifyArticle(_article) {
+		if !policies.Allows(r, "article.update", _article) {
 			ac.ResponseForUnauthorized(w, r)
 		} else {
 
 			// 4.1 表单验证
 			_article.Title = r.PostFormValue("title")
 			_article.Body = r.PostFormValue("body")
+			fillArticleStatus(&_article, r)
+			_article.SetTags(tag.ParseNames(r.PostFormValue("tags")))
 
 			errors := requests.ValidateArticleForm(_article)
 
 			if len(errors) == 0 {
 
 				// 4.2 表单验证通过，更新数据
-				rowsAffected, err := _article.Update()
+				rowsAffected, err := _article.Update(auth.User(r).ID)
 
 				if err != nil {
 					// 数据库错误
@@ -159,15 +229,14 @@ func (ac *ArticlesController) Update(w http.ResponseWriter, r *http.Request) {
 
 				// √ 更新成功，跳转到文章详情页
 				if rowsAffected > 0 {
-					showURL := route.Name2URL("articles.show", "id", id)
-					http.Redirect(w, r, showURL, http.StatusFound)
+					http.Redirect(w, r, _article.Link(), http.StatusFound)
 				} else {
 					fmt.Fprint(w, "您没有做任何更改！")
 				}
 			} else {
 
 				// 4.3 表单验证不通过，显示理由
-				view.Render(w, view.D{
+				view.Render(w, r, view.D{
 					"Article": _article,
 					"Errors":  errors,
 				}, "articles.edit", "articles._form_field")
@@ -191,7 +260,7 @@ func (ac *ArticlesController) Delete(w http.ResponseWriter, r *http.Request) {
 	} else {
 
 		// 检查权限
-		if !policies.CanModifyArticle(_article) {
+		if !policies.Allows(r, "article.delete", _article) {
 			ac.ResponseForUnauthorized(w, r)
 		} else {
 			// 4. 未出现错误，执行删除操作
@@ -217,3 +286,13 @@ func (ac *ArticlesController) Delete(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 }
+
+// fillArticleStatus 从表单中读取文章状态、定时发布时间和评论开关
+func fillArticleStatus(_article *article.Article, r *http.Request) {
+	var publishAt *time.Time
+	if t, err := time.ParseInLocation("2006-01-02T15:04", r.PostFormValue("published_at"), time.Local); err == nil {
+		publishAt = &t
+	}
+	_article.SetStatus(r.PostFormValue("status"), publishAt)
+	_article.CommentsClosed = r.PostFormValue("comments_closed") == "1"
+}
@@ -3,11 +3,16 @@ package controllers
 import (
 	"encoding/json"
 	"fmt"
+	"goblog/app/models/loginattempt"
 	"goblog/app/models/user"
 	"goblog/app/requests"
+	"goblog/pkg/antispam"
 	"goblog/pkg/auth"
 	"goblog/pkg/flash"
+	"goblog/pkg/oidc"
+	"goblog/pkg/route"
 	"goblog/pkg/view"
+	"log"
 	"net/http"
 )
 
@@ -15,7 +20,7 @@ type AuthController struct {
 }
 
 func (*AuthController) Register(w http.ResponseWriter, r *http.Request) {
-	view.RenderSimple(w, view.D{}, "auth.register")
+	view.RenderSimple(w, r, view.D{}, "auth.register")
 }
 
 // DoRegister 处理注册逻辑
@@ -31,17 +36,29 @@ func (*AuthController) DoRegister(w http.ResponseWriter, r *http.Request) {
 	// 2. 表单规则
 	errs := requests.ValidateRegistrationForm(_user)
 
+	// 3. 拦截机器人注册：蜜罐字段、提交时间以及垃圾内容过滤器
+	errs = requests.ValidateFormGuard(r, errs, "name")
+	if antispam.Default.IsSpam(antispam.Sample{Name: _user.Name, Email: _user.Email, IP: route.ClientIP(r)}) {
+		errs["email"] = append(errs["email"], "注册请求被识别为垃圾注册，请联系管理员")
+	}
+
 	if len(errs) > 0 {
-		// 3. 有错误发生，打印数据
+		// 4. 有错误发生，打印数据
 		data, _ := json.MarshalIndent(errs, "", "  ")
 		fmt.Fprint(w, string(data))
 	} else {
-		// 4. 验证成功，创建数据
+		// 5. 验证成功，创建数据
 		_user.Create()
 
 		if _user.ID > 0 {
-			flash.Success("恭喜您注册成功")
-			auth.Login(_user)
+			// 发送邮箱验证链接，验证前不能发布文章和评论
+			if err := sendVerificationEmail(&_user); err != nil {
+				log.Println("发送验证邮件失败：", err)
+			}
+			if err := auth.Login(r, _user); err != nil {
+				log.Println("注册后自动登录失败：", err)
+			}
+			flash.Success(r, "恭喜您注册成功，验证邮件已发送至 "+_user.Email+"，请查收")
 			http.Redirect(w, r, "/", http.StatusFound)
 		} else {
 			w.WriteHeader(http.StatusInternalServerError)
@@ -53,32 +70,61 @@ func (*AuthController) DoRegister(w http.ResponseWriter, r *http.Request) {
 }
 
 func (*AuthController) Login(w http.ResponseWriter, r *http.Request) {
-	view.RenderSimple(w, view.D{}, "auth.login")
+	renderLogin(w, r, view.D{})
 }
 
 func (*AuthController) DoLogin(w http.ResponseWriter, r *http.Request) {
 	// 1. 初始化表单数据
 	email := r.PostFormValue("email")
 	password := r.PostFormValue("password")
+	remember := r.PostFormValue("remember") == "1"
+
+	// 2. 多次登录失败后，需等待一段时间才能再次尝试
+	ip := route.ClientIP(r)
+	if err := auth.Throttled(email, ip); err != nil {
+		auth.Failed(email, ip, r.UserAgent(), loginattempt.ReasonLocked)
+		renderLogin(w, r, view.D{
+			"Lockout": err.Error(),
+			"Email":   email,
+		})
+		return
+	}
 
-	// 2. 尝试登录
-	if err := auth.Attempt(email, password); err == nil {
+	// 3. 尝试登录
+	if err := auth.Attempt(w, r, email, password, remember); err == nil {
 		// 登录成功
-		flash.Success("欢迎回来")
+		auth.Succeeded(email)
+		flash.Success(r, "欢迎回来")
 		http.Redirect(w, r, "/", http.StatusFound)
+	} else if err == auth.ErrTwoFactorRequired {
+		// 密码正确，继续输入两步验证码
+		http.Redirect(w, r, route.Name2URL("auth.two_factor"), http.StatusFound)
 	} else {
-		// 3. 失败，显示错误提示
-		view.RenderSimple(w, view.D{
+		// 4. 失败，记录失败次数并显示错误提示
+		data := view.D{
 			"Error":    err.Error(),
 			"Email":    email,
 			"Password": password,
-		}, "auth.login")
+			"Remember": remember,
+		}
+		if err == auth.ErrInvalidCredentials {
+			if throttled := auth.Failed(email, ip, r.UserAgent(), loginattempt.ReasonPassword); throttled != nil {
+				data["Lockout"] = throttled.Error()
+			}
+		}
+		renderLogin(w, r, data)
 	}
 
 }
 
+// renderLogin 显示登录表单，以及已启用的外部身份提供方
+func renderLogin(w http.ResponseWriter, r *http.Request, data view.D) {
+	data["Providers"] = oidc.All()
+	view.RenderSimple(w, r, data, "auth.login")
+}
+
 func (*AuthController) Logout(w http.ResponseWriter, r *http.Request) {
-	auth.Logout()
-	flash.Success("您已退出登录")
+	auth.Logout(w, r)
+	flash.Success(r, "您已退出登录")
 	http.Redirect(w, r, "/", http.StatusFound)
 }
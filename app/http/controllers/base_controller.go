@@ -3,7 +3,7 @@ package controllers
 import (
 	"fmt"
 	"goblog/pkg/flash"
-	"goblog/pkg/logger"
+	"log"
 	"net/http"
 
 	"gorm.io/gorm"
@@ -20,8 +20,8 @@ func (bc BaseController) ResponseForSQLError(w http.ResponseWriter, err error) {
 		w.WriteHeader(http.StatusNotFound)
 		fmt.Fprint(w, "404 文章未找到")
 	} else {
-		// 3.2 数据库错误
-		logger.LogError(err)
+		// 3.2 数据库错误，记录日志后返回 500，不退出程序
+		log.Println("数据库错误：", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(w, "500 服务器内部错误")
 	}
@@ -29,6 +29,6 @@ func (bc BaseController) ResponseForSQLError(w http.ResponseWriter, err error) {
 
 // ResponseForUnauthorized 处理未授权的访问
 func (bc BaseController) ResponseForUnauthorized(w http.ResponseWriter, r *http.Request) {
-	flash.Warning("未授权操作！")
+	flash.Warning(r, "未授权操作！")
 	http.Redirect(w, r, "/", http.StatusFound)
 }
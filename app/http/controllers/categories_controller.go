@@ -9,6 +9,8 @@ import (
 	"goblog/pkg/route"
 	"goblog/pkg/view"
 	"net/http"
+
+	"gorm.io/gorm"
 )
 
 // CategoriesController 文章分类控制器
@@ -18,7 +20,7 @@ type CategoriesController struct {
 
 // Create 文章分类创建页面
 func (*CategoriesController) Create(w http.ResponseWriter, r *http.Request) {
-	view.Render(w, view.D{}, "categories.create")
+	view.Render(w, r, view.D{}, "categories.create")
 }
 
 // Store 保存文章分类
@@ -37,7 +39,7 @@ func (*CategoriesController) Store(w http.ResponseWriter, r *http.Request) {
 		// 创建文章分类
 		_category.Create()
 		if _category.ID > 0 {
-			flash.Success("分类创建成功")
+			flash.Success(r, "分类创建成功")
 			indexURL := route.Name2URL("home")
 			http.Redirect(w, r, indexURL, http.StatusFound)
 		} else {
@@ -45,14 +47,14 @@ func (*CategoriesController) Store(w http.ResponseWriter, r *http.Request) {
 			fmt.Fprint(w, "创建文章分类失败，请联系管理员")
 		}
 	} else {
-		view.Render(w, view.D{
+		view.Render(w, r, view.D{
 			"Category": _category,
 			"Errors":   errors,
 		}, "categories.create")
 	}
 }
 
-// Show 显示分类下的文章列表
+// Show 显示分类下的文章列表，已生成 slug 的分类 301 跳转到 slug 链接
 func (cc *CategoriesController) Show(w http.ResponseWriter, r *http.Request) {
 
 	// 1. 获取 URL 参数
@@ -60,18 +62,57 @@ func (cc *CategoriesController) Show(w http.ResponseWriter, r *http.Request) {
 
 	// 2. 读取对应的数据
 	_category, err := category.Get(id)
+
+	if err != nil {
+		cc.ResponseForSQLError(w, err)
+	} else if len(_category.Slug) > 0 {
+		// 保留分页等查询参数
+		showURL := _category.Link()
+		if len(r.URL.RawQuery) > 0 {
+			showURL += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, showURL, http.StatusMovedPermanently)
+	} else {
+		cc.show(w, r, _category)
+	}
+}
+
+// ShowBySlug 通过 slug 访问的分类文章列表，旧 slug 301 跳转到新链接
+func (cc *CategoriesController) ShowBySlug(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取 URL 参数
+	s := route.GetRouteVariable("slug", r)
+
+	// 2. 读取对应的数据
+	_category, err := category.GetBySlug(s)
+
+	// 3. 名称修改过的分类，旧 slug 跳转到新链接
+	if err == gorm.ErrRecordNotFound {
+		if _old, err := category.GetByOldSlug(s); err == nil {
+			http.Redirect(w, r, _old.Link(), http.StatusMovedPermanently)
+			return
+		}
+	}
+
+	// 4. 如果出现错误
 	if err != nil {
 		cc.ResponseForSQLError(w, err)
+	} else {
+		cc.show(w, r, _category)
 	}
+}
+
+// show 显示分类下的文章列表
+func (cc *CategoriesController) show(w http.ResponseWriter, r *http.Request, _category category.Category) {
 
-	// 3. 获取结果集
-	articles, pagerData, err := article.GetByCategoryID(_category.GetStringID(), r, 2)
+	// 1. 获取结果集
+	articles, pagerData, err := article.GetByCategoryID(_category.GetStringID(), _category.Link(), r, 2)
 
 	if err != nil {
 		cc.ResponseForSQLError(w, err)
 	} else {
 		// ---  2. 加载模板 ---
-		view.Render(w, view.D{
+		view.Render(w, r, view.D{
 			"Articles":  articles,
 			"PagerData": pagerData,
 		}, "articles.index", "articles._article_meta")
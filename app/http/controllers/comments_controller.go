@@ -0,0 +1,225 @@
+package controllers
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/comment"
+	"goblog/app/policies"
+	"goblog/app/requests"
+	"goblog/pkg/antispam"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// CommentsController 文章评论控制器
+type CommentsController struct {
+	BaseController
+}
+
+// Store 发表评论，登录用户的评论直接通过，游客的评论需审核
+func (cc *CommentsController) Store(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取文章，未发布或已关闭评论的文章不能评论
+	_article, err := article.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		cc.ResponseForSQLError(w, err)
+		return
+	}
+	if !_article.IsPublished() || _article.CommentsClosed {
+		flash.Warning(r, "该文章已关闭评论")
+		http.Redirect(w, r, _article.Link(), http.StatusFound)
+		return
+	}
+
+	// 2. 初始化数据
+	_comment := comment.Comment{
+		ArticleID: _article.ID,
+		Body:      r.PostFormValue("body"),
+		Status:    comment.StatusPending,
+		IP:        route.ClientIP(r),
+	}
+	if auth.Check(r) {
+		uid := auth.User(r).ID
+		_comment.UserID = &uid
+		_comment.Status = comment.StatusApproved
+	} else {
+		_comment.GuestName = r.PostFormValue("guest_name")
+		_comment.GuestEmail = r.PostFormValue("guest_email")
+	}
+
+	// 3. 回复的评论需属于同一篇文章且已通过
+	var replyTo comment.Comment
+	if parentID := r.PostFormValue("parent_id"); len(parentID) > 0 {
+		if _, err := strconv.ParseUint(parentID, 10, 64); err == nil {
+			if parent, err := comment.Get(parentID); err == nil && parent.ArticleID == _article.ID && parent.IsApproved() {
+				replyTo = parent
+				_comment.ParentID = &parent.ID
+			}
+		}
+	}
+
+	// 4. 表单验证，包括蜜罐字段和提交时间检查
+	errors := requests.ValidateCommentForm(_comment)
+	errors = requests.ValidateFormGuard(r, errors, "body")
+
+	if len(errors) > 0 {
+		data := view.D{
+			"Comment":       _comment,
+			"CommentErrors": errors,
+		}
+		if replyTo.ID > 0 {
+			data["ReplyTo"] = replyTo
+		}
+		if err := renderArticle(w, r, _article, data); err != nil {
+			cc.ResponseForSQLError(w, err)
+		}
+		return
+	}
+
+	// 5. 过滤器判定为垃圾内容的评论不公开，等待审核时人工确认
+	sample := _comment.SpamSample()
+	if auth.Check(r) {
+		sample.Name, sample.Email = auth.User(r).Name, auth.User(r).Email
+	}
+	if antispam.Default.IsSpam(sample) {
+		_comment.Status = comment.StatusSpam
+	}
+
+	// 6. 保存评论
+	if err := _comment.Create(); err != nil {
+		cc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if _comment.IsApproved() {
+		flash.Success(r, "评论发表成功")
+	} else {
+		flash.Info(r, "评论已提交，审核通过后将会显示")
+	}
+	http.Redirect(w, r, _article.Link()+"#comments", http.StatusFound)
+}
+
+// Index 评论审核页面，显示当前用户所写文章下的评论，可审核所有评论的用户显示全部评论，
+// ?status=pending|approved|rejected|spam
+func (cc *CommentsController) Index(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	switch status {
+	case comment.StatusApproved, comment.StatusRejected, comment.StatusSpam:
+	default:
+		status = comment.StatusPending
+	}
+
+	comments, pagerData, err := comment.GetForModeration(auth.User(r).ID, policies.Allows(r, "comment.moderate.any"), status, r, 20)
+
+	if err != nil {
+		cc.ResponseForSQLError(w, err)
+	} else {
+		view.Render(w, r, view.D{
+			"Status":    status,
+			"Comments":  comments,
+			"PagerData": pagerData,
+		}, "comments.index")
+	}
+}
+
+// Approve 通过评论
+func (cc *CommentsController) Approve(w http.ResponseWriter, r *http.Request) {
+	cc.moderate(w, r, comment.StatusApproved, "评论已通过")
+}
+
+// Reject 拒绝评论
+func (cc *CommentsController) Reject(w http.ResponseWriter, r *http.Request) {
+	cc.moderate(w, r, comment.StatusRejected, "评论已拒绝")
+}
+
+// Delete 删除评论及其回复
+func (cc *CommentsController) Delete(w http.ResponseWriter, r *http.Request) {
+	_comment, ok := cc.getComment(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := _comment.Delete(); err != nil {
+		cc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, "评论已删除")
+	cc.redirectBack(w, r, _comment)
+}
+
+// Spam 标记为垃圾评论，并作为垃圾样本训练过滤器
+func (cc *CommentsController) Spam(w http.ResponseWriter, r *http.Request) {
+	cc.train(w, r, antispam.Spam, comment.StatusSpam, "已标记为垃圾评论")
+}
+
+// Ham 标记为正常评论并通过，同时作为正常样本训练过滤器
+func (cc *CommentsController) Ham(w http.ResponseWriter, r *http.Request) {
+	cc.train(w, r, antispam.Ham, comment.StatusApproved, "已标记为正常评论")
+}
+
+// train 修改评论状态，并将评论作为训练样本反馈给垃圾评论过滤器，重复标记为同一分类时不会重复训练
+func (cc *CommentsController) train(w http.ResponseWriter, r *http.Request, class antispam.Class, status string, message string) {
+	_comment, ok := cc.getComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := _comment.SetStatus(status); err != nil {
+		cc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if err := _comment.Train(antispam.Default, class); err != nil {
+		cc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, message)
+	cc.redirectBack(w, r, _comment)
+}
+
+// moderate 修改评论状态
+func (cc *CommentsController) moderate(w http.ResponseWriter, r *http.Request, status string, message string) {
+	_comment, ok := cc.getComment(w, r)
+	if !ok {
+		return
+	}
+
+	if err := _comment.SetStatus(status); err != nil {
+		cc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, message)
+	cc.redirectBack(w, r, _comment)
+}
+
+// getComment 读取路由中的评论，并检查当前用户是否有权限审核
+func (cc *CommentsController) getComment(w http.ResponseWriter, r *http.Request) (comment.Comment, bool) {
+	_comment, err := comment.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		cc.ResponseForSQLError(w, err)
+		return _comment, false
+	}
+
+	if !policies.Allows(r, "comment.moderate", _comment) {
+		cc.ResponseForUnauthorized(w, r)
+		return _comment, false
+	}
+
+	return _comment, true
+}
+
+// redirectBack 操作完成后返回审核页面，表单可通过 redirect 字段指定返回文章页
+func (cc *CommentsController) redirectBack(w http.ResponseWriter, r *http.Request, _comment comment.Comment) {
+	if r.PostFormValue("redirect") == "article" {
+		http.Redirect(w, r, _comment.Article.Link()+"#comments", http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, route.Name2URL("comments.index")+"?status="+url.QueryEscape(r.PostFormValue("status")), http.StatusFound)
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/tag"
+	"goblog/app/models/user"
+	"goblog/pkg/config"
+	"goblog/pkg/feed"
+	"goblog/pkg/route"
+	"log"
+	"net/http"
+)
+
+// FeedsController 订阅源控制器，输出全站、分类、标签和作者的 RSS、Atom 和 JSON Feed
+type FeedsController struct {
+	BaseController
+}
+
+// feedFormats 路由中的文件名对应的订阅源格式，其余按 RSS 输出
+var feedFormats = map[string]string{
+	"atom.xml":  feed.FormatAtom,
+	"feed.json": feed.FormatJSON,
+}
+
+// Index 全站订阅源
+func (fc *FeedsController) Index(w http.ResponseWriter, r *http.Request) {
+	format := route.GetRouteVariable("format", r)
+	fc.serve(w, r, feed.Feed{
+		Title:   config.GetString("app.name"),
+		Link:    route.Name2URL("home"),
+		FeedURL: route.Name2URL("feeds.index", "format", format),
+	}, article.CursorFilter{})
+}
+
+// Category 分类下文章的订阅源
+func (fc *FeedsController) Category(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取分类，路由已限制 id 为数字
+	id := route.GetRouteVariable("id", r)
+	_category, err := category.Get(id)
+	if err != nil {
+		fc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 2. 输出订阅源
+	format := route.GetRouteVariable("format", r)
+	fc.serve(w, r, feed.Feed{
+		Title:   _category.Name + " - " + config.GetString("app.name"),
+		Link:    _category.Link(),
+		FeedURL: route.Name2URL("feeds.category", "id", id, "format", format),
+	}, article.CursorFilter{CategoryID: _category.ID})
+}
+
+// User 作者的订阅源
+func (fc *FeedsController) User(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取作者
+	id := route.GetRouteVariable("id", r)
+	_user, err := user.Get(id)
+	if err != nil {
+		fc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 2. 输出订阅源
+	format := route.GetRouteVariable("format", r)
+	fc.serve(w, r, feed.Feed{
+		Title:   _user.Name + " - " + config.GetString("app.name"),
+		Link:    _user.Link(),
+		FeedURL: route.Name2URL("feeds.user", "id", id, "format", format),
+	}, article.CursorFilter{UserID: _user.ID})
+}
+
+// Tag 标签下文章的订阅源
+func (fc *FeedsController) Tag(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取标签
+	slug := route.GetRouteVariable("slug", r)
+	_tag, err := tag.GetBySlug(slug)
+	if err != nil {
+		fc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 2. 输出订阅源
+	format := route.GetRouteVariable("format", r)
+	fc.serve(w, r, feed.Feed{
+		Title:   _tag.Name + " - " + config.GetString("app.name"),
+		Link:    _tag.Link(),
+		FeedURL: route.Name2URL("feeds.tag", "slug", _tag.Slug, "format", format),
+	}, article.CursorFilter{TagID: _tag.ID})
+}
+
+// serve 读取已发布的文章填充订阅源并输出，按配置输出全文或摘要
+func (fc *FeedsController) serve(w http.ResponseWriter, r *http.Request, f feed.Feed, filter article.CursorFilter) {
+	articles, err := article.GetForFeed(filter, config.GetInt("feed.limit"))
+	if err != nil {
+		fc.ResponseForSQLError(w, err)
+		return
+	}
+
+	f.Description = config.GetString("feed.description")
+	f.Language = "zh-CN"
+	fullContent := config.GetBool("feed.full_content")
+	excerptLength := config.GetInt("feed.excerpt_length")
+
+	for _, _article := range articles {
+		item := feed.Item{
+			// 使用数字 ID 的链接作为唯一标识，修改标题后 slug 链接会变化，阅读器会将其视为新文章
+			ID:         route.Name2URL("articles.show", "id", _article.GetStringID()),
+			Title:      _article.Title,
+			Link:       _article.Link(),
+			Summary:    _article.Excerpt(excerptLength),
+			AuthorName: _article.User.Name,
+			Categories: tag.Names(_article.Tags),
+			Published:  _article.CreatedAt,
+			Updated:    _article.UpdatedAt,
+		}
+		if _article.PublishedAt != nil {
+			item.Published = *_article.PublishedAt
+		}
+		if _article.User.ID > 0 {
+			item.AuthorURL = _article.User.Link()
+		}
+		if fullContent {
+			item.Content = string(_article.RenderedBody())
+		}
+		// 订阅源的更新时间取最近更新的文章
+		if item.Updated.After(f.Updated) {
+			f.Updated = item.Updated
+		}
+		f.Items = append(f.Items, item)
+	}
+
+	format, ok := feedFormats[route.GetRouteVariable("format", r)]
+	if !ok {
+		format = feed.FormatRSS
+	}
+	if err := f.Serve(w, r, format); err != nil {
+		// 编码失败时尚未写入响应，可以返回 500
+		log.Println("生成订阅源失败：", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		fmt.Fprint(w, "500 服务器内部错误")
+	}
+}
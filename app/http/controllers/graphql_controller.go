@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"goblog/app/graphql"
+	"goblog/pkg/auth"
+	"goblog/pkg/response"
+	"net/http"
+)
+
+// GraphQLController GraphQL 接口
+type GraphQLController struct {
+	APIController
+}
+
+// Query 执行 GraphQL 查询。未携带令牌时只能读取公开的数据，修改数据需要 write 权限范围的令牌
+func (gc *GraphQLController) Query(w http.ResponseWriter, r *http.Request) {
+	// 1. 携带了令牌时必须有效，避免客户端误以为已认证
+	if len(r.Header.Get("Authorization")) > 0 {
+		var err error
+		if r, err = auth.AuthenticateToken(r); err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+			response.Error(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+	}
+
+	// 2. 解析请求
+	var params graphql.Params
+	if !gc.decode(w, r, &params) {
+		return
+	}
+	if len(params.Query) == 0 {
+		response.Error(w, http.StatusBadRequest, "缺少 query 参数")
+		return
+	}
+
+	// 3. 执行，查询错误在响应的 errors 中返回
+	response.JSON(w, http.StatusOK, graphql.Execute(r.Context(), auth.TokenUser(r), auth.Token(r), params))
+}
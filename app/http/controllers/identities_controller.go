@@ -0,0 +1,206 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"goblog/app/models/identity"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/oidc"
+	"goblog/pkg/route"
+	"goblog/pkg/session"
+	"goblog/pkg/view"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// oidcTimeout 跳转到身份提供方后，完成登录的有效时间
+const oidcTimeout = 10 * time.Minute
+
+// IdentitiesController 使用外部身份提供方（OpenID Connect / OAuth2）登录，以及在账号设置中关联和取消关联
+type IdentitiesController struct {
+	BaseController
+}
+
+// Redirect 跳转到身份提供方登录
+func (idc *IdentitiesController) Redirect(w http.ResponseWriter, r *http.Request) {
+	idc.start(w, r, false)
+}
+
+// Link 跳转到身份提供方，验证通过后关联到当前用户
+func (idc *IdentitiesController) Link(w http.ResponseWriter, r *http.Request) {
+	idc.start(w, r, true)
+}
+
+// Callback 身份提供方登录后的回调，校验 state、换取令牌并验证身份，然后登录或关联
+func (idc *IdentitiesController) Callback(w http.ResponseWriter, r *http.Request) {
+	// 1. 校验 state，每次发起的 state、nonce 和 verifier 只能使用一次
+	name := route.GetRouteVariable("provider", r)
+	state, _ := session.Get(r, "oidc_state").(string)
+	nonce, _ := session.Get(r, "oidc_nonce").(string)
+	verifier, _ := session.Get(r, "oidc_verifier").(string)
+	pending, _ := session.Get(r, "oidc_provider").(string)
+	at, _ := session.Get(r, "oidc_at").(int64)
+	link, _ := session.Get(r, "oidc_link").(bool)
+	forgetOIDC(r)
+
+	failURL := route.Name2URL("auth.login")
+	if link {
+		failURL = route.Name2URL("settings.identities")
+	}
+
+	p, ok := oidc.Get(name)
+	if !ok || pending != name || len(state) == 0 || time.Since(time.Unix(at, 0)) > oidcTimeout ||
+		subtle.ConstantTimeCompare([]byte(state), []byte(r.URL.Query().Get("state"))) != 1 {
+		flash.Warning(r, oidc.ErrInvalidState.Error())
+		http.Redirect(w, r, failURL, http.StatusFound)
+		return
+	}
+
+	// 2. 用户在身份提供方拒绝授权
+	if len(r.URL.Query().Get("error")) > 0 {
+		flash.Warning(r, "已取消使用"+p.Label+"登录")
+		http.Redirect(w, r, failURL, http.StatusFound)
+		return
+	}
+
+	// 3. 换取令牌并验证身份
+	claims, err := p.Authenticate(r.URL.Query().Get("code"), verifier, nonce)
+	if err != nil {
+		log.Println("外部登录失败：", p.Name, err)
+		flash.Warning(r, "无法通过"+p.Label+"验证身份，请稍后重试")
+		http.Redirect(w, r, failURL, http.StatusFound)
+		return
+	}
+
+	// 4. 关联到当前用户
+	if link {
+		_user := auth.User(r)
+		if _user.ID == 0 {
+			flash.Warning(r, "登录用户才能关联账号")
+			http.Redirect(w, r, route.Name2URL("auth.login"), http.StatusFound)
+			return
+		}
+		if _, err := identity.Link(_user.ID, p.Name, claims); err != nil {
+			idc.responseForIdentityError(w, r, err, failURL)
+			return
+		}
+		flash.Success(r, "已关联"+p.Label)
+		http.Redirect(w, r, failURL, http.StatusFound)
+		return
+	}
+
+	// 5. 登录，找不到关联用户时按邮箱关联或创建
+	if auth.Check(r) {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+	_user, err := identity.Resolve(p.Name, claims)
+	if err != nil {
+		idc.responseForIdentityError(w, r, err, failURL)
+		return
+	}
+
+	switch err := auth.LoginExternal(w, r, _user); err {
+	case nil:
+		flash.Success(r, "欢迎回来")
+		http.Redirect(w, r, "/", http.StatusFound)
+	case auth.ErrTwoFactorRequired:
+		http.Redirect(w, r, route.Name2URL("auth.two_factor"), http.StatusFound)
+	default:
+		flash.Warning(r, err.Error())
+		http.Redirect(w, r, failURL, http.StatusFound)
+	}
+}
+
+// Index 已关联的外部账号，以及可以关联的身份提供方
+func (idc *IdentitiesController) Index(w http.ResponseWriter, r *http.Request) {
+	identities, err := identity.GetByUser(auth.User(r).ID)
+	if err != nil {
+		idc.ResponseForSQLError(w, err)
+		return
+	}
+
+	linked := make(map[string]bool)
+	for _, i := range identities {
+		linked[i.Provider] = true
+	}
+	var available []*oidc.Provider
+	for _, p := range oidc.All() {
+		if !linked[p.Name] {
+			available = append(available, p)
+		}
+	}
+
+	view.Render(w, r, view.D{
+		"Identities": identities,
+		"Providers":  available,
+	}, "settings.identities")
+}
+
+// Unlink 取消关联外部账号
+func (idc *IdentitiesController) Unlink(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseUint(route.GetRouteVariable("id", r), 10, 64)
+
+	rowsAffected, err := identity.Unlink(auth.User(r).ID, id)
+	if err != nil {
+		idc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if rowsAffected > 0 {
+		flash.Success(r, "已取消关联，如果没有设置过密码，可以通过找回密码设置")
+	}
+	http.Redirect(w, r, route.Name2URL("settings.identities"), http.StatusFound)
+}
+
+// start 生成 state、nonce 和 PKCE verifier 保存到会话，然后跳转到身份提供方
+func (idc *IdentitiesController) start(w http.ResponseWriter, r *http.Request, link bool) {
+	backURL := route.Name2URL("auth.login")
+	if link {
+		backURL = route.Name2URL("settings.identities")
+	}
+
+	p, ok := oidc.Get(route.GetRouteVariable("provider", r))
+	if !ok {
+		flash.Warning(r, "不支持该登录方式")
+		http.Redirect(w, r, backURL, http.StatusFound)
+		return
+	}
+	if err := p.Discover(); err != nil {
+		log.Println("获取身份提供方配置失败：", p.Name, err)
+		flash.Warning(r, p.Label+"暂时无法使用，请稍后重试")
+		http.Redirect(w, r, backURL, http.StatusFound)
+		return
+	}
+
+	state, nonce, verifier := oidc.RandomString(), oidc.RandomString(), oidc.RandomString()
+	session.Put(r, "oidc_state", state)
+	session.Put(r, "oidc_nonce", nonce)
+	session.Put(r, "oidc_verifier", verifier)
+	session.Put(r, "oidc_provider", p.Name)
+	session.Put(r, "oidc_at", time.Now().Unix())
+	session.Put(r, "oidc_link", link)
+
+	http.Redirect(w, r, p.AuthCodeURL(state, nonce, verifier), http.StatusFound)
+}
+
+// responseForIdentityError 登录或关联失败，数据库错误以外的错误提示给用户
+func (idc *IdentitiesController) responseForIdentityError(w http.ResponseWriter, r *http.Request, err error, backURL string) {
+	switch err {
+	case identity.ErrEmailRequired, identity.ErrEmailNotVerified, identity.ErrLinkedToOtherUser, identity.ErrProviderLinked:
+		flash.Warning(r, err.Error())
+	default:
+		log.Println("外部登录失败：", err)
+		flash.Warning(r, "内部错误，请稍后尝试")
+	}
+	http.Redirect(w, r, backURL, http.StatusFound)
+}
+
+// forgetOIDC 清除会话中发起外部登录时保存的数据
+func forgetOIDC(r *http.Request) {
+	for _, key := range []string{"oidc_state", "oidc_nonce", "oidc_verifier", "oidc_provider", "oidc_at", "oidc_link"} {
+		session.Forget(r, key)
+	}
+}
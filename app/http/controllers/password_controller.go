@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/user"
+	"goblog/app/models/usersession"
+	"goblog/app/requests"
+	"goblog/pkg/config"
+	"goblog/pkg/flash"
+	"goblog/pkg/logger"
+	"goblog/pkg/mail"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PasswordController 找回密码
+type PasswordController struct {
+	BaseController
+}
+
+// Forgot 找回密码页面，填写注册邮箱
+func (*PasswordController) Forgot(w http.ResponseWriter, r *http.Request) {
+	view.RenderSimple(w, r, view.D{}, "auth.forgot")
+}
+
+// SendResetLink 发送重置密码邮件
+// 无论邮箱是否已注册都显示相同的提示，避免被用来探测注册邮箱
+func (*PasswordController) SendResetLink(w http.ResponseWriter, r *http.Request) {
+	email := strings.TrimSpace(r.PostFormValue("email"))
+
+	// 1. 查找用户，被封禁的用户不能重置密码
+	if _user, err := user.GetByEmail(email); err == nil && !_user.IsBanned() {
+
+		// 2. 生成令牌
+		ttl := time.Duration(config.GetInt("auth.password_reset_expire")) * time.Minute
+		t, err := _user.CreatePasswordReset(ttl)
+		logger.LogError(err)
+
+		// 3. 发送邮件
+		link := route.Name2URL("auth.password.reset", "token", t)
+		err = mail.Send(mail.Message{
+			To:      []string{_user.Email},
+			Subject: "重置密码",
+			Text: fmt.Sprintf("%s，您好：\n\n我们收到了重置您账号密码的申请，请在 %d 分钟内打开以下链接设置新密码：\n\n%s\n\n如果这不是您本人的操作，请忽略此邮件，您的密码不会改变。\n",
+				_user.Name, int(ttl.Minutes()), link),
+		})
+		if err != nil {
+			// 邮件发送失败不影响页面响应，仅记录日志
+			log.Println("发送重置密码邮件失败：", err)
+		}
+	}
+
+	flash.Info(r, "如果该邮箱已注册，您将收到一封重置密码的邮件，请按邮件中的说明操作")
+	http.Redirect(w, r, route.Name2URL("auth.login"), http.StatusFound)
+}
+
+// ResetForm 重置密码页面
+func (pc *PasswordController) ResetForm(w http.ResponseWriter, r *http.Request) {
+	t := route.GetRouteVariable("token", r)
+
+	if _, err := user.GetByResetToken(t); err != nil {
+		pc.responseForInvalidToken(w, r, err)
+		return
+	}
+
+	view.RenderSimple(w, r, view.D{
+		"Token": t,
+	}, "auth.reset")
+}
+
+// Reset 设置新密码，成功后该用户所有已登录的会话失效，需重新登录
+func (pc *PasswordController) Reset(w http.ResponseWriter, r *http.Request) {
+	t := route.GetRouteVariable("token", r)
+
+	// 1. 检查令牌
+	_user, err := user.GetByResetToken(t)
+	if err != nil {
+		pc.responseForInvalidToken(w, r, err)
+		return
+	}
+
+	// 2. 表单验证
+	_user.Password = r.PostFormValue("password")
+	_user.PasswordConfirm = r.PostFormValue("password_confirm")
+	errs := requests.ValidatePasswordResetForm(_user)
+
+	if len(errs) > 0 {
+		view.RenderSimple(w, r, view.D{
+			"Token":  t,
+			"Errors": errs,
+		}, "auth.reset")
+		return
+	}
+
+	// 3. 更新密码
+	if err := _user.ResetPassword(t, _user.Password); err != nil {
+		pc.responseForInvalidToken(w, r, err)
+		return
+	}
+
+	// 4. 退出所有登录设备，包括「记住我」的登录
+	if _, err := usersession.RevokeAllDevices(_user.ID); err != nil {
+		log.Println("退出登录设备失败：", err)
+	}
+
+	flash.Success(r, "密码已重置，请使用新密码登录")
+	http.Redirect(w, r, route.Name2URL("auth.login"), http.StatusFound)
+}
+
+// responseForInvalidToken 令牌无效时返回找回密码页面重新申请
+func (pc *PasswordController) responseForInvalidToken(w http.ResponseWriter, r *http.Request, err error) {
+	if err != user.ErrInvalidResetToken {
+		pc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Warning(r, err.Error())
+	http.Redirect(w, r, route.Name2URL("auth.password.forgot"), http.StatusFound)
+}
@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/policies"
+	"goblog/pkg/auth"
+	"goblog/pkg/diff"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/types"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+
+	"gorm.io/gorm"
+)
+
+// RevisionsController 文章历史版本控制器
+type RevisionsController struct {
+	BaseController
+}
+
+// Index 文章的历史版本列表
+func (rc *RevisionsController) Index(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取文章并检查权限
+	_article, ok := rc.getArticle(w, r)
+	if !ok {
+		return
+	}
+
+	// 2. 读取历史版本
+	revisions, err := article.GetRevisions(_article.ID)
+	if err != nil {
+		rc.ResponseForSQLError(w, err)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"Article":   _article,
+		"Revisions": revisions,
+	}, "revisions.index")
+}
+
+// Diff 对比两个历史版本，?from=1&to=2&mode=line|word
+func (rc *RevisionsController) Diff(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取文章并检查权限
+	_article, ok := rc.getArticle(w, r)
+	if !ok {
+		return
+	}
+
+	// 2. 读取需要对比的两个版本，URL 参数不合法时视为版本不存在
+	query := r.URL.Query()
+	fromID, err1 := strconv.ParseUint(query.Get("from"), 10, 64)
+	toID, err2 := strconv.ParseUint(query.Get("to"), 10, 64)
+	if err1 != nil || err2 != nil {
+		rc.ResponseForSQLError(w, gorm.ErrRecordNotFound)
+		return
+	}
+	from, err := article.GetRevision(_article.ID, fromID)
+	if err != nil {
+		rc.ResponseForSQLError(w, err)
+		return
+	}
+	to, err := article.GetRevision(_article.ID, toID)
+	if err != nil {
+		rc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 3. 计算差异，默认按行对比
+	mode := query.Get("mode")
+	var bodyDiff []diff.Chunk
+	if mode == "word" {
+		bodyDiff = diff.Words(from.Body, to.Body)
+	} else {
+		mode = "line"
+		bodyDiff = diff.Lines(from.Body, to.Body)
+	}
+
+	view.Render(w, r, view.D{
+		"Article":   _article,
+		"From":      from,
+		"To":        to,
+		"Mode":      mode,
+		"TitleDiff": diff.Words(from.Title, to.Title),
+		"BodyDiff":  bodyDiff,
+	}, "revisions.diff")
+}
+
+// Restore 将文章恢复到指定的历史版本，恢复操作本身会产生一个新版本
+func (rc *RevisionsController) Restore(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取文章并检查权限
+	_article, ok := rc.getArticle(w, r)
+	if !ok {
+		return
+	}
+
+	// 2. 读取历史版本
+	revision, err := article.GetRevision(_article.ID, types.StringToUint64(route.GetRouteVariable("revision", r)))
+	if err != nil {
+		rc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 3. 恢复并保存
+	_article.Title = revision.Title
+	_article.Body = revision.Body
+	if _, err := _article.Update(auth.User(r).ID); err != nil {
+		rc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, "已恢复到 "+revision.CreatedAtTime()+" 的版本")
+	http.Redirect(w, r, _article.Link(), http.StatusFound)
+}
+
+// getArticle 读取路由中的文章，并检查当前用户是否有权限管理其历史版本
+func (rc *RevisionsController) getArticle(w http.ResponseWriter, r *http.Request) (article.Article, bool) {
+	_article, err := article.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		rc.ResponseForSQLError(w, err)
+		return _article, false
+	}
+
+	if !policies.Allows(r, "article.update", _article) {
+		rc.ResponseForUnauthorized(w, r)
+		return _article, false
+	}
+
+	return _article, true
+}
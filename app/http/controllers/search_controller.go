@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"goblog/app/models/article"
+	"goblog/pkg/pagination"
+	"goblog/pkg/route"
+	"goblog/pkg/search"
+	"goblog/pkg/view"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SearchController 全文搜索控制器
+type SearchController struct {
+	BaseController
+}
+
+// Index 搜索结果页，?q=关键词
+func (sc *SearchController) Index(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取搜索词
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+
+	// 2. 搜索并分页
+	hits := search.Default.Search(q)
+	baseURL := route.Name2URL("search") + "?q=" + url.QueryEscape(q)
+	_pager := pagination.NewStatic(r, int64(len(hits)), baseURL, 0)
+	pagerData := _pager.Paging()
+
+	var ids []uint64
+	if offset := _pager.Offset(); offset < len(hits) {
+		end := offset + _pager.PerPage
+		if end > len(hits) {
+			end = len(hits)
+		}
+		for _, hit := range hits[offset:end] {
+			ids = append(ids, hit.ID)
+		}
+	}
+
+	// 3. 读取文章并生成高亮摘要
+	articles, err := article.GetPublishedByIDs(ids)
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+
+	results := make([]view.D, 0, len(articles))
+	for _, _article := range articles {
+		results = append(results, view.D{
+			"Article": _article,
+			"Title":   search.Highlight(_article.Title, q, 0),
+			"Snippet": search.Highlight(_article.PlainText(), q, 160),
+		})
+	}
+
+	view.Render(w, r, view.D{
+		"Query":     q,
+		"Results":   results,
+		"PagerData": pagerData,
+	}, "search.index", "articles._article_meta")
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/article"
+	"goblog/app/models/tag"
+	"goblog/pkg/config"
+	"goblog/pkg/route"
+	"goblog/pkg/seo"
+	"goblog/pkg/sitemap"
+	"net/http"
+	"strconv"
+)
+
+// SEOController 站点地图和 robots.txt
+type SEOController struct {
+	BaseController
+}
+
+// Sitemap 站点地图，文章数量超过单个站点地图的容量时输出站点地图索引，
+// 索引中包含固定页面的站点地图和按 ID 分段的文章站点地图
+func (sc *SEOController) Sitemap(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 计算文章需要的站点地图数量
+	total, err := article.CountByCursorFilter(article.CursorFilter{})
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+	size := sitemap.Size(config.GetInt("seo.sitemap_size"))
+	chunks := sitemap.Chunks(total, size)
+
+	// 2. 文章较少时，所有链接放在一个站点地图中
+	if chunks == 1 {
+		urls, err := sitemapPages()
+		if err != nil {
+			sc.ResponseForSQLError(w, err)
+			return
+		}
+		articleURLs, err := sitemapArticles(0, size)
+		if err != nil {
+			sc.ResponseForSQLError(w, err)
+			return
+		}
+		sitemap.Write(w, append(urls, articleURLs...).Encode)
+		return
+	}
+
+	// 3. 输出站点地图索引
+	index := sitemap.Index{{Loc: route.Name2URL("seo.sitemap.pages")}}
+	for i := 1; i <= chunks; i++ {
+		index = append(index, sitemap.URL{Loc: route.Name2URL("seo.sitemap.articles", "page", strconv.Itoa(i))})
+	}
+	sitemap.Write(w, index.Encode)
+}
+
+// SitemapPages 首页、标签和作者页面的站点地图
+func (sc *SEOController) SitemapPages(w http.ResponseWriter, r *http.Request) {
+	urls, err := sitemapPages()
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+	sitemap.Write(w, urls.Encode)
+}
+
+// SitemapArticles 第 page 段文章的站点地图，超出范围时返回 404
+func (sc *SEOController) SitemapArticles(w http.ResponseWriter, r *http.Request) {
+	total, err := article.CountByCursorFilter(article.CursorFilter{})
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+	size := sitemap.Size(config.GetInt("seo.sitemap_size"))
+
+	page, _ := strconv.Atoi(route.GetRouteVariable("page", r))
+	if page < 1 || page > sitemap.Chunks(total, size) {
+		http.NotFound(w, r)
+		return
+	}
+
+	urls, err := sitemapArticles((page-1)*size, size)
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+	sitemap.Write(w, urls.Encode)
+}
+
+// Robots 根据配置生成 robots.txt
+func (sc *SEOController) Robots(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	disallow := seo.ParsePaths(config.GetString("seo.disallow"))
+	fmt.Fprint(w, seo.Robots(disallow, route.Name2URL("seo.sitemap")))
+}
+
+// sitemapPages 首页、标签列表、各标签和作者页面的链接，分类页面需登录访问，不包含在内
+// 作者页面只包含有已发布文章的作者，与文章一样分段查询
+func sitemapPages() (sitemap.URLSet, error) {
+	urls := sitemap.URLSet{
+		{Loc: route.Name2URL("home")},
+		{Loc: route.Name2URL("tags.index")},
+	}
+
+	tags, err := tag.All()
+	if err != nil {
+		return nil, err
+	}
+	for _, _tag := range tags {
+		if len(_tag.Slug) > 0 {
+			urls = append(urls, sitemap.URL{Loc: _tag.Link(), LastMod: _tag.UpdatedAt})
+		}
+	}
+
+	size := sitemap.Size(config.GetInt("seo.sitemap_size"))
+	for offset := 0; ; offset += size {
+		users, err := article.GetAuthorsForSitemap(offset, size)
+		if err != nil {
+			return nil, err
+		}
+		for _, _user := range users {
+			urls = append(urls, sitemap.URL{Loc: _user.Link()})
+		}
+		if len(users) < size {
+			return urls, nil
+		}
+	}
+}
+
+// sitemapArticles 已发布文章的链接，最后修改时间取文章的更新时间
+func sitemapArticles(offset, limit int) (sitemap.URLSet, error) {
+	articles, err := article.GetForSitemap(offset, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	urls := make(sitemap.URLSet, 0, len(articles))
+	for _, _article := range articles {
+		urls = append(urls, sitemap.URL{Loc: _article.Link(), LastMod: _article.UpdatedAt})
+	}
+	return urls, nil
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/usersession"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+)
+
+// SessionsController 管理已登录的设备
+type SessionsController struct {
+	BaseController
+}
+
+// Index 已登录的设备列表
+func (sc *SessionsController) Index(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+	current, _ := auth.CurrentDevice(r)
+
+	devices, err := usersession.GetDevicesByUser(_user.ID)
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"Devices":         devices,
+		"CurrentDeviceID": current.ID,
+	}, "settings.sessions")
+}
+
+// Revoke 退出某个设备的登录，不能退出当前设备（请使用退出登录）
+func (sc *SessionsController) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(route.GetRouteVariable("id", r), 10, 64)
+	current, _ := auth.CurrentDevice(r)
+	if err != nil || id == current.ID {
+		flash.Warning(r, "无法退出当前设备，请使用退出登录")
+		http.Redirect(w, r, route.Name2URL("settings.sessions"), http.StatusFound)
+		return
+	}
+
+	rowsAffected, err := usersession.RevokeDevice(auth.User(r).ID, id)
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if rowsAffected > 0 {
+		flash.Success(r, "已退出该设备的登录")
+	}
+	http.Redirect(w, r, route.Name2URL("settings.sessions"), http.StatusFound)
+}
+
+// RevokeOthers 退出当前设备以外的所有设备，需验证密码
+func (sc *SessionsController) RevokeOthers(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+
+	if !_user.ComparePassword(r.PostFormValue("password")) {
+		flash.Warning(r, "密码错误")
+		http.Redirect(w, r, route.Name2URL("settings.sessions"), http.StatusFound)
+		return
+	}
+
+	current, _ := auth.CurrentDevice(r)
+	rowsAffected, err := usersession.RevokeOtherDevices(_user.ID, current.ID)
+	if err != nil {
+		sc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, fmt.Sprintf("已退出其他 %d 个设备的登录", rowsAffected))
+	http.Redirect(w, r, route.Name2URL("settings.sessions"), http.StatusFound)
+}
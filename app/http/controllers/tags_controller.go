@@ -0,0 +1,128 @@
+package controllers
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/tag"
+	"goblog/app/policies"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// TagsController 标签控制器
+type TagsController struct {
+	BaseController
+}
+
+// Index 所有标签
+func (tc *TagsController) Index(w http.ResponseWriter, r *http.Request) {
+	tags, err := tag.All()
+
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+	} else {
+		view.Render(w, r, view.D{
+			"Tags": tags,
+		}, "tags.index")
+	}
+}
+
+// Show 显示标签下的文章列表
+func (tc *TagsController) Show(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 获取 URL 参数
+	s := route.GetRouteVariable("slug", r)
+
+	// 2. 读取对应的数据
+	_tag, err := tag.GetBySlug(s)
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 3. 获取结果集
+	articles, pagerData, err := article.GetByTagID(_tag.ID, _tag.Link(), r, 0)
+
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+	} else {
+		// ---  4. 加载模板 ---
+		view.Render(w, r, view.D{
+			"Tag":       _tag,
+			"Articles":  articles,
+			"PagerData": pagerData,
+		}, "articles.index", "articles._article_meta")
+	}
+}
+
+// Rename 重命名标签
+func (tc *TagsController) Rename(w http.ResponseWriter, r *http.Request) {
+	_tag, ok := tc.getTag(w, r)
+	if !ok {
+		return
+	}
+
+	name := strings.Join(strings.Fields(r.PostFormValue("name")), " ")
+	if len(name) == 0 || utf8.RuneCountInString(name) > 20 {
+		flash.Danger(r, "标签名称不能为空，且长度不能超过 20 个字")
+	} else if err := _tag.Rename(name); err == tag.ErrNameTaken {
+		flash.Danger(r, err.Error())
+	} else if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	} else {
+		flash.Success(r, "标签已重命名为「"+name+"」")
+	}
+
+	http.Redirect(w, r, route.Name2URL("tags.index"), http.StatusFound)
+}
+
+// Merge 将标签合并到另一个标签
+func (tc *TagsController) Merge(w http.ResponseWriter, r *http.Request) {
+	_tag, ok := tc.getTag(w, r)
+	if !ok {
+		return
+	}
+
+	// 表单参数不合法时视为标签不存在
+	targetID := r.PostFormValue("target_id")
+	if _, err := strconv.ParseUint(targetID, 10, 64); err != nil {
+		tc.ResponseForSQLError(w, gorm.ErrRecordNotFound)
+		return
+	}
+	target, err := tag.Get(targetID)
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if err := _tag.MergeInto(target); err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, "标签「"+_tag.Name+"」已合并到「"+target.Name+"」")
+	http.Redirect(w, r, route.Name2URL("tags.index"), http.StatusFound)
+}
+
+// getTag 读取路由中的标签，并检查当前用户是否有权限管理标签
+func (tc *TagsController) getTag(w http.ResponseWriter, r *http.Request) (tag.Tag, bool) {
+	if !policies.Allows(r, "tag.manage") {
+		tc.ResponseForUnauthorized(w, r)
+		return tag.Tag{}, false
+	}
+
+	_tag, err := tag.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return _tag, false
+	}
+
+	return _tag, true
+}
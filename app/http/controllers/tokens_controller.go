@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"goblog/app/models/accesstoken"
+	"goblog/app/requests"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TokensController 管理调用 API 的个人访问令牌
+type TokensController struct {
+	BaseController
+}
+
+// Index 令牌列表及创建表单
+func (tc *TokensController) Index(w http.ResponseWriter, r *http.Request) {
+	tc.render(w, r, view.D{})
+}
+
+// Store 创建令牌，令牌明文只在创建后显示这一次
+func (tc *TokensController) Store(w http.ResponseWriter, r *http.Request) {
+	// 1. 初始化数据，有效期为空时永不过期
+	_token := accesstoken.AccessToken{
+		UserID: auth.User(r).ID,
+		Name:   strings.TrimSpace(r.PostFormValue("name")),
+		Scopes: strings.Join(r.PostForm["scopes"], ","),
+	}
+	if days, err := strconv.Atoi(r.PostFormValue("expires_in")); err == nil && days > 0 {
+		expiresAt := time.Now().AddDate(0, 0, days)
+		_token.ExpiresAt = &expiresAt
+	}
+
+	// 2. 表单验证
+	if errs := requests.ValidateAccessTokenForm(_token); len(errs) > 0 {
+		tc.render(w, r, view.D{
+			"Form":   _token,
+			"Errors": errs,
+		})
+		return
+	}
+
+	// 3. 创建令牌
+	plain, err := _token.Create()
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, "令牌已创建，请立即复制保存，离开页面后将无法再次查看")
+	tc.render(w, r, view.D{"PlainToken": plain})
+}
+
+// Revoke 撤销令牌，使用该令牌的请求将立即失效
+func (tc *TokensController) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.ParseUint(route.GetRouteVariable("id", r), 10, 64)
+
+	rowsAffected, err := accesstoken.Revoke(auth.User(r).ID, id)
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	}
+
+	if rowsAffected > 0 {
+		flash.Success(r, "令牌已撤销")
+	}
+	http.Redirect(w, r, route.Name2URL("settings.tokens"), http.StatusFound)
+}
+
+// render 渲染令牌设置页面
+func (tc *TokensController) render(w http.ResponseWriter, r *http.Request, data view.D) {
+	tokens, err := accesstoken.GetByUser(auth.User(r).ID)
+	if err != nil {
+		tc.ResponseForSQLError(w, err)
+		return
+	}
+
+	data["Tokens"] = tokens
+	data["Scopes"] = accesstoken.Scopes
+	view.Render(w, r, data, "settings.tokens")
+}
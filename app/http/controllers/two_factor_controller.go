@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"goblog/app/models/loginattempt"
+	"goblog/app/models/user"
+	"goblog/pkg/auth"
+	"goblog/pkg/config"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"goblog/pkg/totp"
+	"goblog/pkg/view"
+	"html/template"
+	"net/http"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// TwoFactorController 两步验证：登录时的第二步，以及在账号设置中启用和关闭
+type TwoFactorController struct {
+	BaseController
+}
+
+// Challenge 登录第二步，输入验证码或恢复码
+func (*TwoFactorController) Challenge(w http.ResponseWriter, r *http.Request) {
+	if _, ok := auth.PendingTwoFactorUser(r); !ok {
+		http.Redirect(w, r, route.Name2URL("auth.login"), http.StatusFound)
+		return
+	}
+
+	view.RenderSimple(w, r, view.D{}, "auth.two_factor")
+}
+
+// DoChallenge 检查验证码，通过后完成登录；验证码错误与密码错误共用登录失败次数限制
+func (*TwoFactorController) DoChallenge(w http.ResponseWriter, r *http.Request) {
+	_user, ok := auth.PendingTwoFactorUser(r)
+	if !ok {
+		flash.Warning(r, auth.ErrTwoFactorExpired.Error())
+		http.Redirect(w, r, route.Name2URL("auth.login"), http.StatusFound)
+		return
+	}
+
+	ip := route.ClientIP(r)
+	if err := auth.Throttled(_user.Email, ip); err != nil {
+		auth.Failed(_user.Email, ip, r.UserAgent(), loginattempt.ReasonLocked)
+		view.RenderSimple(w, r, view.D{"Error": err.Error()}, "auth.two_factor")
+		return
+	}
+
+	switch err := auth.AttemptTwoFactor(w, r, r.PostFormValue("code")); err {
+	case nil:
+		auth.Succeeded(_user.Email)
+		flash.Success(r, "欢迎回来")
+		http.Redirect(w, r, "/", http.StatusFound)
+	case auth.ErrTwoFactorExpired:
+		flash.Warning(r, err.Error())
+		http.Redirect(w, r, route.Name2URL("auth.login"), http.StatusFound)
+	default:
+		message := user.ErrInvalidTwoFactorCode.Error()
+		if throttled := auth.Failed(_user.Email, ip, r.UserAgent(), loginattempt.ReasonTwoFactor); throttled != nil {
+			message = throttled.Error()
+		}
+		view.RenderSimple(w, r, view.D{"Error": message}, "auth.two_factor")
+	}
+}
+
+// Show 两步验证设置页面，正在启用时显示二维码
+func (tfc *TwoFactorController) Show(w http.ResponseWriter, r *http.Request) {
+	tfc.render(w, r, auth.User(r), view.D{})
+}
+
+// Enable 生成新的密钥，扫码并输入验证码确认后才会生效
+func (tfc *TwoFactorController) Enable(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+	if _user.HasTwoFactor() {
+		http.Redirect(w, r, route.Name2URL("account.two_factor"), http.StatusFound)
+		return
+	}
+
+	if _, err := _user.StartTwoFactor(); err != nil {
+		tfc.ResponseForSQLError(w, err)
+		return
+	}
+
+	http.Redirect(w, r, route.Name2URL("account.two_factor"), http.StatusFound)
+}
+
+// Confirm 输入验证码确认启用，显示恢复码
+func (tfc *TwoFactorController) Confirm(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+	if _user.HasTwoFactor() {
+		http.Redirect(w, r, route.Name2URL("account.two_factor"), http.StatusFound)
+		return
+	}
+
+	codes, err := _user.ConfirmTwoFactor(r.PostFormValue("code"))
+	if err == user.ErrInvalidTwoFactorCode {
+		tfc.render(w, r, _user, view.D{"Error": err.Error()})
+		return
+	} else if err != nil {
+		tfc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, "两步验证已启用，请妥善保存恢复码")
+	tfc.render(w, r, _user, view.D{"RecoveryCodes": codes})
+}
+
+// Disable 关闭两步验证，需验证密码；角色要求两步验证时不能关闭
+func (tfc *TwoFactorController) Disable(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+
+	if _user.TwoFactorRequired() {
+		flash.Warning(r, "您的角色要求启用两步验证，无法关闭")
+	} else if !_user.ComparePassword(r.PostFormValue("password")) {
+		flash.Warning(r, "密码错误")
+	} else if err := _user.DisableTwoFactor(); err != nil {
+		tfc.ResponseForSQLError(w, err)
+		return
+	} else {
+		flash.Success(r, "两步验证已关闭")
+	}
+
+	http.Redirect(w, r, route.Name2URL("account.two_factor"), http.StatusFound)
+}
+
+// RegenerateCodes 重新生成恢复码，需验证密码
+func (tfc *TwoFactorController) RegenerateCodes(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+
+	if !_user.HasTwoFactor() || !_user.ComparePassword(r.PostFormValue("password")) {
+		flash.Warning(r, "密码错误")
+		http.Redirect(w, r, route.Name2URL("account.two_factor"), http.StatusFound)
+		return
+	}
+
+	codes, err := _user.RegenerateRecoveryCodes()
+	if err != nil {
+		tfc.ResponseForSQLError(w, err)
+		return
+	}
+
+	flash.Success(r, "已生成新的恢复码，旧的恢复码已失效")
+	tfc.render(w, r, _user, view.D{"RecoveryCodes": codes})
+}
+
+// render 渲染设置页面，正在启用（已生成密钥但未确认）时生成二维码
+func (tfc *TwoFactorController) render(w http.ResponseWriter, r *http.Request, _user user.User, data view.D) {
+	data["User"] = _user
+
+	if len(_user.TwoFactorSecret) > 0 && !_user.HasTwoFactor() {
+		secret, err := _user.TwoFactorSecretPlain()
+		if err != nil {
+			tfc.ResponseForSQLError(w, err)
+			return
+		}
+
+		uri := totp.URI(config.GetString("app.name"), _user.Email, secret)
+		png, err := qrcode.Encode(uri, qrcode.Medium, 220)
+		if err != nil {
+			tfc.ResponseForSQLError(w, err)
+			return
+		}
+
+		data["Secret"] = secret
+		data["QRCode"] = template.URL("data:image/png;base64," + base64.StdEncoding.EncodeToString(png))
+	} else if _user.HasTwoFactor() {
+		data["RemainingCodes"] = _user.RemainingRecoveryCodes()
+	}
+
+	view.Render(w, r, data, "account.two_factor")
+}
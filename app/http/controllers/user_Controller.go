@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"goblog/app/models/article"
 	"goblog/app/models/user"
+	"goblog/pkg/auth"
 	"goblog/pkg/logger"
 	"goblog/pkg/route"
 	"goblog/pkg/view"
@@ -29,13 +30,14 @@ func (uc *UserController) Show(w http.ResponseWriter, r *http.Request) {
 		uc.ResponseForSQLError(w, err)
 	} else {
 		// ---  4. 读取成功，显示用户文章列表 ---
-		articles, err := article.GetByUserID(_user.GetStringID())
+		// 作者本人可以看到自己的草稿
+		articles, err := article.GetByUserID(_user.GetStringID(), auth.User(r).ID == _user.ID)
 		if err != nil {
 			logger.LogError(err)
 			w.WriteHeader(http.StatusInternalServerError)
 			fmt.Fprint(w, "500 服务器内部错误")
 		} else {
-			view.Render(w, view.D{
+			view.Render(w, r, view.D{
 				"Articles": articles,
 			}, "articles.index", "articles._article_meta")
 		}
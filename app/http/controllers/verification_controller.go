@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"fmt"
+	"goblog/app/models/user"
+	"goblog/pkg/auth"
+	"goblog/pkg/config"
+	"goblog/pkg/flash"
+	"goblog/pkg/mail"
+	"goblog/pkg/route"
+	"goblog/pkg/token"
+	"goblog/pkg/view"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// VerificationController 邮箱验证
+type VerificationController struct {
+	BaseController
+}
+
+// Notice 提示用户验证邮箱，可重发验证邮件
+func (*VerificationController) Notice(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+	if _user.IsVerified() {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	view.Render(w, r, view.D{
+		"User": _user,
+	}, "auth.verify")
+}
+
+// Verify 打开邮件中的验证链接，签名包含用户 ID、邮箱和过期时间，修改邮箱后旧链接失效
+func (vc *VerificationController) Verify(w http.ResponseWriter, r *http.Request) {
+
+	// 1. 读取用户
+	_user, err := user.Get(route.GetRouteVariable("id", r))
+	if err != nil {
+		vc.ResponseForSQLError(w, err)
+		return
+	}
+
+	// 2. 检查签名和过期时间
+	query := r.URL.Query()
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires ||
+		!token.Verify(signingKey(), query.Get("signature"), "verify", _user.GetStringID(), _user.Email, query.Get("expires")) {
+		flash.Warning(r, "验证链接无效或已过期，请重新发送验证邮件")
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	// 3. 标记为已验证
+	if !_user.IsVerified() {
+		if err := _user.MarkEmailVerified(); err != nil {
+			vc.ResponseForSQLError(w, err)
+			return
+		}
+	}
+
+	flash.Success(r, "邮箱验证成功")
+	http.Redirect(w, r, "/", http.StatusFound)
+}
+
+// Resend 重新发送验证邮件，两次发送之间至少间隔 auth.verify_resend_interval 秒
+func (vc *VerificationController) Resend(w http.ResponseWriter, r *http.Request) {
+	_user := auth.User(r)
+	if _user.IsVerified() {
+		http.Redirect(w, r, "/", http.StatusFound)
+		return
+	}
+
+	interval := time.Duration(config.GetInt("auth.verify_resend_interval")) * time.Second
+	if _user.VerificationSentAt != nil {
+		if wait := interval - time.Since(*_user.VerificationSentAt); wait > 0 {
+			flash.Warning(r, fmt.Sprintf("发送过于频繁，请 %d 秒后再试", int(wait.Seconds())+1))
+			http.Redirect(w, r, route.Name2URL("auth.verify.notice"), http.StatusFound)
+			return
+		}
+	}
+
+	if err := sendVerificationEmail(&_user); err != nil {
+		flash.Danger(r, "验证邮件发送失败，请稍后再试")
+	} else {
+		flash.Success(r, "验证邮件已发送至 "+_user.Email+"，请查收")
+	}
+	http.Redirect(w, r, route.Name2URL("auth.verify.notice"), http.StatusFound)
+}
+
+// sendVerificationEmail 发送带签名的邮箱验证链接，并记录发送时间
+func sendVerificationEmail(_user *user.User) error {
+	ttl := time.Duration(config.GetInt("auth.verify_expire")) * time.Minute
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	signature := token.Sign(signingKey(), "verify", _user.GetStringID(), _user.Email, expires)
+	link := route.Name2URL("auth.verify", "id", _user.GetStringID()) + "?expires=" + expires + "&signature=" + signature
+
+	if err := _user.TouchVerificationSent(); err != nil {
+		return err
+	}
+
+	return mail.Send(mail.Message{
+		To:      []string{_user.Email},
+		Subject: "验证您的邮箱",
+		Text: fmt.Sprintf("%s，您好：\n\n请在 %d 小时内打开以下链接验证您的邮箱：\n\n%s\n\n如果这不是您本人的操作，请忽略此邮件。\n",
+			_user.Name, int(ttl.Hours()), link),
+	})
+}
+
+// signingKey 签名链接使用的密钥
+func signingKey() []byte {
+	return []byte(config.GetString("app.key"))
+}
@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"goblog/pkg/auth"
+	"goblog/pkg/response"
+	"net/http"
+)
+
+// APIAuth 使用个人访问令牌认证 API 请求，令牌需拥有 scope 权限范围，如 middlewares.APIAuth("write")(aac.Store)
+func APIAuth(scope string) func(HttpHandlerFunc) HttpHandlerFunc {
+	return func(next HttpHandlerFunc) HttpHandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+
+			r, err := auth.AuthenticateToken(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+				response.Error(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			if !auth.Token(r).Can(scope) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="insufficient_scope", scope="`+scope+`"`)
+				response.Error(w, http.StatusForbidden, "访问令牌缺少 "+scope+" 权限范围")
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
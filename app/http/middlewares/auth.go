@@ -10,8 +10,16 @@ import (
 func Auth(next HttpHandlerFunc) HttpHandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 
-		if !auth.Check() {
-			flash.Warning("登录用户才能访问此页面")
+		if !auth.Check(r) {
+			flash.Warning(r, "登录用户才能访问此页面")
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+
+		// 登录后被封禁的用户，退出登录
+		if auth.User(r).IsBanned() {
+			auth.Logout(w, r)
+			flash.Warning(r, "账号已被封禁")
 			http.Redirect(w, r, "/", http.StatusFound)
 			return
 		}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"goblog/app/policies"
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"net/http"
+)
+
+// Can 拥有权限的登录用户才可访问，如 middlewares.Can("category.create")(cc.Create)
+func Can(ability string) func(HttpHandlerFunc) HttpHandlerFunc {
+	return func(next HttpHandlerFunc) HttpHandlerFunc {
+		return Auth(func(w http.ResponseWriter, r *http.Request) {
+
+			if !policies.Allows(r, ability) {
+				// 角色要求两步验证，但用户尚未启用时，引导用户完成设置
+				if auth.User(r).NeedsTwoFactorSetup() {
+					flash.Warning(r, "您的角色要求启用两步验证，请先完成设置")
+					http.Redirect(w, r, route.Name2URL("account.two_factor"), http.StatusFound)
+					return
+				}
+				flash.Warning(r, "未授权操作！")
+				http.Redirect(w, r, "/", http.StatusFound)
+				return
+			}
+
+			next(w, r)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"goblog/pkg/auth"
 	"goblog/pkg/session"
 	"net/http"
 )
@@ -9,10 +10,13 @@ import (
 func StartSession(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
-		// 1. 启动会话
-		session.StartSession(w, r)
+		// 1. 启动会话，会话和登录状态的缓存保存在请求的 context 中
+		r = auth.WithCache(session.Start(w, r))
 
-		// 2. . 继续处理接下去的请求
+		// 2. 会话中没有登录用户时，使用「记住我」令牌自动登录
+		auth.LoginFromRemember(w, r)
+
+		// 3. . 继续处理接下去的请求
 		next.ServeHTTP(w, r)
 	})
 }
@@ -0,0 +1,22 @@
+package middlewares
+
+import (
+	"goblog/pkg/auth"
+	"goblog/pkg/flash"
+	"goblog/pkg/route"
+	"net/http"
+)
+
+// Verified 已登录但未验证邮箱的用户不可访问，游客不受影响（需登录的页面由 Auth 处理）
+func Verified(next HttpHandlerFunc) HttpHandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+
+		if _user := auth.User(r); _user.ID > 0 && !_user.IsVerified() {
+			flash.Warning(r, "请先验证您的邮箱")
+			http.Redirect(w, r, route.Name2URL("auth.verify.notice"), http.StatusFound)
+			return
+		}
+
+		next(w, r)
+	}
+}
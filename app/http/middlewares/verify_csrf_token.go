@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"bytes"
+	"goblog/pkg/csrf"
+	"goblog/pkg/view"
+	"net/http"
+	"strings"
+)
+
+// csrfExceptPrefixes 不检查 CSRF 令牌的路径前缀，csrfExceptPaths 为需完全一致的路径
+// 这些路由使用请求头中的 API 令牌认证，不读取会话
+var (
+	csrfExceptPrefixes = []string{"/api/"}
+	csrfExceptPaths    = []string{"/graphql"}
+)
+
+// VerifyCSRFToken 检查 POST 等修改数据的请求中的 CSRF 令牌，需在 StartSession 之后执行
+func VerifyCSRFToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		// 1. 只读请求和豁免的路由无需检查
+		if isReadOnly(r.Method) || isCSRFExcept(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// 2. 令牌不一致时显示 419 页面，渲染时可能写入会话 Cookie，需在写入状态码之前渲染
+		if !csrf.Verify(r) {
+			var buf bytes.Buffer
+			view.RenderSimple(&buf, r, view.D{}, "errors.419")
+			w.WriteHeader(419)
+			w.Write(buf.Bytes())
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isReadOnly 是否为不修改数据的请求方法
+func isReadOnly(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+// isCSRFExcept 路径是否在豁免列表中
+func isCSRFExcept(path string) bool {
+	for _, prefix := range csrfExceptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	for _, except := range csrfExceptPaths {
+		if path == except {
+			return true
+		}
+	}
+	return false
+}
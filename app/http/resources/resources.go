@@ -0,0 +1,142 @@
+// Package resources 将模型转换为 API 输出的 JSON 结构，只包含可以公开的字段
+package resources
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/tag"
+	"goblog/app/models/user"
+	"goblog/pkg/pagination"
+	"time"
+)
+
+// Collection 分页列表
+type Collection struct {
+	Data interface{}         `json:"data"`
+	Meta pagination.ViewData `json:"meta"`
+}
+
+// Item 单个资源
+type Item struct {
+	Data interface{} `json:"data"`
+}
+
+// ArticleResource 文章
+type ArticleResource struct {
+	ID             uint64        `json:"id"`
+	Title          string        `json:"title"`
+	Slug           string        `json:"slug"`
+	Body           string        `json:"body"`
+	BodyHTML       string        `json:"body_html"`
+	Status         string        `json:"status"`
+	PublishedAt    *time.Time    `json:"published_at"`
+	CategoryID     uint64        `json:"category_id"`
+	Tags           []string      `json:"tags"`
+	CommentsClosed bool          `json:"comments_closed"`
+	CommentCount   uint64        `json:"comment_count"`
+	Author         *UserResource `json:"author,omitempty"`
+	URL            string        `json:"url"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+// CategoryResource 分类
+type CategoryResource struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Slug      string    `json:"slug"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// UserResource 用户，邮箱、角色和封禁状态只对本人和管理员输出
+type UserResource struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Email     string     `json:"email,omitempty"`
+	RoleID    uint64     `json:"role_id,omitempty"`
+	BannedAt  *time.Time `json:"banned_at,omitempty"`
+	URL       string     `json:"url"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Article 转换文章，已预加载作者时包含作者信息
+func Article(a article.Article) ArticleResource {
+	res := ArticleResource{
+		ID:             a.ID,
+		Title:          a.Title,
+		Slug:           a.Slug,
+		Body:           a.Body,
+		BodyHTML:       string(a.RenderedBody()),
+		Status:         a.Status,
+		PublishedAt:    a.PublishedAt,
+		CategoryID:     a.CategoryID,
+		Tags:           tag.Names(a.Tags),
+		CommentsClosed: a.CommentsClosed,
+		CommentCount:   a.CommentCount,
+		URL:            a.Link(),
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+	if a.User.ID > 0 {
+		author := User(a.User, false)
+		res.Author = &author
+	}
+	return res
+}
+
+// Articles 转换文章列表
+func Articles(articles []article.Article) []ArticleResource {
+	list := make([]ArticleResource, 0, len(articles))
+	for _, a := range articles {
+		list = append(list, Article(a))
+	}
+	return list
+}
+
+// Category 转换分类
+func Category(c category.Category) CategoryResource {
+	return CategoryResource{
+		ID:        c.ID,
+		Name:      c.Name,
+		Slug:      c.Slug,
+		URL:       c.Link(),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// Categories 转换分类列表
+func Categories(categories []category.Category) []CategoryResource {
+	list := make([]CategoryResource, 0, len(categories))
+	for _, c := range categories {
+		list = append(list, Category(c))
+	}
+	return list
+}
+
+// User 转换用户，private 为 true 时包含邮箱、角色和封禁状态
+func User(u user.User, private bool) UserResource {
+	res := UserResource{
+		ID:        u.ID,
+		Name:      u.Name,
+		URL:       u.Link(),
+		CreatedAt: u.CreatedAt,
+	}
+	if private {
+		res.Email = u.Email
+		res.RoleID = u.RoleID
+		res.BannedAt = u.BannedAt
+	}
+	return res
+}
+
+// Users 转换用户列表
+func Users(users []user.User, private bool) []UserResource {
+	list := make([]UserResource, 0, len(users))
+	for _, u := range users {
+		list = append(list, User(u, private))
+	}
+	return list
+}
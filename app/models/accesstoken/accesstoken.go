@@ -0,0 +1,101 @@
+package accesstoken
+
+import (
+	"strings"
+	"time"
+)
+
+// 令牌的权限范围，admin 包含 write，write 包含 read
+const (
+	// ScopeRead 读取文章、分类和用户
+	ScopeRead = "read"
+	// ScopeWrite 发布、修改和删除文章
+	ScopeWrite = "write"
+	// ScopeAdmin 管理分类和用户，还需要用户的角色拥有对应权限
+	ScopeAdmin = "admin"
+)
+
+// Scopes 所有权限范围及说明，用于设置页面
+var Scopes = []struct {
+	Name        string
+	Description string
+}{
+	{ScopeRead, "读取文章、分类和用户"},
+	{ScopeWrite, "发布、修改和删除文章"},
+	{ScopeAdmin, "管理分类和用户"},
+}
+
+// scopeLevels 权限范围的级别，高级别包含低级别
+var scopeLevels = map[string]int{
+	ScopeRead:  1,
+	ScopeWrite: 2,
+	ScopeAdmin: 3,
+}
+
+// AccessToken 个人访问令牌，用于调用 API，数据库只保存令牌的哈希值，明文只在创建时显示一次
+type AccessToken struct {
+	ID        uint64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	UserID    uint64 `gorm:"not null;index"`
+	Name      string `gorm:"type:varchar(100);not null" valid:"name"`
+	TokenHash string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	// Scopes 逗号分隔的权限范围
+	Scopes     string `gorm:"type:varchar(50);not null" valid:"scopes"`
+	LastUsedAt *time.Time
+	// ExpiresAt 过期时间，为空时永不过期
+	ExpiresAt *time.Time
+	CreatedAt time.Time
+}
+
+// TableName 表名
+func (AccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// ScopeList 权限范围列表
+func (t AccessToken) ScopeList() []string {
+	if len(t.Scopes) == 0 {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+// Can 令牌是否拥有 scope 权限范围
+func (t AccessToken) Can(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if scopeLevels[s] >= scopeLevels[scope] && scopeLevels[scope] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired 是否已过期
+func (t AccessToken) IsExpired() bool {
+	return t.ExpiresAt != nil && !t.ExpiresAt.After(time.Now())
+}
+
+// CreatedAtDate 创建日期
+func (t AccessToken) CreatedAtDate() string {
+	return t.CreatedAt.Format("2006-01-02")
+}
+
+// LastUsedAtTime 最后使用时间，未使用过时返回空字符串
+func (t AccessToken) LastUsedAtTime() string {
+	if t.LastUsedAt == nil {
+		return ""
+	}
+	return t.LastUsedAt.Format("2006-01-02 15:04")
+}
+
+// ExpiresAtDate 过期日期，永不过期时返回空字符串
+func (t AccessToken) ExpiresAtDate() string {
+	if t.ExpiresAt == nil {
+		return ""
+	}
+	return t.ExpiresAt.Format("2006-01-02")
+}
+
+// IsValidScope 是否为已定义的权限范围
+func IsValidScope(scope string) bool {
+	return scopeLevels[scope] > 0
+}
@@ -0,0 +1,56 @@
+package accesstoken
+
+import (
+	"goblog/pkg/model"
+	"goblog/pkg/token"
+	"strings"
+	"time"
+)
+
+// Prefix 令牌前缀，便于识别泄露到代码或日志中的令牌
+const Prefix = "gbp_"
+
+// touchInterval 更新最后使用时间的最短间隔
+const touchInterval = time.Minute
+
+// Create 创建令牌，返回令牌明文
+func (t *AccessToken) Create() (string, error) {
+	plain := Prefix + token.Generate()
+	t.TokenHash = token.Hash(plain)
+	if err := model.DB.Create(t).Error; err != nil {
+		return "", err
+	}
+	return plain, nil
+}
+
+// FindByToken 通过令牌明文查找未过期的令牌
+func FindByToken(plain string) (AccessToken, error) {
+	var t AccessToken
+	err := model.DB.
+		Where("token_hash = ? AND (expires_at IS NULL OR expires_at > ?)", token.Hash(strings.TrimSpace(plain)), time.Now()).
+		First(&t).Error
+	return t, err
+}
+
+// GetByUser 用户的所有令牌，最近创建的在前
+func GetByUser(userID uint64) ([]AccessToken, error) {
+	var tokens []AccessToken
+	err := model.DB.Where("user_id = ?", userID).Order("id desc").Find(&tokens).Error
+	return tokens, err
+}
+
+// Touch 记录最后使用时间，间隔不足一分钟时不更新
+func (t *AccessToken) Touch() error {
+	now := time.Now()
+	if t.LastUsedAt != nil && now.Sub(*t.LastUsedAt) < touchInterval {
+		return nil
+	}
+	t.LastUsedAt = &now
+	return model.DB.Model(t).UpdateColumn("last_used_at", now).Error
+}
+
+// Revoke 删除用户的某个令牌，返回删除的数量，不是该用户的令牌时为 0
+func Revoke(userID, id uint64) (int64, error) {
+	result := model.DB.Where("user_id = ? AND id = ?", userID, id).Delete(&AccessToken{})
+	return result.RowsAffected, result.Error
+}
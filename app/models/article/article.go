@@ -2,19 +2,64 @@ package article
 
 import (
 	"goblog/app/models"
+	"goblog/app/models/tag"
 	"goblog/app/models/user"
+	"goblog/pkg/markdown"
 	"goblog/pkg/route"
+	"goblog/pkg/search"
+	"html/template"
 	"strconv"
+	"strings"
+	"time"
 )
 
+// 文章状态
+const (
+	// StatusDraft 草稿，仅作者可见
+	StatusDraft = "draft"
+	// StatusPublished 已发布
+	StatusPublished = "published"
+	// StatusScheduled 定时发布，到达 PublishedAt 后由调度器自动发布
+	StatusScheduled = "scheduled"
+	// StatusArchived 已归档，不再出现在列表中
+	StatusArchived = "archived"
+)
+
+// statusNames 状态对应的显示名称
+var statusNames = map[string]string{
+	StatusDraft:     "草稿",
+	StatusPublished: "已发布",
+	StatusScheduled: "定时发布",
+	StatusArchived:  "已归档",
+}
+
 type Article struct {
 	models.BaseModel
 	Title  string `gorm:"type:varchar(255);not null" valid:"title"`
-	Body   string `gorm:"type:varchar(255);not null" valid:"body"`
+	Slug   string `gorm:"type:varchar(128);uniqueIndex"`
+	Body   string `gorm:"type:longtext;not null" valid:"body"`
 	UserID uint64 `gorm:"not null;index"`
 	User   user.User
 
 	CategoryID uint64 `gorm:"not null;default:4;index"`
+
+	Tags []tag.Tag `gorm:"many2many:article_tags;"`
+
+	// BodyHTML Markdown 正文渲染并净化后的 HTML 缓存，保存时由 BeforeSave 钩子生成
+	BodyHTML string `gorm:"type:longtext"`
+
+	Status      string     `gorm:"type:varchar(20);not null;default:published;index" valid:"status"`
+	PublishedAt *time.Time `gorm:"index"`
+
+	// CommentsClosed 是否关闭评论
+	CommentsClosed bool `gorm:"not null;default:false"`
+	// CommentCount 已通过的评论数量，由评论模型维护
+	CommentCount uint64 `gorm:"not null;default:0"`
+
+	// editorID 本次修改文章的用户，记录为历史版本的作者，创建时为作者本人
+	editorID uint64
+	// tagsChanged 是否通过 SetTags 修改了标签，为 true 时 Create 和 Update 会在同一事务中保存标签
+	tagsChanged bool
 }
 
 /**
@@ -40,12 +85,99 @@ o.method()
 function()
 */
 
-// Link 方法用来生成文章链接
+// Link 方法用来生成文章链接，优先使用 slug 链接
 func (article Article) Link() string {
-	return route.Name2URL("articles.show", "id", strconv.FormatInt(int64(article.ID), 10))
+	if len(article.Slug) == 0 {
+		return route.Name2URL("articles.show", "id", strconv.FormatInt(int64(article.ID), 10))
+	}
+	return route.Name2URL("articles.slug", "slug", article.Slug)
+}
+
+// RenderedBody 返回渲染后的正文 HTML，旧数据未生成缓存时即时渲染
+func (article Article) RenderedBody() template.HTML {
+	if len(article.BodyHTML) == 0 {
+		return template.HTML(markdown.Render(article.Body))
+	}
+	return template.HTML(article.BodyHTML)
+}
+
+// PlainText 正文的纯文本，用于搜索和摘要
+func (article Article) PlainText() string {
+	return markdown.PlainText(string(article.RenderedBody()))
+}
+
+// Excerpt 正文纯文本的前 maxRunes 个字，超出时以省略号结尾，用于订阅源和页面描述
+func (article Article) Excerpt(maxRunes int) string {
+	text := []rune(strings.Join(strings.Fields(article.PlainText()), " "))
+	if len(text) <= maxRunes {
+		return string(text)
+	}
+	return strings.TrimSpace(string(text[:maxRunes])) + "…"
+}
+
+// SearchDocument 生成用于搜索索引的文档
+func (article Article) SearchDocument() search.Document {
+	t := article.CreatedAt
+	if article.PublishedAt != nil {
+		t = *article.PublishedAt
+	}
+
+	return search.Document{
+		ID:    article.ID,
+		Title: article.Title,
+		Body:  article.PlainText(),
+		Time:  t,
+	}
+}
+
+// TagNames 逗号分隔的标签名称，用以填充表单
+func (article Article) TagNames() string {
+	return strings.Join(tag.Names(article.Tags), ", ")
 }
 
 // CreatedAtDate 创建日期
 func (article Article) CreatedAtDate() string {
 	return article.CreatedAt.Format("2006-01-02")
 }
+
+// PublishedAtDate 发布日期，未设置发布时间的文章使用创建日期
+func (article Article) PublishedAtDate() string {
+	if article.PublishedAt == nil {
+		return article.CreatedAtDate()
+	}
+	return article.PublishedAt.Format("2006-01-02")
+}
+
+// PublishedAtInput 用以填充表单 datetime-local 输入框的发布时间
+func (article Article) PublishedAtInput() string {
+	if article.PublishedAt == nil {
+		return ""
+	}
+	return article.PublishedAt.Format("2006-01-02T15:04")
+}
+
+// IsPublished 是否已发布
+func (article Article) IsPublished() bool {
+	return article.Status == StatusPublished
+}
+
+// StatusName 状态的显示名称
+func (article Article) StatusName() string {
+	return statusNames[article.Status]
+}
+
+// SetStatus 设置文章状态并维护发布时间，publishAt 仅在定时发布时使用
+func (article *Article) SetStatus(status string, publishAt *time.Time) {
+	switch status {
+	case StatusPublished:
+		if article.Status != StatusPublished || article.PublishedAt == nil {
+			now := time.Now()
+			article.PublishedAt = &now
+		}
+	case StatusScheduled:
+		article.PublishedAt = publishAt
+	case StatusDraft:
+		article.PublishedAt = nil
+	}
+	article.Status = status
+}
@@ -1,19 +1,38 @@
 package article
 
 import (
-	"goblog/pkg/logger"
+	"goblog/app/models/tag"
+	"goblog/app/models/user"
 	"goblog/pkg/model"
 	"goblog/pkg/pagination"
 	"goblog/pkg/route"
+	"goblog/pkg/search"
 	"goblog/pkg/types"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// Published 查询作用域，仅包含已发布的文章
+func Published(db *gorm.DB) *gorm.DB {
+	return db.Where("status = ?", StatusPublished)
+}
+
+// Latest 列表排序，按发布时间倒序，相同时按 ID 倒序以保证分页稳定
+// 定时发布的文章在到期后按预定的发布时间排列，而不是创建时间
+func Latest(db *gorm.DB) *gorm.DB {
+	return db.Order("published_at desc").Order("id desc")
+}
+
 // Get 通过 ID 获取文章
 func Get(idstr string) (Article, error) {
 	var article Article
 	id := types.StringToUint64(idstr)
-	if err := model.DB.Preload("User").First(&article, id).Error; err != nil {
+	if err := model.DB.Preload("User").Preload("Tags").First(&article, id).Error; err != nil {
 		return article, err
 	}
 
@@ -24,7 +43,7 @@ func Get(idstr string) (Article, error) {
 func GetAll(r *http.Request, perPage int) ([]Article, pagination.ViewData, error) {
 
 	// 1. 初始化分页实例
-	db := model.DB.Model(Article{}).Order("created_at desc")
+	db := model.DB.Model(Article{}).Scopes(Published, Latest)
 	_pager := pagination.New(r, db, route.Name2URL("home"), perPage)
 
 	// 2. 获取视图数据
@@ -37,54 +56,92 @@ func GetAll(r *http.Request, perPage int) ([]Article, pagination.ViewData, error
 	return articles, viewData, nil
 }
 
-// Create 创建文章，通过 article.ID 来判断是否创建成功
+// Create 创建文章，通过 article.ID 来判断是否创建成功，SetTags 设置的标签在同一事务中保存
 func (article *Article) Create() (err error) {
-	result := model.DB.Create(&article)
-	if err = result.Error; err != nil {
-		logger.LogError(err)
+	err = model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Tags").Create(&article).Error; err != nil {
+			return err
+		}
+		return article.saveTags(tx)
+	})
+	if err != nil {
 		return err
 	}
 
+	article.syncSearchIndex()
 	return nil
 }
 
-// Update 更新文章
-func (article *Article) Update() (rowsAffected int64, err error) {
-	result := model.DB.Save(&article)
-	if err = result.Error; err != nil {
-		logger.LogError(err)
+// editableColumns 更新文章时写入的字段，评论数量等由其他模型维护的字段不在其中，避免写入过期的值
+var editableColumns = []string{"Title", "Body", "BodyHTML", "Slug", "Status", "PublishedAt", "CommentsClosed", "CategoryID", "UpdatedAt"}
+
+// Update 更新文章，editorID 为修改文章的用户，SetTags 设置的标签在同一事务中保存
+func (article *Article) Update(editorID uint64) (rowsAffected int64, err error) {
+	article.editorID = editorID
+	err = model.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(article).Select(editableColumns).Updates(article)
+		if result.Error != nil {
+			return result.Error
+		}
+		rowsAffected = result.RowsAffected
+		return article.saveTags(tx)
+	})
+	if err != nil {
 		return 0, err
 	}
 
-	return result.RowsAffected, nil
+	article.syncSearchIndex()
+	return rowsAffected, nil
 }
 
-// Delete 更新文章
+// Delete 删除文章，同时删除标签关联，评论由外键级联删除
 func (article *Article) Delete() (rowsAffected int64, err error) {
-	result := model.DB.Delete(&article)
+	result := model.DB.Select("Tags").Delete(&article)
 	if err = result.Error; err != nil {
-		logger.LogError(err)
 		return 0, err
 	}
 
+	search.Default.Remove(article.ID)
 	return result.RowsAffected, nil
 }
 
-// GetByUserID 获取全部文章
-func GetByUserID(uid string) ([]Article, error) {
+// GetByUserID 获取用户的文章，withDrafts 为 true 时包含未发布的文章（作者本人查看时使用）
+func GetByUserID(uid string, withDrafts bool) ([]Article, error) {
 	var articles []Article
-	if err := model.DB.Where("user_id = ?", uid).Preload("User").Find(&articles).Error; err != nil {
+	db := model.DB.Where("user_id = ?", uid)
+	if !withDrafts {
+		db = db.Scopes(Published)
+	}
+	if err := db.Preload("User").Preload("Tags").Scopes(Latest).Find(&articles).Error; err != nil {
 		return articles, err
 	}
 	return articles, nil
 }
 
-// GetByCategoryID 获取分类相关的文章
-func GetByCategoryID(cid string, r *http.Request, perPage int) ([]Article, pagination.ViewData, error) {
+// GetByCategoryID 获取分类相关的文章，baseURL 用以生成分页链接
+func GetByCategoryID(cid string, baseURL string, r *http.Request, perPage int) ([]Article, pagination.ViewData, error) {
+
+	// 1. 初始化分页实例
+	db := model.DB.Model(Article{}).Where("category_id = ?", cid).Scopes(Published, Latest)
+	_pager := pagination.New(r, db, baseURL, perPage)
+
+	// 2. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 3. 获取数据
+	var articles []Article
+	_pager.Results(&articles)
+
+	return articles, viewData, nil
+}
+
+// GetByTagID 获取标签下已发布的文章，baseURL 用以生成分页链接
+func GetByTagID(tid uint64, baseURL string, r *http.Request, perPage int) ([]Article, pagination.ViewData, error) {
 
 	// 1. 初始化分页实例
-	db := model.DB.Model(Article{}).Where("category_id = ?", cid).Order("created_at desc")
-	_pager := pagination.New(r, db, route.Name2URL("categories.show", "id", cid), perPage)
+	articleIDs := model.DB.Table("article_tags").Select("article_id").Where("tag_id = ?", tid)
+	db := model.DB.Model(Article{}).Where("id IN (?)", articleIDs).Scopes(Published, Latest)
+	_pager := pagination.New(r, db, baseURL, perPage)
 
 	// 2. 获取视图数据
 	viewData := _pager.Paging()
@@ -95,3 +152,358 @@ func GetByCategoryID(cid string, r *http.Request, perPage int) ([]Article, pagin
 
 	return articles, viewData, nil
 }
+
+// SetTags 根据名称设置文章的标签，在调用 Create 或 Update 时保存
+func (article *Article) SetTags(names []string) {
+	article.Tags = tag.FromNames(names)
+	article.tagsChanged = true
+}
+
+// saveTags 保存 SetTags 设置的标签，不存在的标签会被自动创建，并替换原有的关联
+func (article *Article) saveTags(tx *gorm.DB) error {
+	if !article.tagsChanged {
+		return nil
+	}
+
+	tags, err := tag.FirstOrCreate(tx, tag.Names(article.Tags))
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(article).Association("Tags").Replace(tags); err != nil {
+		return err
+	}
+
+	article.Tags = tags
+	article.tagsChanged = false
+	return nil
+}
+
+// TagCloud 获取标签云，按已发布文章数量取前 limit 个标签并计算权重
+func TagCloud(limit int) ([]tag.CloudItem, error) {
+	var items []tag.CloudItem
+	err := model.DB.Model(&tag.Tag{}).
+		Select("tags.*, COUNT(articles.id) AS count").
+		Joins("JOIN article_tags ON article_tags.tag_id = tags.id").
+		Joins("JOIN articles ON articles.id = article_tags.article_id AND articles.status = ?", StatusPublished).
+		Group("tags.id").
+		Order("count desc").
+		Limit(limit).
+		Scan(&items).Error
+	if err != nil {
+		return items, err
+	}
+
+	return tag.NewCloud(items), nil
+}
+
+// GetPublishedByIDs 获取指定 ID 的已发布文章，按 ids 的顺序返回
+func GetPublishedByIDs(ids []uint64) ([]Article, error) {
+	var articles []Article
+	if len(ids) == 0 {
+		return articles, nil
+	}
+
+	if err := model.DB.Scopes(Published).Preload("User").Preload("Tags").Find(&articles, ids).Error; err != nil {
+		return articles, err
+	}
+
+	// 恢复传入的顺序
+	position := make(map[uint64]int, len(ids))
+	for i, id := range ids {
+		position[id] = i
+	}
+	sort.Slice(articles, func(i, j int) bool {
+		return position[articles[i].ID] < position[articles[j].ID]
+	})
+
+	return articles, nil
+}
+
+// AllPublished 获取所有已发布的文章
+func AllPublished() ([]Article, error) {
+	var articles []Article
+	if err := model.DB.Scopes(Published).Find(&articles).Error; err != nil {
+		return articles, err
+	}
+	return articles, nil
+}
+
+// PublishScheduled 发布所有已到达发布时间的定时文章，返回发布的数量
+func PublishScheduled() (rowsAffected int64, err error) {
+	now := time.Now()
+
+	// 1. 查找到期的定时文章
+	var articles []Article
+	if err = model.DB.Where("status = ? AND published_at <= ?", StatusScheduled, now).Find(&articles).Error; err != nil {
+		return 0, err
+	}
+	if len(articles) == 0 {
+		return 0, nil
+	}
+
+	// 2. 更新状态，使用 UpdateColumns 跳过模型钩子，避免重复生成版本
+	ids := make([]uint64, 0, len(articles))
+	for _, article := range articles {
+		ids = append(ids, article.ID)
+	}
+	result := model.DB.Model(Article{}).
+		Where("id IN ? AND status = ?", ids, StatusScheduled).
+		UpdateColumns(map[string]interface{}{"status": StatusPublished, "updated_at": now})
+	if err = result.Error; err != nil {
+		return 0, err
+	}
+
+	// 3. 加入搜索索引
+	for _, article := range articles {
+		article.Status = StatusPublished
+		article.syncSearchIndex()
+	}
+
+	return result.RowsAffected, nil
+}
+
+// AdminSortable 后台文章列表允许排序的字段
+var AdminSortable = []string{"id", "title", "created_at", "published_at", "comment_count"}
+
+// GetForAdmin 后台文章列表，包括所有状态的文章，支持按标题搜索，按状态、分类和作者筛选
+func GetForAdmin(r *http.Request, q pagination.Query, perPage int) ([]Article, pagination.ViewData, error) {
+
+	// 1. 构建查询条件
+	db := model.DB.Model(Article{}).Order(q.OrderBy())
+	if len(q.Keyword) > 0 {
+		db = db.Where("title LIKE ?", "%"+q.Keyword+"%")
+	}
+	if status := q.Get("status"); len(status) > 0 {
+		db = db.Where("status = ?", status)
+	}
+	if cid := q.Get("category_id"); len(cid) > 0 {
+		db = db.Where("category_id = ?", cid)
+	}
+	if uid := q.Get("user_id"); len(uid) > 0 {
+		db = db.Where("user_id = ?", uid)
+	}
+
+	// 2. 初始化分页实例
+	_pager := pagination.New(r, db, q.URL(), perPage)
+
+	// 3. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 4. 获取数据
+	var articles []Article
+	err := _pager.Results(&articles)
+
+	return articles, viewData, err
+}
+
+// APISortable API 文章列表允许排序的字段
+var APISortable = []string{"published_at", "id", "title", "comment_count"}
+
+// GetForAPI API 文章列表，只包括已发布的文章，支持按标题搜索，按分类和作者筛选
+func GetForAPI(r *http.Request, q pagination.Query, perPage int) ([]Article, pagination.ViewData, error) {
+
+	// 1. 构建查询条件
+	db := model.DB.Model(Article{}).Scopes(Published).Order(q.OrderBy())
+	if len(q.Keyword) > 0 {
+		db = db.Where("title LIKE ?", "%"+q.Keyword+"%")
+	}
+	if cid := q.Get("category_id"); len(cid) > 0 {
+		db = db.Where("category_id = ?", cid)
+	}
+	if uid := q.Get("user_id"); len(uid) > 0 {
+		db = db.Where("user_id = ?", uid)
+	}
+
+	// 2. 初始化分页实例
+	_pager := pagination.New(r, db, q.URL(), perPage)
+
+	// 3. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 4. 获取数据
+	var articles []Article
+	err := _pager.Results(&articles)
+
+	return articles, viewData, err
+}
+
+// GetByIDs 通过 ID 批量获取文章，包括所有状态
+func GetByIDs(ids []uint64) ([]Article, error) {
+	var articles []Article
+	err := model.DB.Where("id IN ?", ids).Find(&articles).Error
+	return articles, err
+}
+
+// CountByStatus 各状态的文章数量
+func CountByStatus() (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := model.DB.Model(Article{}).Select("status, count(*) as count").Group("status").Scan(&rows).Error
+
+	counts := map[string]int64{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, err
+}
+
+// CountByCategory 各分类下的文章数量
+func CountByCategory(categoryIDs []uint64) (map[uint64]int64, error) {
+	var rows []struct {
+		CategoryID uint64
+		Count      int64
+	}
+	err := model.DB.Model(Article{}).Select("category_id, count(*) as count").
+		Where("category_id IN ?", categoryIDs).Group("category_id").Scan(&rows).Error
+
+	counts := map[uint64]int64{}
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, err
+}
+
+// CountByUser 用户的文章数量，包括所有状态
+func CountByUser(uid uint64) (count int64, err error) {
+	err = model.DB.Model(Article{}).Where("user_id = ?", uid).Count(&count).Error
+	return
+}
+
+// Recent 最近创建的文章，包括所有状态
+func Recent(limit int) ([]Article, error) {
+	var articles []Article
+	err := model.DB.Preload("User").Order("id desc").Limit(limit).Find(&articles).Error
+	return articles, err
+}
+
+// SetCategory 批量修改文章分类
+func SetCategory(ids []uint64, categoryID uint64) (rowsAffected int64, err error) {
+	result := model.DB.Model(Article{}).Where("id IN ?", ids).UpdateColumn("category_id", categoryID)
+	return result.RowsAffected, result.Error
+}
+
+// CursorFilter 游标分页的筛选条件，零值的条件不生效
+type CursorFilter struct {
+	Keyword    string
+	CategoryID uint64
+	UserID     uint64
+	TagID      uint64
+}
+
+// scope 筛选条件对应的查询
+func (f CursorFilter) scope(db *gorm.DB) *gorm.DB {
+	db = db.Scopes(Published)
+	if len(f.Keyword) > 0 {
+		db = db.Where("title LIKE ?", "%"+f.Keyword+"%")
+	}
+	if f.CategoryID > 0 {
+		db = db.Where("category_id = ?", f.CategoryID)
+	}
+	if f.UserID > 0 {
+		db = db.Where("user_id = ?", f.UserID)
+	}
+	if f.TagID > 0 {
+		db = db.Where("id IN (?)", model.DB.Table("article_tags").Select("article_id").Where("tag_id = ?", f.TagID))
+	}
+	return db
+}
+
+// GetByCursor 已发布的文章按 ID 倒序排列，取 ID 小于 beforeID 的 limit 篇，beforeID 为 0 时从最新的开始
+// 只预加载标签，作者由调用方批量加载
+func GetByCursor(f CursorFilter, beforeID uint64, limit int) ([]Article, error) {
+	db := model.DB.Scopes(f.scope)
+	if beforeID > 0 {
+		db = db.Where("id < ?", beforeID)
+	}
+
+	var articles []Article
+	err := db.Preload("Tags").Order("id desc").Limit(limit).Find(&articles).Error
+	return articles, err
+}
+
+// 批量读取多个作者或分类的文章时的分组字段
+const (
+	GroupByUser     = "user_id"
+	GroupByCategory = "category_id"
+)
+
+// GetByCursorInGroups 与 GetByCursor 相同，但按 column 分组，每组各取 ID 小于 beforeID 的 limit 篇
+// column 为 GroupByUser 或 GroupByCategory，各组的查询使用 UNION ALL 合并，不随分组数量增加查询次数
+func GetByCursorInGroups(column string, groupIDs []uint64, beforeID uint64, limit int) ([]Article, error) {
+	var articles []Article
+	if len(groupIDs) == 0 {
+		return articles, nil
+	}
+
+	// 1. 每组最新的 limit 篇文章的 ID
+	parts := make([]string, len(groupIDs))
+	subQueries := make([]interface{}, len(groupIDs))
+	for i, groupID := range groupIDs {
+		db := model.DB.Model(&Article{}).Scopes(Published).Where(column+" = ?", groupID)
+		if beforeID > 0 {
+			db = db.Where("id < ?", beforeID)
+		}
+		subQueries[i] = db.Select("id").Order("id desc").Limit(limit)
+		parts[i] = "SELECT id FROM (?) AS g" + strconv.Itoa(i)
+	}
+	var ids []uint64
+	if err := model.DB.Raw(strings.Join(parts, " UNION ALL "), subQueries...).Scan(&ids).Error; err != nil {
+		return articles, err
+	}
+	if len(ids) == 0 {
+		return articles, nil
+	}
+
+	// 2. 读取文章，只预加载标签
+	err := model.DB.Preload("Tags").Where("id IN ?", ids).Order("id desc").Find(&articles).Error
+	return articles, err
+}
+
+// CountInGroups 按 column 分组统计已发布的文章数量，没有文章的分组不在结果中
+func CountInGroups(column string, groupIDs []uint64) (map[uint64]int64, error) {
+	var rows []struct {
+		GroupID uint64
+		Total   int64
+	}
+	err := model.DB.Model(&Article{}).Scopes(Published).
+		Select(column+" AS group_id, COUNT(*) AS total").
+		Where(column+" IN ?", groupIDs).Group(column).Scan(&rows).Error
+
+	counts := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.GroupID] = row.Total
+	}
+	return counts, err
+}
+
+// CountByCursorFilter 符合筛选条件的已发布文章数量
+func CountByCursorFilter(f CursorFilter) (count int64, err error) {
+	err = model.DB.Model(Article{}).Scopes(f.scope).Count(&count).Error
+	return
+}
+
+// GetForFeed 订阅源中的文章，按发布时间倒序，预加载作者和标签
+func GetForFeed(f CursorFilter, limit int) ([]Article, error) {
+	var articles []Article
+	err := model.DB.Scopes(f.scope, Latest).Preload("User").Preload("Tags").Limit(limit).Find(&articles).Error
+	return articles, err
+}
+
+// GetForSitemap 站点地图中的文章，按 ID 排序以保证分页稳定，只读取生成链接需要的字段
+func GetForSitemap(offset, limit int) ([]Article, error) {
+	var articles []Article
+	err := model.DB.Scopes(Published).Select("id", "slug", "updated_at").
+		Order("id").Offset(offset).Limit(limit).Find(&articles).Error
+	return articles, err
+}
+
+// GetAuthorsForSitemap 有已发布文章的作者，按 ID 排序以保证分页稳定，只读取生成链接需要的字段
+func GetAuthorsForSitemap(offset, limit int) ([]user.User, error) {
+	var users []user.User
+	authorIDs := model.DB.Model(&Article{}).Scopes(Published).Select("user_id")
+	err := model.DB.Select("id").Where("id IN (?)", authorIDs).
+		Order("id").Offset(offset).Limit(limit).Find(&users).Error
+	return users, err
+}
@@ -0,0 +1,30 @@
+package article
+
+import (
+	"goblog/pkg/markdown"
+	"goblog/pkg/search"
+
+	"gorm.io/gorm"
+)
+
+// BeforeSave GORM 的模型钩子，在创建和更新模型前调用，缓存渲染后的正文 HTML 并生成 slug
+func (article *Article) BeforeSave(tx *gorm.DB) (err error) {
+	article.BodyHTML = markdown.Render(article.Body)
+	return article.generateSlug(tx)
+}
+
+// AfterSave GORM 的模型钩子，在创建和更新模型后调用，记录历史版本
+// 钩子与保存操作处于同一事务中，写入版本失败时保存会被回滚
+func (article *Article) AfterSave(tx *gorm.DB) (err error) {
+	return article.createRevision(tx)
+}
+
+// syncSearchIndex 已发布的文章加入搜索索引，其他状态的文章从索引中移除
+// 搜索索引不在事务中，需在事务提交后调用，避免回滚的修改进入索引
+func (article *Article) syncSearchIndex() {
+	if article.IsPublished() {
+		search.Default.Add(article.SearchDocument())
+	} else {
+		search.Default.Remove(article.ID)
+	}
+}
@@ -0,0 +1,75 @@
+package article
+
+import (
+	"goblog/app/models"
+	"goblog/app/models/user"
+	"goblog/pkg/model"
+
+	"gorm.io/gorm"
+)
+
+// Revision 文章的历史版本，每次创建和更新文章时写入，写入后不再修改
+type Revision struct {
+	models.BaseModel
+
+	ArticleID uint64 `gorm:"not null;index"`
+	UserID    uint64 `gorm:"not null;index"`
+	User      user.User
+
+	Title string `gorm:"type:varchar(255);not null"`
+	Body  string `gorm:"type:longtext;not null"`
+}
+
+// TableName 指定表名为 article_revisions
+func (Revision) TableName() string {
+	return "article_revisions"
+}
+
+// CreatedAtTime 版本创建时间
+func (revision Revision) CreatedAtTime() string {
+	return revision.CreatedAt.Format("2006-01-02 15:04:05")
+}
+
+// GetRevisions 获取文章的所有历史版本，新版本在前
+func GetRevisions(articleID uint64) ([]Revision, error) {
+	var revisions []Revision
+	if err := model.DB.Where("article_id = ?", articleID).
+		Preload("User").
+		Order("id desc").
+		Find(&revisions).Error; err != nil {
+		return revisions, err
+	}
+	return revisions, nil
+}
+
+// GetRevision 获取文章的指定历史版本
+func GetRevision(articleID uint64, id uint64) (Revision, error) {
+	var revision Revision
+	if err := model.DB.Where("article_id = ?", articleID).Preload("User").First(&revision, id).Error; err != nil {
+		return revision, err
+	}
+	return revision, nil
+}
+
+// createRevision 为文章写入一个历史版本，标题和正文与最新版本相同时跳过
+func (article *Article) createRevision(tx *gorm.DB) error {
+	var latest Revision
+	err := tx.Where("article_id = ?", article.ID).Order("id desc").Limit(1).Find(&latest).Error
+	if err != nil {
+		return err
+	}
+	if latest.ID > 0 && latest.Title == article.Title && latest.Body == article.Body {
+		return nil
+	}
+
+	editorID := article.editorID
+	if editorID == 0 {
+		editorID = article.UserID
+	}
+	return tx.Create(&Revision{
+		ArticleID: article.ID,
+		UserID:    editorID,
+		Title:     article.Title,
+		Body:      article.Body,
+	}).Error
+}
@@ -0,0 +1,108 @@
+package article
+
+import (
+	"goblog/app/models"
+	"goblog/pkg/model"
+	"goblog/pkg/slug"
+
+	"gorm.io/gorm"
+)
+
+// OldSlug 文章曾经使用过的 slug，标题修改后旧链接会 301 跳转到新链接
+type OldSlug struct {
+	models.BaseModel
+
+	ArticleID uint64 `gorm:"not null;index"`
+	Slug      string `gorm:"type:varchar(128);not null;uniqueIndex"`
+}
+
+// TableName 指定表名为 article_slugs
+func (OldSlug) TableName() string {
+	return "article_slugs"
+}
+
+// GetBySlug 通过 slug 获取文章
+func GetBySlug(s string) (Article, error) {
+	var article Article
+	if err := model.DB.Preload("User").Where("slug = ?", s).First(&article).Error; err != nil {
+		return article, err
+	}
+
+	return article, nil
+}
+
+// GetByOldSlug 通过曾经使用过的 slug 获取文章
+func GetByOldSlug(s string) (Article, error) {
+	var old OldSlug
+	if err := model.DB.Where("slug = ?", s).First(&old).Error; err != nil {
+		return Article{}, err
+	}
+
+	var article Article
+	if err := model.DB.Preload("User").First(&article, old.ArticleID).Error; err != nil {
+		return article, err
+	}
+
+	return article, nil
+}
+
+// BackfillSlugs 为没有 slug 的文章生成 slug，用于数据迁移
+func BackfillSlugs() error {
+	var articles []Article
+	if err := model.DB.Where("slug = ? OR slug IS NULL", "").Find(&articles).Error; err != nil {
+		return err
+	}
+
+	for _, article := range articles {
+		if err := article.generateSlug(model.DB); err != nil {
+			return err
+		}
+		if err := model.DB.Model(&article).UpdateColumn("slug", article.Slug).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateSlug 根据标题生成唯一的 slug，标题变化导致 slug 变化时记录旧 slug
+func (article *Article) generateSlug(tx *gorm.DB) error {
+	base := slug.Safe(slug.Make(article.Title), "article", "create")
+
+	// 1. 标题未变化（或仅追加了去重后缀）时保留原 slug
+	if len(article.Slug) > 0 && slug.Matches(article.Slug, base) {
+		return nil
+	}
+
+	// 2. 生成唯一的新 slug
+	newSlug, err := uniqueSlug(tx, base, article.ID)
+	if err != nil {
+		return err
+	}
+
+	// 3. 记录旧 slug，并删除与新 slug 相同的旧记录（标题被改回原来的情况）
+	if article.ID > 0 && len(article.Slug) > 0 {
+		if err := tx.Create(&OldSlug{ArticleID: article.ID, Slug: article.Slug}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("slug = ?", newSlug).Delete(&OldSlug{}).Error; err != nil {
+		return err
+	}
+
+	article.Slug = newSlug
+	return nil
+}
+
+// uniqueSlug 查找未被其他文章（包括其旧 slug）占用的 slug，冲突时追加数字后缀
+func uniqueSlug(tx *gorm.DB, base string, id uint64) (string, error) {
+	return slug.Unique(base, func(candidate string) (bool, error) {
+		var count int64
+		if err := tx.Model(&Article{}).Where("slug = ? AND id <> ?", candidate, id).Count(&count).Error; err != nil || count > 0 {
+			return count > 0, err
+		}
+
+		err := tx.Model(&OldSlug{}).Where("slug = ? AND article_id <> ?", candidate, id).Count(&count).Error
+		return count > 0, err
+	})
+}
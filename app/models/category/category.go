@@ -9,9 +9,13 @@ type Category struct {
 	models.BaseModel
 
 	Name string `gorm:"type:varchar(255);not null;" valid:"name"`
+	Slug string `gorm:"type:varchar(128);uniqueIndex"`
 }
 
-// Link 方法用来生成文章链接
+// Link 方法用来生成分类链接，优先使用 slug 链接
 func (c Category) Link() string {
-	return route.Name2URL("categories.show", "id", c.GetStringID())
+	if len(c.Slug) == 0 {
+		return route.Name2URL("categories.show", "id", c.GetStringID())
+	}
+	return route.Name2URL("categories.slug", "slug", c.Slug)
 }
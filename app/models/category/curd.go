@@ -3,7 +3,9 @@ package category
 import (
 	"goblog/pkg/logger"
 	"goblog/pkg/model"
+	"goblog/pkg/pagination"
 	"goblog/pkg/types"
+	"net/http"
 )
 
 // Create 创建分类，通过 category.ID 来判断是否创建成功
@@ -16,6 +18,17 @@ func (category *Category) Create() (err error) {
 	return nil
 }
 
+// Update 更新分类，名称修改后重新生成 slug
+func (category *Category) Update() (rowsAffected int64, err error) {
+	result := model.DB.Save(&category)
+	if err = result.Error; err != nil {
+		logger.LogError(err)
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
+
 // All 获取分类数据
 func All() ([]Category, error) {
 	var categories []Category
@@ -35,3 +48,59 @@ func Get(idstr string) (Category, error) {
 
 	return category, nil
 }
+
+// GetByIDs 通过 ID 批量获取分类
+func GetByIDs(ids []uint64) ([]Category, error) {
+	var categories []Category
+	err := model.DB.Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+// GetByCursor 按 ID 顺序取 ID 大于 afterID 的 limit 个分类，用于游标分页
+func GetByCursor(afterID uint64, limit int) ([]Category, error) {
+	var categories []Category
+	err := model.DB.Where("id > ?", afterID).Order("id").Limit(limit).Find(&categories).Error
+	return categories, err
+}
+
+// AdminSortable 后台分类列表允许排序的字段
+var AdminSortable = []string{"id", "name", "created_at"}
+
+// GetForAdmin 后台分类列表，支持按名称搜索
+func GetForAdmin(r *http.Request, q pagination.Query, perPage int) ([]Category, pagination.ViewData, error) {
+
+	// 1. 构建查询条件
+	db := model.DB.Model(Category{}).Order(q.OrderBy())
+	if len(q.Keyword) > 0 {
+		db = db.Where("name LIKE ?", "%"+q.Keyword+"%")
+	}
+
+	// 2. 初始化分页实例
+	_pager := pagination.New(r, db, q.URL(), perPage)
+
+	// 3. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 4. 获取数据
+	var categories []Category
+	err := _pager.Results(&categories)
+
+	return categories, viewData, err
+}
+
+// Count 分类总数
+func Count() (count int64, err error) {
+	err = model.DB.Model(Category{}).Count(&count).Error
+	return
+}
+
+// Delete 删除分类
+func (category *Category) Delete() (rowsAffected int64, err error) {
+	result := model.DB.Delete(category)
+	if err = result.Error; err != nil {
+		logger.LogError(err)
+		return 0, err
+	}
+
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,8 @@
+package category
+
+import "gorm.io/gorm"
+
+// BeforeSave GORM 的模型钩子，在创建和更新模型前调用，生成 slug
+func (category *Category) BeforeSave(tx *gorm.DB) (err error) {
+	return category.generateSlug(tx)
+}
@@ -0,0 +1,108 @@
+package category
+
+import (
+	"goblog/app/models"
+	"goblog/pkg/model"
+	"goblog/pkg/slug"
+
+	"gorm.io/gorm"
+)
+
+// OldSlug 分类曾经使用过的 slug，名称修改后旧链接会 301 跳转到新链接
+type OldSlug struct {
+	models.BaseModel
+
+	CategoryID uint64 `gorm:"not null;index"`
+	Slug       string `gorm:"type:varchar(128);not null;uniqueIndex"`
+}
+
+// TableName 指定表名为 category_slugs
+func (OldSlug) TableName() string {
+	return "category_slugs"
+}
+
+// GetBySlug 通过 slug 获取分类
+func GetBySlug(s string) (Category, error) {
+	var category Category
+	if err := model.DB.Where("slug = ?", s).First(&category).Error; err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+// GetByOldSlug 通过曾经使用过的 slug 获取分类
+func GetByOldSlug(s string) (Category, error) {
+	var old OldSlug
+	if err := model.DB.Where("slug = ?", s).First(&old).Error; err != nil {
+		return Category{}, err
+	}
+
+	var category Category
+	if err := model.DB.First(&category, old.CategoryID).Error; err != nil {
+		return category, err
+	}
+
+	return category, nil
+}
+
+// BackfillSlugs 为没有 slug 的分类生成 slug，用于数据迁移
+func BackfillSlugs() error {
+	var categories []Category
+	if err := model.DB.Where("slug = ? OR slug IS NULL", "").Find(&categories).Error; err != nil {
+		return err
+	}
+
+	for _, category := range categories {
+		if err := category.generateSlug(model.DB); err != nil {
+			return err
+		}
+		if err := model.DB.Model(&category).UpdateColumn("slug", category.Slug).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// generateSlug 根据分类名称生成唯一的 slug，名称变化导致 slug 变化时记录旧 slug
+func (category *Category) generateSlug(tx *gorm.DB) error {
+	base := slug.Safe(slug.Make(category.Name), "category", "create")
+
+	// 1. 名称未变化（或仅追加了去重后缀）时保留原 slug
+	if len(category.Slug) > 0 && slug.Matches(category.Slug, base) {
+		return nil
+	}
+
+	// 2. 生成唯一的新 slug
+	newSlug, err := uniqueSlug(tx, base, category.ID)
+	if err != nil {
+		return err
+	}
+
+	// 3. 记录旧 slug，并删除与新 slug 相同的旧记录（名称被改回原来的情况）
+	if category.ID > 0 && len(category.Slug) > 0 {
+		if err := tx.Create(&OldSlug{CategoryID: category.ID, Slug: category.Slug}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("slug = ?", newSlug).Delete(&OldSlug{}).Error; err != nil {
+		return err
+	}
+
+	category.Slug = newSlug
+	return nil
+}
+
+// uniqueSlug 查找未被其他分类（包括其旧 slug）占用的 slug，冲突时追加数字后缀
+func uniqueSlug(tx *gorm.DB, base string, id uint64) (string, error) {
+	return slug.Unique(base, func(candidate string) (bool, error) {
+		var count int64
+		if err := tx.Model(&Category{}).Where("slug = ? AND id <> ?", candidate, id).Count(&count).Error; err != nil || count > 0 {
+			return count > 0, err
+		}
+
+		err := tx.Model(&OldSlug{}).Where("slug = ? AND category_id <> ?", candidate, id).Count(&count).Error
+		return count > 0, err
+	})
+}
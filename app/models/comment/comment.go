@@ -0,0 +1,144 @@
+package comment
+
+import (
+	"goblog/app/models"
+	"goblog/app/models/article"
+	"goblog/app/models/user"
+	"goblog/pkg/antispam"
+	"html/template"
+)
+
+// 评论状态
+const (
+	// StatusPending 待审核
+	StatusPending = "pending"
+	// StatusApproved 已通过，公开显示
+	StatusApproved = "approved"
+	// StatusRejected 已拒绝
+	StatusRejected = "rejected"
+	// StatusSpam 垃圾评论
+	StatusSpam = "spam"
+)
+
+// statusNames 状态对应的显示名称
+var statusNames = map[string]string{
+	StatusPending:  "待审核",
+	StatusApproved: "已通过",
+	StatusRejected: "已拒绝",
+	StatusSpam:     "垃圾评论",
+}
+
+// Comment 文章评论，ParentID 不为空时为对另一条评论的回复
+type Comment struct {
+	models.BaseModel
+
+	ArticleID uint64  `gorm:"not null;index"`
+	ParentID  *uint64 `gorm:"index"`
+
+	// 登录用户的评论记录 UserID，游客评论记录名称和邮箱
+	UserID     *uint64 `gorm:"index"`
+	GuestName  string  `gorm:"type:varchar(64)" valid:"guest_name"`
+	GuestEmail string  `gorm:"type:varchar(255)" valid:"guest_email"`
+
+	// IP 发表评论时的客户端 IP，用于垃圾评论识别
+	IP string `gorm:"type:varchar(45)"`
+
+	Body     string `gorm:"type:text;not null" valid:"body"`
+	BodyHTML string `gorm:"type:text"`
+	Status   string `gorm:"type:varchar(20);not null;default:pending;index"`
+
+	// TrainedAs 作为训练样本时标记的分类，未用于训练时为空，避免重复训练
+	TrainedAs antispam.Class `gorm:"type:varchar(10);not null;default:''"`
+
+	// 关联模型需放在验证字段之后：govalidator 展开嵌套结构体时同名字段先到先得，
+	// 放在前面会使 Article.Body 覆盖评论的 Body
+	Article article.Article `gorm:"constraint:OnDelete:CASCADE;"`
+	User    *user.User
+
+	// Children 回复列表，仅用于渲染评论树
+	Children []*Comment `gorm:"-"`
+}
+
+// AuthorName 评论者名称
+func (c Comment) AuthorName() string {
+	if c.User != nil {
+		return c.User.Name
+	}
+	return c.GuestName
+}
+
+// AuthorLink 评论者链接，游客没有链接
+func (c Comment) AuthorLink() string {
+	if c.User != nil {
+		return c.User.Link()
+	}
+	return ""
+}
+
+// RenderedBody 渲染并净化后的评论 HTML
+func (c Comment) RenderedBody() template.HTML {
+	return template.HTML(c.BodyHTML)
+}
+
+// CreatedAtTime 评论时间
+func (c Comment) CreatedAtTime() string {
+	return c.CreatedAt.Format("2006-01-02 15:04")
+}
+
+// StatusName 状态的显示名称
+func (c Comment) StatusName() string {
+	return statusNames[c.Status]
+}
+
+// IsPending 是否待审核
+func (c Comment) IsPending() bool {
+	return c.Status == StatusPending
+}
+
+// IsApproved 是否已通过
+func (c Comment) IsApproved() bool {
+	return c.Status == StatusApproved
+}
+
+// IsSpam 是否为垃圾评论
+func (c Comment) IsSpam() bool {
+	return c.Status == StatusSpam
+}
+
+// SpamSample 用于垃圾评论识别和训练的样本
+func (c Comment) SpamSample() antispam.Sample {
+	sample := antispam.Sample{
+		Content: c.Body,
+		Name:    c.GuestName,
+		Email:   c.GuestEmail,
+		IP:      c.IP,
+	}
+	if c.User != nil {
+		sample.Name = c.User.Name
+		sample.Email = c.User.Email
+	}
+	return sample
+}
+
+// Tree 将评论列表组装为评论树，返回顶层评论；父评论不在列表中的回复作为顶层评论显示
+func Tree(comments []Comment) []*Comment {
+	nodes := make(map[uint64]*Comment, len(comments))
+	for i := range comments {
+		comments[i].Children = nil
+		nodes[comments[i].ID] = &comments[i]
+	}
+
+	var roots []*Comment
+	for i := range comments {
+		c := &comments[i]
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+
+	return roots
+}
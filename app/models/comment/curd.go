@@ -0,0 +1,151 @@
+package comment
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/user"
+	"goblog/pkg/antispam"
+	"goblog/pkg/model"
+	"goblog/pkg/pagination"
+	"goblog/pkg/route"
+	"goblog/pkg/types"
+	"net/http"
+
+	"gorm.io/gorm"
+)
+
+// Get 通过 ID 获取评论
+func Get(idstr string) (Comment, error) {
+	var comment Comment
+	id := types.StringToUint64(idstr)
+	if err := model.DB.Preload("Article").Preload("User").First(&comment, id).Error; err != nil {
+		return comment, err
+	}
+
+	return comment, nil
+}
+
+// GetApprovedTree 获取文章已通过的评论，组装为评论树
+func GetApprovedTree(articleID uint64) ([]*Comment, error) {
+	var comments []Comment
+	if err := model.DB.Where("article_id = ? AND status = ?", articleID, StatusApproved).
+		Preload("User").
+		Order("id").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+
+	return Tree(comments), nil
+}
+
+// GetForModeration 获取用户所写文章下指定状态的评论，all 为 true 时获取所有文章的评论，用于审核页面
+func GetForModeration(uid uint64, all bool, status string, r *http.Request, perPage int) ([]Comment, pagination.ViewData, error) {
+
+	// 1. 初始化分页实例
+	db := model.DB.Model(Comment{}).Where("status = ?", status).Order("id desc")
+	if !all {
+		articleIDs := model.DB.Model(&article.Article{}).Select("id").Where("user_id = ?", uid)
+		db = db.Where("article_id IN (?)", articleIDs)
+	}
+	_pager := pagination.New(r, db, route.Name2URL("comments.index")+"?status="+status, perPage)
+
+	// 2. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 3. 获取数据
+	var comments []Comment
+	_pager.Results(&comments)
+
+	return comments, viewData, nil
+}
+
+// Create 创建评论，通过 comment.ID 来判断是否创建成功
+func (c *Comment) Create() (err error) {
+	if err = model.DB.Create(c).Error; err != nil {
+		return err
+	}
+
+	return refreshCount(c.ArticleID)
+}
+
+// SetStatus 修改评论状态
+func (c *Comment) SetStatus(status string) (err error) {
+	if err = model.DB.Model(c).UpdateColumn("status", status).Error; err != nil {
+		return err
+	}
+	c.Status = status
+
+	return refreshCount(c.ArticleID)
+}
+
+// Delete 删除评论及其所有回复
+func (c *Comment) Delete() (rowsAffected int64, err error) {
+	err = model.DB.Transaction(func(tx *gorm.DB) error {
+
+		// 1. 逐层查找所有回复
+		ids := []uint64{c.ID}
+		for parents := ids; len(parents) > 0; {
+			var children []uint64
+			if err := tx.Model(&Comment{}).Where("parent_id IN ?", parents).Pluck("id", &children).Error; err != nil {
+				return err
+			}
+			ids = append(ids, children...)
+			parents = children
+		}
+
+		// 2. 删除
+		result := tx.Delete(&Comment{}, ids)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, refreshCount(c.ArticleID)
+}
+
+// Train 将评论作为 class 类样本训练过滤器，只在分类变化时训练，之前按另一分类训练过的先撤销
+func (c *Comment) Train(f *antispam.Filter, class antispam.Class) error {
+	if c.TrainedAs == class {
+		return nil
+	}
+
+	sample := c.SpamSample()
+	if len(c.TrainedAs) > 0 {
+		if err := f.Untrain(c.TrainedAs, sample); err != nil {
+			return err
+		}
+	}
+	if err := f.Train(class, sample); err != nil {
+		return err
+	}
+
+	if err := model.DB.Model(c).UpdateColumn("trained_as", class).Error; err != nil {
+		return err
+	}
+	c.TrainedAs = class
+	return nil
+}
+
+// refreshCount 重新统计文章已通过的评论数量
+func refreshCount(articleID uint64) error {
+	return model.DB.Model(&article.Article{}).Where("id = ?", articleID).
+		UpdateColumn("comment_count", model.DB.Model(&Comment{}).
+			Select("COUNT(*)").
+			Where("article_id = ? AND status = ?", articleID, StatusApproved)).Error
+}
+
+// CountByStatus 指定状态的评论数量
+func CountByStatus(status string) (count int64, err error) {
+	err = model.DB.Model(Comment{}).Where("status = ?", status).Count(&count).Error
+	return
+}
+
+// DetachUser 将用户的评论转为游客评论，在删除用户前调用，以保留评论内容
+func DetachUser(_user user.User) error {
+	return model.DB.Model(Comment{}).Where("user_id = ?", _user.ID).UpdateColumns(map[string]interface{}{
+		"user_id":     nil,
+		"guest_name":  _user.Name,
+		"guest_email": _user.Email,
+	}).Error
+}
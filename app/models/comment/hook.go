@@ -0,0 +1,13 @@
+package comment
+
+import (
+	"goblog/pkg/markdown"
+
+	"gorm.io/gorm"
+)
+
+// BeforeSave GORM 的模型钩子，在创建和更新模型前调用，渲染并净化 Markdown 内容
+func (c *Comment) BeforeSave(tx *gorm.DB) (err error) {
+	c.BodyHTML = markdown.Render(c.Body)
+	return
+}
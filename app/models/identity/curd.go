@@ -0,0 +1,152 @@
+package identity
+
+import (
+	"errors"
+	"goblog/app/models/user"
+	"goblog/pkg/model"
+	"goblog/pkg/oidc"
+	"goblog/pkg/token"
+	"goblog/pkg/types"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrEmailRequired 提供方没有返回邮箱，无法创建账号
+	ErrEmailRequired = errors.New("无法获取该账号的邮箱，请在身份提供方公开邮箱后重试")
+	// ErrEmailNotVerified 邮箱已注册，但邮箱未经双方验证，不能自动关联
+	ErrEmailNotVerified = errors.New("该邮箱已注册，请使用密码登录后在账号设置中关联")
+	// ErrLinkedToOtherUser 该外部账号已关联其他用户
+	ErrLinkedToOtherUser = errors.New("该账号已关联其他用户")
+	// ErrProviderLinked 当前用户已关联该提供方的其他账号
+	ErrProviderLinked = errors.New("已关联该身份提供方的其他账号，请先取消关联")
+)
+
+// nameInvalidChars 用户名只允许字母和数字，与注册表单一致
+var nameInvalidChars = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// Resolve 获取外部账号对应的用户：已关联的直接返回；邮箱已注册时，只有双方都验证过邮箱才自动关联；否则创建新用户
+func Resolve(provider string, claims oidc.Claims) (user.User, error) {
+	// 1. 已关联；关联的用户已被删除时清理该记录，按未关联处理
+	var _identity Identity
+	err := model.DB.Where("provider = ? AND subject = ?", provider, claims.Subject).First(&_identity).Error
+	if err == nil {
+		_user, err := user.Get(types.Uint64ToString(_identity.UserID))
+		if err != gorm.ErrRecordNotFound {
+			return _user, err
+		}
+		if err := model.DB.Delete(&_identity).Error; err != nil {
+			return user.User{}, err
+		}
+	} else if err != gorm.ErrRecordNotFound {
+		return user.User{}, err
+	}
+
+	if len(claims.Email) == 0 {
+		return user.User{}, ErrEmailRequired
+	}
+
+	// 2. 邮箱已注册，防止他人抢先用该邮箱注册后劫持外部登录，要求本站邮箱也已验证
+	_user, err := user.GetByEmail(claims.Email)
+	if err == nil {
+		if !claims.EmailVerified || !_user.IsVerified() {
+			return user.User{}, ErrEmailNotVerified
+		}
+		_, err = Link(_user.ID, provider, claims)
+		return _user, err
+	} else if err != gorm.ErrRecordNotFound {
+		return user.User{}, err
+	}
+
+	// 3. 创建新用户，密码随机生成，需要时可通过找回密码设置
+	err = model.DB.Transaction(func(tx *gorm.DB) error {
+		_user = user.User{
+			Name:     uniqueName(tx, claims),
+			Email:    claims.Email,
+			Password: token.Generate(),
+		}
+		if claims.EmailVerified {
+			now := time.Now()
+			_user.EmailVerifiedAt = &now
+		}
+		if err := tx.Create(&_user).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Identity{
+			UserID:   _user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}).Error
+	})
+	return _user, err
+}
+
+// Link 将外部账号关联到用户，每个提供方只能关联一个账号
+func Link(userID uint64, provider string, claims oidc.Claims) (Identity, error) {
+	var existing Identity
+	err := model.DB.Where("provider = ? AND (subject = ? OR user_id = ?)", provider, claims.Subject, userID).First(&existing).Error
+	if err == nil {
+		switch {
+		case existing.UserID != userID:
+			return existing, ErrLinkedToOtherUser
+		case existing.Subject != claims.Subject:
+			return existing, ErrProviderLinked
+		default:
+			return existing, nil
+		}
+	} else if err != gorm.ErrRecordNotFound {
+		return existing, err
+	}
+
+	_identity := Identity{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	return _identity, model.DB.Create(&_identity).Error
+}
+
+// GetByUser 用户已关联的外部账号
+func GetByUser(userID uint64) ([]Identity, error) {
+	var identities []Identity
+	err := model.DB.Where("user_id = ?", userID).Order("id").Find(&identities).Error
+	return identities, err
+}
+
+// Unlink 取消关联，返回删除的数量，不是该用户的关联时为 0
+func Unlink(userID, id uint64) (int64, error) {
+	result := model.DB.Where("user_id = ? AND id = ?", userID, id).Delete(&Identity{})
+	return result.RowsAffected, result.Error
+}
+
+// uniqueName 根据外部账号生成符合注册规则（3-20 位字母和数字）且未被使用的用户名
+func uniqueName(tx *gorm.DB, claims oidc.Claims) string {
+	base := ""
+	for _, candidate := range []string{claims.PreferredUsername, claims.Name, strings.SplitN(claims.Email, "@", 2)[0]} {
+		if base = nameInvalidChars.ReplaceAllString(candidate, ""); len(base) >= 3 {
+			break
+		}
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 14 {
+		base = base[:14]
+	}
+
+	name := base
+	for i := 0; i < 10; i++ {
+		var count int64
+		tx.Model(&user.User{}).Where("name = ?", name).Count(&count)
+		if count == 0 {
+			return name
+		}
+		name = base + token.Generate()[:6]
+	}
+	return name
+}
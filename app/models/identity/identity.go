@@ -0,0 +1,35 @@
+package identity
+
+import (
+	"goblog/pkg/oidc"
+	"time"
+)
+
+// Identity 用户在外部身份提供方的账号，同一提供方的同一账号只能关联一个用户
+type Identity struct {
+	ID       uint64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	UserID   uint64 `gorm:"not null;index"`
+	Provider string `gorm:"type:varchar(50);not null;uniqueIndex:idx_provider_subject"`
+	Subject  string `gorm:"type:varchar(191);not null;uniqueIndex:idx_provider_subject"`
+	// Email 关联时提供方返回的邮箱，仅用于显示
+	Email     string `gorm:"type:varchar(255);not null;default:''"`
+	CreatedAt time.Time
+}
+
+// TableName 表名
+func (Identity) TableName() string {
+	return "user_identities"
+}
+
+// Label 身份提供方的显示名称，提供方已停用时显示标识
+func (i Identity) Label() string {
+	if p, ok := oidc.Get(i.Provider); ok {
+		return p.Label
+	}
+	return i.Provider
+}
+
+// CreatedAtDate 关联日期
+func (i Identity) CreatedAtDate() string {
+	return i.CreatedAt.Format("2006-01-02")
+}
@@ -0,0 +1,23 @@
+package loginattempt
+
+import (
+	"goblog/pkg/model"
+)
+
+// Create 写入一条审计记录，User-Agent 过长时截断
+func (a *LoginAttempt) Create() error {
+	if len(a.UserAgent) > 255 {
+		a.UserAgent = a.UserAgent[:255]
+	}
+	if len(a.Email) > 255 {
+		a.Email = a.Email[:255]
+	}
+	return model.DB.Create(a).Error
+}
+
+// Recent 最近的登录失败记录
+func Recent(limit int) ([]LoginAttempt, error) {
+	var attempts []LoginAttempt
+	err := model.DB.Order("id desc").Limit(limit).Find(&attempts).Error
+	return attempts, err
+}
@@ -0,0 +1,41 @@
+package loginattempt
+
+import (
+	"time"
+)
+
+// 登录失败的原因
+const (
+	ReasonPassword  = "password"
+	ReasonTwoFactor = "two_factor"
+	ReasonLocked    = "locked"
+)
+
+// LoginAttempt 登录失败的审计记录
+type LoginAttempt struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Email     string    `gorm:"type:varchar(255);not null;index"`
+	UserID    uint64    `gorm:"not null;default:0;index"`
+	IP        string    `gorm:"type:varchar(45);not null;default:'';index"`
+	UserAgent string    `gorm:"type:varchar(255);not null;default:''"`
+	Reason    string    `gorm:"type:varchar(20);not null"`
+	CreatedAt time.Time `gorm:"index"`
+}
+
+// ReasonText 失败原因的中文说明
+func (a LoginAttempt) ReasonText() string {
+	switch a.Reason {
+	case ReasonPassword:
+		return "密码错误"
+	case ReasonTwoFactor:
+		return "验证码错误"
+	case ReasonLocked:
+		return "锁定期间尝试"
+	}
+	return a.Reason
+}
+
+// CreatedAtTime 失败时间
+func (a LoginAttempt) CreatedAtTime() string {
+	return a.CreatedAt.Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,76 @@
+package loginattempt
+
+import (
+	"goblog/pkg/model"
+	"goblog/pkg/throttle"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Throttle 登录限流的失败记录，重启后仍然有效
+type Throttle struct {
+	ID          uint64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Key         string `gorm:"type:varchar(191);not null;uniqueIndex"`
+	Failures    int    `gorm:"not null;default:0"`
+	LastFailure time.Time
+	LockedUntil *time.Time `gorm:"index"`
+}
+
+// TableName 表名
+func (Throttle) TableName() string {
+	return "login_throttles"
+}
+
+// Store 基于数据库的失败记录存储，实现 throttle.Store 接口
+type Store struct{}
+
+// Get 读取记录，不存在时返回零值
+func (Store) Get(key string) (throttle.Record, error) {
+	var row Throttle
+	if err := model.DB.Where("`key` = ?", key).First(&row).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return throttle.Record{}, nil
+		}
+		return throttle.Record{}, err
+	}
+
+	record := throttle.Record{
+		Failures:    row.Failures,
+		LastFailure: row.LastFailure,
+	}
+	if row.LockedUntil != nil {
+		record.LockedUntil = *row.LockedUntil
+	}
+	return record, nil
+}
+
+// Put 保存记录
+func (Store) Put(key string, record throttle.Record) error {
+	row := Throttle{
+		Key:         key,
+		Failures:    record.Failures,
+		LastFailure: record.LastFailure,
+	}
+	if !record.LockedUntil.IsZero() {
+		row.LockedUntil = &record.LockedUntil
+	}
+	return model.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"failures", "last_failure", "locked_until"}),
+	}).Create(&row).Error
+}
+
+// Delete 删除记录
+func (Store) Delete(key string) error {
+	return model.DB.Where("`key` = ?", key).Delete(&Throttle{}).Error
+}
+
+// Prune 删除最后一次失败早于指定时间且已解锁的记录，这些记录已不再影响限流
+func Prune(before time.Time) error {
+	return model.DB.
+		Where("last_failure < ?", before).
+		Where("locked_until IS NULL OR locked_until < ?", time.Now()).
+		Delete(&Throttle{}).Error
+}
@@ -0,0 +1,169 @@
+package role
+
+import (
+	"goblog/pkg/model"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// cachedRole 缓存的角色权限设置
+type cachedRole struct {
+	permissions      map[string]bool
+	requireTwoFactor bool
+}
+
+// cache 角色 ID 对应的权限设置，权限检查在每次请求中多次发生，缓存后避免重复查询
+var (
+	cache   = map[uint64]*cachedRole{}
+	cacheMu sync.RWMutex
+)
+
+// GetByName 通过名称获取角色
+func GetByName(name string) (Role, error) {
+	var role Role
+	if err := model.DB.Preload("Permissions").Where("name = ?", name).First(&role).Error; err != nil {
+		return role, err
+	}
+
+	return role, nil
+}
+
+// All 获取所有角色
+func All() ([]Role, error) {
+	var roles []Role
+	if err := model.DB.Preload("Permissions").Order("id").Find(&roles).Error; err != nil {
+		return roles, err
+	}
+	return roles, nil
+}
+
+// HasPermission 角色是否拥有权限
+func HasPermission(roleID uint64, permission string) bool {
+	cached, ok := load(roleID)
+	return ok && cached.permissions[permission]
+}
+
+// RequiresTwoFactor 角色是否要求启用两步验证
+func RequiresTwoFactor(roleID uint64) bool {
+	cached, ok := load(roleID)
+	return ok && cached.requireTwoFactor
+}
+
+// load 从缓存中读取角色设置，未缓存时从数据库读取
+func load(roleID uint64) (*cachedRole, bool) {
+	if roleID == 0 {
+		return nil, false
+	}
+
+	cacheMu.RLock()
+	cached, ok := cache[roleID]
+	cacheMu.RUnlock()
+	if ok {
+		return cached, true
+	}
+
+	var role Role
+	if err := model.DB.Preload("Permissions").First(&role, roleID).Error; err != nil {
+		return nil, false
+	}
+
+	cached = &cachedRole{
+		permissions:      make(map[string]bool, len(role.Permissions)),
+		requireTwoFactor: role.RequireTwoFactor,
+	}
+	for _, p := range role.Permissions {
+		cached.permissions[p.Name] = true
+	}
+
+	cacheMu.Lock()
+	cache[roleID] = cached
+	cacheMu.Unlock()
+
+	return cached, true
+}
+
+// SetRequireTwoFactor 设置角色是否要求启用两步验证
+func (r *Role) SetRequireTwoFactor(required bool) error {
+	if err := model.DB.Model(r).UpdateColumn("require_two_factor", required).Error; err != nil {
+		return err
+	}
+	r.RequireTwoFactor = required
+
+	Flush()
+	return nil
+}
+
+// Get 通过 ID 获取角色
+func Get(id uint64) (Role, error) {
+	var role Role
+	if err := model.DB.Preload("Permissions").First(&role, id).Error; err != nil {
+		return role, err
+	}
+
+	return role, nil
+}
+
+// SyncPermissions 将角色的权限设置为 names
+func (r *Role) SyncPermissions(names []string) error {
+	var perms []Permission
+	if len(names) > 0 {
+		if err := model.DB.Where("name IN ?", names).Find(&perms).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := model.DB.Model(r).Association("Permissions").Replace(perms); err != nil {
+		return err
+	}
+	r.Permissions = perms
+
+	Flush()
+	return nil
+}
+
+// Flush 清空权限缓存，角色权限变化后调用
+func Flush() {
+	cacheMu.Lock()
+	cache = map[uint64]*cachedRole{}
+	cacheMu.Unlock()
+}
+
+// Seed 创建内置权限和角色：已存在的角色保留其权限设置，管理员每次都同步为全部权限
+func Seed() error {
+	var all []string
+	for _, p := range permissions {
+		p := p
+		if err := model.DB.Where(Permission{Name: p.Name}).Attrs(p).FirstOrCreate(&p).Error; err != nil {
+			return err
+		}
+		all = append(all, p.Name)
+	}
+
+	for _, item := range roles {
+		role, err := GetByName(item.Name)
+		switch {
+		case err == gorm.ErrRecordNotFound:
+			role = Role{Name: item.Name, DisplayName: item.DisplayName}
+			if err := model.DB.Create(&role).Error; err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if item.Name != Admin {
+				continue
+			}
+		}
+
+		names := item.Permissions
+		if item.Name == Admin {
+			names = all
+		}
+		if err := role.SyncPermissions(names); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
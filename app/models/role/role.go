@@ -0,0 +1,79 @@
+package role
+
+import (
+	"goblog/app/models"
+)
+
+// 内置角色
+const (
+	// Admin 管理员，拥有全部权限
+	Admin = "admin"
+	// Editor 编辑，可管理所有文章、分类、标签和评论
+	Editor = "editor"
+	// Author 作者，可发布文章并管理自己的文章和评论
+	Author = "author"
+	// Reader 读者，只能阅读和评论
+	Reader = "reader"
+)
+
+// Role 用户角色，通过 role_permissions 关联权限
+type Role struct {
+	models.BaseModel
+
+	Name        string `gorm:"type:varchar(32);not null;uniqueIndex"`
+	DisplayName string `gorm:"type:varchar(64);not null"`
+
+	// RequireTwoFactor 是否要求该角色的用户启用两步验证，未启用前无法使用需要权限的功能
+	RequireTwoFactor bool `gorm:"not null;default:false"`
+
+	Permissions []Permission `gorm:"many2many:role_permissions;"`
+}
+
+// Permission 权限，名称形如 article.update.own，.own 后缀表示仅限自己的资源，.any 表示所有资源
+type Permission struct {
+	models.BaseModel
+
+	Name        string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	Description string `gorm:"type:varchar(255)"`
+}
+
+// permissions 内置权限及其说明
+var permissions = []Permission{
+	{Name: "article.create", Description: "发布文章"},
+	{Name: "article.update.own", Description: "编辑自己的文章"},
+	{Name: "article.update.any", Description: "编辑所有文章"},
+	{Name: "article.delete.own", Description: "删除自己的文章"},
+	{Name: "article.delete.any", Description: "删除所有文章"},
+	{Name: "category.create", Description: "新建分类"},
+	{Name: "category.update", Description: "编辑分类"},
+	{Name: "tag.manage", Description: "重命名、合并标签"},
+	{Name: "comment.moderate.own", Description: "审核自己文章下的评论"},
+	{Name: "comment.moderate.any", Description: "审核所有评论"},
+	{Name: "admin.access", Description: "访问管理后台，管理用户、文章和分类"},
+}
+
+// roles 内置角色及其默认权限，管理员始终拥有全部权限
+var roles = []struct {
+	Name        string
+	DisplayName string
+	Permissions []string
+}{
+	{Admin, "管理员", nil},
+	{Editor, "编辑", []string{
+		"article.create", "article.update.own", "article.update.any", "article.delete.own", "article.delete.any",
+		"category.create", "category.update", "tag.manage", "comment.moderate.own", "comment.moderate.any",
+	}},
+	{Author, "作者", []string{
+		"article.create", "article.update.own", "article.delete.own", "comment.moderate.own",
+	}},
+	{Reader, "读者", []string{}},
+}
+
+// PermissionNames 角色拥有的权限名称
+func (r Role) PermissionNames() []string {
+	names := make([]string, 0, len(r.Permissions))
+	for _, p := range r.Permissions {
+		names = append(names, p.Name)
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package spamtoken
+
+import (
+	"goblog/pkg/antispam"
+	"goblog/pkg/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// documentsToken 记录两类样本数量的特殊行，分词结果中不会出现下划线
+const documentsToken = "__documents__"
+
+// SpamToken 垃圾内容分类器的训练数据，每行为一个特征在两类样本中出现的次数
+type SpamToken struct {
+	ID    uint64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	Token string `gorm:"type:varchar(191);not null;uniqueIndex"`
+	Spam  uint64 `gorm:"not null;default:0"`
+	Ham   uint64 `gorm:"not null;default:0"`
+}
+
+// Store 基于数据库的训练数据存储，实现 antispam.Store 接口
+type Store struct{}
+
+// Load 读取全部训练数据
+func (Store) Load() (docs antispam.Counts, features map[string]antispam.Counts, err error) {
+	var tokens []SpamToken
+	if err = model.DB.Find(&tokens).Error; err != nil {
+		return
+	}
+
+	features = make(map[string]antispam.Counts, len(tokens))
+	for _, t := range tokens {
+		counts := antispam.Counts{Spam: t.Spam, Ham: t.Ham}
+		if t.Token == documentsToken {
+			docs = counts
+		} else {
+			features[t.Token] = counts
+		}
+	}
+	return
+}
+
+// Learn 样本数及各特征在该分类下的次数加一，在同一事务中完成
+func (Store) Learn(class antispam.Class, features []string) error {
+	column := columnFor(class)
+
+	rows := make([]SpamToken, 0, len(features)+1)
+	for _, token := range append([]string{documentsToken}, features...) {
+		row := SpamToken{Token: token}
+		if class == antispam.Spam {
+			row.Spam = 1
+		} else {
+			row.Ham = 1
+		}
+		rows = append(rows, row)
+	}
+
+	return model.DB.Transaction(func(tx *gorm.DB) error {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "token"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{column: gorm.Expr(column + " + 1")}),
+		}).CreateInBatches(rows, 200).Error
+	})
+}
+
+// Unlearn 样本数及各特征在该分类下的次数减一，已为 0 的不再减少
+func (Store) Unlearn(class antispam.Class, features []string) error {
+	column := columnFor(class)
+	tokens := append([]string{documentsToken}, features...)
+
+	return model.DB.Model(&SpamToken{}).
+		Where("token IN ? AND "+column+" > 0", tokens).
+		UpdateColumn(column, gorm.Expr(column+" - 1")).Error
+}
+
+// columnFor 分类对应的计数字段
+func columnFor(class antispam.Class) string {
+	if class == antispam.Spam {
+		return "spam"
+	}
+	return "ham"
+}
@@ -0,0 +1,121 @@
+package tag
+
+import (
+	"errors"
+	"goblog/pkg/model"
+	"goblog/pkg/slug"
+	"goblog/pkg/types"
+
+	"gorm.io/gorm"
+)
+
+// ErrNameTaken 重命名时名称已被其他标签使用
+var ErrNameTaken = errors.New("标签名称已存在，请使用合并功能")
+
+// Get 通过 ID 获取标签
+func Get(idstr string) (Tag, error) {
+	var tag Tag
+	id := types.StringToUint64(idstr)
+	if err := model.DB.First(&tag, id).Error; err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// GetBySlug 通过 slug 获取标签
+func GetBySlug(s string) (Tag, error) {
+	var tag Tag
+	if err := model.DB.Where("slug = ?", s).First(&tag).Error; err != nil {
+		return tag, err
+	}
+
+	return tag, nil
+}
+
+// All 获取所有标签，按名称排序
+func All() ([]Tag, error) {
+	var tags []Tag
+	if err := model.DB.Order("name").Find(&tags).Error; err != nil {
+		return tags, err
+	}
+	return tags, nil
+}
+
+// FirstOrCreate 在事务 tx 中根据名称获取标签，不存在的标签会被创建，返回顺序与 names 一致
+func FirstOrCreate(tx *gorm.DB, names []string) ([]Tag, error) {
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tag := Tag{Name: name}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&tag).Error; err != nil {
+			return tags, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, nil
+}
+
+// Rename 重命名标签，slug 会随之更新
+func (tag *Tag) Rename(name string) error {
+	var count int64
+	if err := model.DB.Model(&Tag{}).Where("name = ? AND id <> ?", name, tag.ID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrNameTaken
+	}
+
+	tag.Name = name
+	if err := model.DB.Save(tag).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MergeInto 将标签合并到 target：文章关联改为 target，然后删除当前标签，在同一事务中完成
+func (tag *Tag) MergeInto(target Tag) error {
+	if tag.ID == target.ID {
+		return nil
+	}
+
+	return model.DB.Transaction(func(tx *gorm.DB) error {
+
+		// 1. 将关联复制到目标标签，跳过已同时拥有两个标签的文章
+		if err := tx.Exec("INSERT INTO article_tags (article_id, tag_id) "+
+			"SELECT article_id, ? FROM article_tags WHERE tag_id = ? "+
+			"AND article_id NOT IN (SELECT article_id FROM article_tags WHERE tag_id = ?)",
+			target.ID, tag.ID, target.ID).Error; err != nil {
+			return err
+		}
+
+		// 2. 删除原标签的关联
+		if err := tx.Exec("DELETE FROM article_tags WHERE tag_id = ?", tag.ID).Error; err != nil {
+			return err
+		}
+
+		// 3. 删除原标签
+		return tx.Delete(&Tag{}, tag.ID).Error
+	})
+}
+
+// generateSlug 根据标签名称生成唯一的 slug
+func (tag *Tag) generateSlug(tx *gorm.DB) error {
+	base := slug.Safe(slug.Make(tag.Name), "tag")
+	if len(tag.Slug) > 0 && slug.Matches(tag.Slug, base) {
+		return nil
+	}
+
+	newSlug, err := slug.Unique(base, func(candidate string) (bool, error) {
+		var count int64
+		err := tx.Model(&Tag{}).Where("slug = ? AND id <> ?", candidate, tag.ID).Count(&count).Error
+		return count > 0, err
+	})
+	if err != nil {
+		return err
+	}
+
+	tag.Slug = newSlug
+	return nil
+}
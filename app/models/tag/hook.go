@@ -0,0 +1,8 @@
+package tag
+
+import "gorm.io/gorm"
+
+// BeforeSave GORM 的模型钩子，在创建和更新模型前调用，生成 slug
+func (tag *Tag) BeforeSave(tx *gorm.DB) (err error) {
+	return tag.generateSlug(tx)
+}
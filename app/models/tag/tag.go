@@ -0,0 +1,98 @@
+package tag
+
+import (
+	"goblog/app/models"
+	"goblog/pkg/route"
+	"math"
+	"strings"
+)
+
+// Tag 文章标签，与文章为多对多关系，关联表为 article_tags
+type Tag struct {
+	models.BaseModel
+
+	Name string `gorm:"type:varchar(64);not null;uniqueIndex" valid:"name"`
+	Slug string `gorm:"type:varchar(128);uniqueIndex"`
+}
+
+// CloudItem 标签云中的一项
+type CloudItem struct {
+	Tag
+	// Count 标签下已发布的文章数量
+	Count int64
+	// Weight 权重，取值 1～5，用以设置字号
+	Weight int
+}
+
+// Link 方法用来生成标签链接
+func (t Tag) Link() string {
+	return route.Name2URL("tags.show", "slug", t.Slug)
+}
+
+// ParseNames 解析逗号分隔的标签输入，支持中英文逗号和顿号，去除空白和重复项（不区分大小写）
+func ParseNames(input string) []string {
+	fields := strings.FieldsFunc(input, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、'
+	})
+
+	var names []string
+	seen := map[string]bool{}
+	for _, f := range fields {
+		name := strings.Join(strings.Fields(f), " ")
+		key := strings.ToLower(name)
+		if len(name) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// FromNames 根据名称构建未保存的标签，用于表单回显和验证
+func FromNames(names []string) []Tag {
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{Name: name})
+	}
+	return tags
+}
+
+// Names 返回标签名称列表
+func Names(tags []Tag) []string {
+	names := make([]string, 0, len(tags))
+	for _, t := range tags {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// NewCloud 按文章数量的对数计算标签云中每项的权重
+func NewCloud(items []CloudItem) []CloudItem {
+	if len(items) == 0 {
+		return items
+	}
+
+	min, max := items[0].Count, items[0].Count
+	for _, item := range items {
+		if item.Count < min {
+			min = item.Count
+		}
+		if item.Count > max {
+			max = item.Count
+		}
+	}
+
+	for i, item := range items {
+		if max == min {
+			items[i].Weight = 3
+			continue
+		}
+		ratio := (math.Log(float64(item.Count)) - math.Log(float64(min))) /
+			(math.Log(float64(max)) - math.Log(float64(min)))
+		items[i].Weight = 1 + int(math.Round(ratio*4))
+	}
+
+	return items
+}
@@ -1,11 +1,25 @@
 package user
 
 import (
+	"errors"
+	"goblog/app/models/role"
 	"goblog/pkg/logger"
 	"goblog/pkg/model"
+	"goblog/pkg/pagination"
 	"goblog/pkg/types"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// ErrRoleNotFound 修改角色时指定的角色不存在
+var ErrRoleNotFound = errors.New("角色不存在")
+
+// ownedTables 以 user_id 关联到用户的数据表，删除用户时在同一事务中删除
+// 外部登录身份的模型依赖 user 包，为避免循环引用，这里统一使用表名
+var ownedTables = []string{"user_identities", "personal_access_tokens", "user_sessions", "remember_tokens"}
+
 // Create 创建文章，通过 article.ID 来判断是否创建成功
 func (user *User) Create() (err error) {
 	result := model.DB.Create(&user)
@@ -46,3 +60,174 @@ func All() ([]User, error) {
 	}
 	return users, nil
 }
+
+// AdminSortable 后台用户列表允许排序的字段
+var AdminSortable = []string{"id", "name", "email", "created_at"}
+
+// GetForAdmin 后台用户列表，支持按名称或邮箱搜索，按角色（role）和封禁状态（banned=1|0）筛选
+func GetForAdmin(r *http.Request, q pagination.Query, perPage int) ([]User, pagination.ViewData, error) {
+
+	// 1. 构建查询条件
+	db := model.DB.Model(User{}).Order(q.OrderBy())
+	if len(q.Keyword) > 0 {
+		like := "%" + q.Keyword + "%"
+		db = db.Where("name LIKE ? OR email LIKE ?", like, like)
+	}
+	if roleID := q.Get("role"); len(roleID) > 0 {
+		db = db.Where("role_id = ?", roleID)
+	}
+	switch q.Get("banned") {
+	case "1":
+		db = db.Where("banned_at IS NOT NULL")
+	case "0":
+		db = db.Where("banned_at IS NULL")
+	}
+
+	// 2. 初始化分页实例
+	_pager := pagination.New(r, db, q.URL(), perPage)
+
+	// 3. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 4. 获取数据
+	var users []User
+	err := _pager.Results(&users)
+
+	return users, viewData, err
+}
+
+// APISortable API 用户列表允许排序的字段
+var APISortable = []string{"id", "name", "created_at"}
+
+// GetForAPI API 用户列表，只支持按名称搜索，不暴露邮箱
+func GetForAPI(r *http.Request, q pagination.Query, perPage int) ([]User, pagination.ViewData, error) {
+
+	// 1. 构建查询条件
+	db := model.DB.Model(User{}).Order(q.OrderBy())
+	if len(q.Keyword) > 0 {
+		db = db.Where("name LIKE ?", "%"+q.Keyword+"%")
+	}
+
+	// 2. 初始化分页实例
+	_pager := pagination.New(r, db, q.URL(), perPage)
+
+	// 3. 获取视图数据
+	viewData := _pager.Paging()
+
+	// 4. 获取数据
+	var users []User
+	err := _pager.Results(&users)
+
+	return users, viewData, err
+}
+
+// GetByIDs 通过 ID 批量获取用户
+func GetByIDs(ids []uint64) ([]User, error) {
+	var users []User
+	err := model.DB.Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+// GetByCursor 按 ID 顺序取 ID 大于 afterID 的 limit 个用户，用于游标分页
+func GetByCursor(afterID uint64, limit int) ([]User, error) {
+	var users []User
+	err := model.DB.Where("id > ?", afterID).Order("id").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+// Count 用户总数
+func Count() (count int64, err error) {
+	err = model.DB.Model(User{}).Count(&count).Error
+	return
+}
+
+// Recent 最近注册的用户
+func Recent(limit int) ([]User, error) {
+	var users []User
+	err := model.DB.Order("id desc").Limit(limit).Find(&users).Error
+	return users, err
+}
+
+// SetBanned 批量封禁或解封用户
+func SetBanned(ids []uint64, banned bool) (rowsAffected int64, err error) {
+	var bannedAt *time.Time
+	if banned {
+		now := time.Now()
+		bannedAt = &now
+	}
+
+	result := model.DB.Model(User{}).Where("id IN ?", ids).UpdateColumn("banned_at", bannedAt)
+	return result.RowsAffected, result.Error
+}
+
+// SetRole 批量修改用户角色，角色不存在时返回 ErrRoleNotFound
+func SetRole(ids []uint64, roleID uint64) (rowsAffected int64, err error) {
+	if _, err := role.Get(roleID); err == gorm.ErrRecordNotFound {
+		return 0, ErrRoleNotFound
+	} else if err != nil {
+		return 0, err
+	}
+
+	result := model.DB.Model(User{}).Where("id IN ?", ids).UpdateColumn("role_id", roleID)
+	return result.RowsAffected, result.Error
+}
+
+// Delete 删除用户，同时删除其外部登录身份、API 令牌、登录设备和「记住我」令牌
+// 重置密码记录和恢复码由外键级联删除
+func (user *User) Delete() (rowsAffected int64, err error) {
+	err = model.DB.Transaction(func(tx *gorm.DB) error {
+		for _, table := range ownedTables {
+			if err := tx.Exec("DELETE FROM "+table+" WHERE user_id = ?", user.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		result := tx.Delete(user)
+		rowsAffected = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return rowsAffected, nil
+}
+
+// MarkEmailVerified 将邮箱标记为已验证
+func (user *User) MarkEmailVerified() error {
+	now := time.Now()
+	if err := model.DB.Model(user).UpdateColumn("email_verified_at", now).Error; err != nil {
+		return err
+	}
+	user.EmailVerifiedAt = &now
+	return nil
+}
+
+// TouchVerificationSent 记录发送验证邮件的时间
+func (user *User) TouchVerificationSent() error {
+	now := time.Now()
+	if err := model.DB.Model(user).UpdateColumn("verification_sent_at", now).Error; err != nil {
+		return err
+	}
+	user.VerificationSentAt = &now
+	return nil
+}
+
+// ChangeEmail 修改邮箱，新邮箱需要重新验证
+func (user *User) ChangeEmail(email string) error {
+	if err := model.DB.Model(user).UpdateColumns(map[string]interface{}{
+		"email":             email,
+		"email_verified_at": nil,
+	}).Error; err != nil {
+		return err
+	}
+	user.Email = email
+	user.EmailVerifiedAt = nil
+	return nil
+}
+
+// ChangePassword 修改密码，由 BeforeSave 钩子加密
+func (user *User) ChangePassword(newPassword string) error {
+	user.Password = newPassword
+	return model.DB.Model(user).Select("Password").Updates(user).Error
+}
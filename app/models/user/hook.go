@@ -6,6 +6,8 @@ GORM 模型钩子 是在创建、查询、更新、删除等操作之前、之
 如果任何回调返回错误，GORM 将停止后续的操作并回滚事务。
 */
 import (
+	"goblog/app/models/role"
+	"goblog/pkg/config"
 	"goblog/pkg/password"
 
 	"gorm.io/gorm"
@@ -34,3 +36,13 @@ func (u *User) BeforeSave(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// BeforeCreate GORM 的模型钩子，未指定角色的新用户使用默认角色
+func (u *User) BeforeCreate(tx *gorm.DB) (err error) {
+	if u.RoleID == 0 {
+		if _role, err := role.GetByName(config.GetString("auth.default_role")); err == nil {
+			u.RoleID = _role.ID
+		}
+	}
+	return
+}
@@ -0,0 +1,79 @@
+package user
+
+import (
+	"errors"
+	"goblog/pkg/model"
+	"goblog/pkg/token"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidResetToken 重置密码令牌不存在、已使用或已过期
+var ErrInvalidResetToken = errors.New("重置链接无效或已过期，请重新申请")
+
+// PasswordReset 重置密码令牌，只保存令牌的哈希值，使用一次后失效
+type PasswordReset struct {
+	ID        uint64    `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	UserID    uint64    `gorm:"not null;index"`
+	TokenHash string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// CreatePasswordReset 为用户生成重置密码令牌，之前未使用的令牌同时失效，返回令牌明文用于发送邮件
+func (user User) CreatePasswordReset(ttl time.Duration) (string, error) {
+	t := token.Generate()
+
+	err := model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&PasswordReset{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&PasswordReset{
+			UserID:    user.ID,
+			TokenHash: token.Hash(t),
+			ExpiresAt: time.Now().Add(ttl),
+		}).Error
+	})
+
+	return t, err
+}
+
+// GetByResetToken 通过重置密码令牌获取用户，令牌无效时返回 ErrInvalidResetToken
+func GetByResetToken(t string) (User, error) {
+	var reset PasswordReset
+	err := model.DB.Preload("User").
+		Where("token_hash = ? AND used_at IS NULL AND expires_at > ?", token.Hash(t), time.Now()).
+		First(&reset).Error
+
+	if err == gorm.ErrRecordNotFound {
+		return User{}, ErrInvalidResetToken
+	}
+	return reset.User, err
+}
+
+// ResetPassword 使用令牌重置密码：令牌标记为已使用，并使该用户所有已登录的会话失效
+func (user *User) ResetPassword(t string, newPassword string) error {
+	return model.DB.Transaction(func(tx *gorm.DB) error {
+
+		// 1. 令牌标记为已使用，同一令牌只能成功使用一次
+		result := tx.Model(&PasswordReset{}).
+			Where("user_id = ? AND token_hash = ? AND used_at IS NULL AND expires_at > ?", user.ID, token.Hash(t), time.Now()).
+			UpdateColumn("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		// 2. 更新密码，由 BeforeSave 钩子加密
+		user.Password = newPassword
+		user.SessionVersion++
+		return tx.Model(user).Select("Password", "SessionVersion").Updates(user).Error
+	})
+}
@@ -0,0 +1,208 @@
+package user
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"goblog/app/models/role"
+	"goblog/pkg/config"
+	"goblog/pkg/encrypt"
+	"goblog/pkg/model"
+	"goblog/pkg/token"
+	"goblog/pkg/totp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeCount 每次生成的恢复码数量
+const RecoveryCodeCount = 10
+
+// ErrInvalidTwoFactorCode 验证码或恢复码错误
+var ErrInvalidTwoFactorCode = errors.New("验证码错误")
+
+// RecoveryCode 两步验证恢复码，只保存哈希值，每个恢复码只能使用一次
+type RecoveryCode struct {
+	ID        uint64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	UserID    uint64 `gorm:"not null;index"`
+	CodeHash  string `gorm:"type:varchar(64);not null;index"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User User `gorm:"constraint:OnDelete:CASCADE;"`
+}
+
+// TableName 恢复码数据表
+func (RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// HasTwoFactor 是否已启用两步验证
+func (u User) HasTwoFactor() bool {
+	return u.TwoFactorConfirmedAt != nil
+}
+
+// TwoFactorRequired 用户角色是否要求启用两步验证
+func (u User) TwoFactorRequired() bool {
+	return role.RequiresTwoFactor(u.RoleID)
+}
+
+// NeedsTwoFactorSetup 角色要求启用两步验证，但用户尚未启用
+func (u User) NeedsTwoFactorSetup() bool {
+	return u.TwoFactorRequired() && !u.HasTwoFactor()
+}
+
+// TwoFactorSecretPlain 解密后的两步验证密钥
+func (u User) TwoFactorSecretPlain() (string, error) {
+	return encrypt.Decrypt(encryptionKey(), u.TwoFactorSecret)
+}
+
+// StartTwoFactor 生成新的两步验证密钥，确认验证码之前不会生效
+func (u *User) StartTwoFactor() (string, error) {
+	secret := totp.GenerateSecret()
+	encrypted, err := encrypt.Encrypt(encryptionKey(), secret)
+	if err != nil {
+		return "", err
+	}
+
+	if err := model.DB.Model(u).UpdateColumns(map[string]interface{}{
+		"two_factor_secret":       encrypted,
+		"two_factor_confirmed_at": nil,
+	}).Error; err != nil {
+		return "", err
+	}
+
+	u.TwoFactorSecret = encrypted
+	u.TwoFactorConfirmedAt = nil
+	return secret, nil
+}
+
+// ConfirmTwoFactor 使用验证器应用生成的验证码确认启用两步验证，返回新生成的恢复码
+func (u *User) ConfirmTwoFactor(code string) ([]string, error) {
+	if len(u.TwoFactorSecret) == 0 {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	if err := u.verifyTOTP(code); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if err := model.DB.Model(u).UpdateColumn("two_factor_confirmed_at", now).Error; err != nil {
+		return nil, err
+	}
+	u.TwoFactorConfirmedAt = &now
+
+	return u.RegenerateRecoveryCodes()
+}
+
+// VerifyTwoFactor 登录时检查验证码，也可以使用未使用过的恢复码
+func (u *User) VerifyTwoFactor(code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return u.verifyTOTP(code)
+	}
+
+	result := model.DB.Model(&RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", u.ID, token.Hash(normalizeRecoveryCode(code))).
+		UpdateColumn("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// DisableTwoFactor 关闭两步验证，同时删除恢复码
+func (u *User) DisableTwoFactor() error {
+	return model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", u.ID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(u).UpdateColumns(map[string]interface{}{
+			"two_factor_secret":       "",
+			"two_factor_confirmed_at": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		u.TwoFactorSecret = ""
+		u.TwoFactorConfirmedAt = nil
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码，旧的恢复码全部失效，返回恢复码明文，仅显示一次
+func (u *User) RegenerateRecoveryCodes() ([]string, error) {
+	codes := make([]string, RecoveryCodeCount)
+	rows := make([]RecoveryCode, RecoveryCodeCount)
+	for i := range codes {
+		codes[i] = generateRecoveryCode()
+		rows[i] = RecoveryCode{UserID: u.ID, CodeHash: token.Hash(normalizeRecoveryCode(codes[i]))}
+	}
+
+	err := model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", u.ID).Delete(&RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Omit("User").Create(&rows).Error
+	})
+
+	return codes, err
+}
+
+// RemainingRecoveryCodes 未使用的恢复码数量
+func (u User) RemainingRecoveryCodes() (count int64) {
+	model.DB.Model(&RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", u.ID).Count(&count)
+	return
+}
+
+// verifyTOTP 检查验证码，并记录已使用的时间步，同一验证码不能使用两次
+func (u *User) verifyTOTP(code string) error {
+	secret, err := u.TwoFactorSecretPlain()
+	if err != nil {
+		return ErrInvalidTwoFactorCode
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), u.TwoFactorLastStep)
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	// 条件更新，并发提交同一验证码时只有一次能成功
+	result := model.DB.Model(&User{}).
+		Where("id = ? AND two_factor_last_step < ?", u.ID, step).
+		UpdateColumn("two_factor_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactorCode
+	}
+
+	u.TwoFactorLastStep = step
+	return nil
+}
+
+// generateRecoveryCode 生成形如 abcde-fghij 的恢复码
+func generateRecoveryCode() string {
+	b := make([]byte, 7)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	s := strings.ToLower(base32.StdEncoding.EncodeToString(b))[:10]
+	return s[:5] + "-" + s[5:]
+}
+
+// normalizeRecoveryCode 忽略大小写、空格和连字符
+func normalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
+
+// encryptionKey 加密两步验证密钥使用的密钥
+func encryptionKey() []byte {
+	return []byte(config.GetString("app.key"))
+}
@@ -2,8 +2,11 @@ package user
 
 import (
 	"goblog/app/models"
+	"goblog/app/models/role"
+	"goblog/pkg/model"
 	"goblog/pkg/password"
 	"goblog/pkg/route"
+	"time"
 )
 
 type User struct {
@@ -15,6 +18,26 @@ type User struct {
 
 	// gorm:"-" —— 设置 GORM 在读写时略过此字段，仅用于表单验证
 	PasswordConfirm string `gorm:"-" valid:"password_confirm"`
+
+	// RoleID 用户角色，注册时设置为 auth.default_role
+	RoleID uint64 `gorm:"not null;default:0;index"`
+
+	// BannedAt 被封禁的时间，封禁后无法登录
+	BannedAt *time.Time
+
+	// EmailVerifiedAt 邮箱验证时间，为空时表示邮箱未验证
+	EmailVerifiedAt *time.Time
+	// VerificationSentAt 最近一次发送验证邮件的时间，用于限制重发频率
+	VerificationSentAt *time.Time
+
+	// TwoFactorSecret 加密保存的两步验证密钥，TwoFactorConfirmedAt 不为空时两步验证才生效
+	TwoFactorSecret      string `gorm:"type:varchar(255)"`
+	TwoFactorConfirmedAt *time.Time
+	// TwoFactorLastStep 最近一次使用的验证码时间步，防止验证码被重放
+	TwoFactorLastStep int64 `gorm:"not null;default:0"`
+
+	// SessionVersion 会话版本，登录时写入会话，修改后所有已登录的会话失效
+	SessionVersion uint64 `gorm:"not null;default:0"`
 }
 
 // ComparePassword 对比密码是否匹配
@@ -22,6 +45,33 @@ func (u User) ComparePassword(_password string) bool {
 	return password.CheckHash(_password, u.Password)
 }
 
+// IsBanned 是否已被封禁
+func (u User) IsBanned() bool {
+	return u.BannedAt != nil
+}
+
+// IsVerified 邮箱是否已验证
+func (u User) IsVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// CreatedAtDate 注册日期
+func (u User) CreatedAtDate() string {
+	return u.CreatedAt.Format("2006-01-02")
+}
+
+// HasPermission 用户所属角色是否拥有权限
+func (u User) HasPermission(permission string) bool {
+	return role.HasPermission(u.RoleID, permission)
+}
+
+// Role 获取用户角色
+func (u User) Role() role.Role {
+	var _role role.Role
+	model.DB.First(&_role, u.RoleID)
+	return _role
+}
+
 // Link 方法用来生成用户链接
 func (user User) Link() string {
 	return route.Name2URL("users.show", "id", user.GetStringID())
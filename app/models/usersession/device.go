@@ -0,0 +1,70 @@
+package usersession
+
+import (
+	"strings"
+	"time"
+)
+
+// Device 已登录的设备，每次登录创建一条，会话中保存令牌明文，数据库只保存哈希值
+// 删除记录即可让对应设备退出登录，与会话驱动无关
+type Device struct {
+	ID         uint64    `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	UserID     uint64    `gorm:"not null;index"`
+	TokenHash  string    `gorm:"type:varchar(64);not null;uniqueIndex"`
+	IP         string    `gorm:"type:varchar(45);not null;default:''"`
+	UserAgent  string    `gorm:"type:varchar(255);not null;default:''"`
+	LastSeenAt time.Time `gorm:"not null"`
+	CreatedAt  time.Time
+}
+
+// TableName 表名
+func (Device) TableName() string {
+	return "user_sessions"
+}
+
+// Name 根据 User-Agent 显示浏览器和操作系统，如「Chrome · Windows」
+func (d Device) Name() string {
+	ua := d.UserAgent
+
+	browser := "未知浏览器"
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+	} {
+		if strings.Contains(ua, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	system := "未知系统"
+	for _, o := range []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(ua, o.token) {
+			system = o.name
+			break
+		}
+	}
+
+	return browser + " · " + system
+}
+
+// CreatedAtTime 登录时间
+func (d Device) CreatedAtTime() string {
+	return d.CreatedAt.Format("2006-01-02 15:04")
+}
+
+// LastSeenAtTime 最后访问时间
+func (d Device) LastSeenAtTime() string {
+	return d.LastSeenAt.Format("2006-01-02 15:04")
+}
@@ -0,0 +1,99 @@
+package usersession
+
+import (
+	"goblog/pkg/model"
+	"goblog/pkg/token"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// touchInterval 更新最后访问时间的最短间隔
+const touchInterval = time.Minute
+
+// CreateDevice 记录一次登录，返回保存到会话中的令牌明文
+func CreateDevice(userID uint64, ip, userAgent string) (string, error) {
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	t := token.Generate()
+	err := model.DB.Create(&Device{
+		UserID:     userID,
+		TokenHash:  token.Hash(t),
+		IP:         ip,
+		UserAgent:  userAgent,
+		LastSeenAt: time.Now(),
+	}).Error
+	return t, err
+}
+
+// GetDevice 通过令牌获取设备
+func GetDevice(t string) (Device, error) {
+	var device Device
+	err := model.DB.Where("token_hash = ?", token.Hash(t)).First(&device).Error
+	return device, err
+}
+
+// GetDevicesByUser 获取用户已登录的设备，最近访问的在前
+func GetDevicesByUser(userID uint64) ([]Device, error) {
+	var devices []Device
+	err := model.DB.Where("user_id = ?", userID).Order("last_seen_at desc").Find(&devices).Error
+	return devices, err
+}
+
+// Touch 更新最后访问时间和 IP，一分钟内只更新一次
+func (d *Device) Touch(ip string) error {
+	if time.Since(d.LastSeenAt) < touchInterval && d.IP == ip {
+		return nil
+	}
+	d.LastSeenAt = time.Now()
+	d.IP = ip
+	return model.DB.Model(d).Select("LastSeenAt", "IP").Updates(d).Error
+}
+
+// Revoke 退出该设备的登录，同时删除该设备的「记住我」令牌
+func (d Device) Revoke() error {
+	_, err := revokeDevices(model.DB.Where("id = ?", d.ID))
+	return err
+}
+
+// RevokeDevice 退出用户的某个设备，返回退出的数量，不是该用户的设备时为 0
+func RevokeDevice(userID, id uint64) (int64, error) {
+	return revokeDevices(model.DB.Where("user_id = ? AND id = ?", userID, id))
+}
+
+// RevokeOtherDevices 退出用户除 exceptID 以外的所有设备
+func RevokeOtherDevices(userID, exceptID uint64) (int64, error) {
+	return revokeDevices(model.DB.Where("user_id = ? AND id <> ?", userID, exceptID))
+}
+
+// RevokeAllDevices 退出用户的所有设备，如重置密码后
+func RevokeAllDevices(userID uint64) (int64, error) {
+	if err := DeleteRememberTokens(userID); err != nil {
+		return 0, err
+	}
+	return revokeDevices(model.DB.Where("user_id = ?", userID))
+}
+
+// revokeDevices 删除符合条件的设备及其「记住我」令牌
+func revokeDevices(scope *gorm.DB) (int64, error) {
+	var ids []uint64
+	if err := scope.Model(&Device{}).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := model.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("device_id IN ?", ids).Delete(&RememberToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Device{}, ids).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
@@ -0,0 +1,41 @@
+package usersession
+
+import (
+	"goblog/pkg/model"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Handler 数据库会话存储，实现 session.Handler 接口
+type Handler struct{}
+
+// Read 读取未过期的会话数据
+func (Handler) Read(id string) ([]byte, error) {
+	var s Session
+	err := model.DB.Where("id = ? AND expires_at > ?", id, time.Now()).First(&s).Error
+	if err == gorm.ErrRecordNotFound {
+		return nil, nil
+	}
+	return s.Data, err
+}
+
+// Write 写入会话数据
+func (Handler) Write(id string, data []byte, expiresAt time.Time) error {
+	return model.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"data", "expires_at", "updated_at"}),
+	}).Create(&Session{ID: id, Data: data, ExpiresAt: expiresAt}).Error
+}
+
+// Destroy 删除会话
+func (Handler) Destroy(id string) error {
+	return model.DB.Where("id = ?", id).Delete(&Session{}).Error
+}
+
+// GC 删除已过期的会话
+func (Handler) GC() (int64, error) {
+	result := model.DB.Where("expires_at <= ?", time.Now()).Delete(&Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,142 @@
+package usersession
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"goblog/pkg/model"
+	"goblog/pkg/token"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// rotateGrace 令牌更换后，旧的验证码在此时间内仍然有效，避免同时打开多个页面时被误判为盗用
+const rotateGrace = time.Minute
+
+var (
+	// ErrInvalidRememberToken 令牌格式错误、不存在或已过期
+	ErrInvalidRememberToken = errors.New("记住我令牌无效或已过期")
+	// ErrRememberTokenTheft 选择符正确但验证码错误，令牌可能已被盗用
+	ErrRememberTokenTheft = errors.New("记住我令牌可能已被盗用")
+)
+
+// RememberToken 「记住我」令牌，Cookie 中保存「选择符:验证码」，选择符用于查找，验证码只保存哈希值
+// 每次使用后更换验证码，使用过期的验证码说明令牌被复制过，此时删除该用户的所有令牌
+type RememberToken struct {
+	ID                    uint64 `gorm:"column:id;primaryKey;autoIncrement;not null"`
+	UserID                uint64 `gorm:"not null;index"`
+	DeviceID              uint64 `gorm:"not null;default:0;index"`
+	Selector              string `gorm:"type:varchar(24);not null;uniqueIndex"`
+	ValidatorHash         string `gorm:"type:varchar(64);not null"`
+	PreviousValidatorHash string `gorm:"type:varchar(64);not null;default:''"`
+	RotatedAt             *time.Time
+	ExpiresAt             time.Time `gorm:"not null;index"`
+	CreatedAt             time.Time
+}
+
+// CreateRememberToken 为用户在某个登录设备上创建令牌，返回 Cookie 的值
+func CreateRememberToken(userID, deviceID uint64, ttl time.Duration) (string, error) {
+	selector, validator := randomHex(12), token.Generate()
+
+	err := model.DB.Create(&RememberToken{
+		UserID:        userID,
+		DeviceID:      deviceID,
+		Selector:      selector,
+		ValidatorHash: token.Hash(validator),
+		ExpiresAt:     time.Now().Add(ttl),
+	}).Error
+	return selector + ":" + validator, err
+}
+
+// ConsumeRememberToken 检查 Cookie 中的令牌并更换验证码，返回令牌和新的 Cookie 值
+// 在更换后的宽限时间内使用旧验证码时视为有效，但不再更换，新的 Cookie 值为空
+func ConsumeRememberToken(value string, ttl time.Duration) (RememberToken, string, error) {
+	var rt RememberToken
+
+	// 1. 解析并查找令牌
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 || len(parts[0]) != 24 {
+		return rt, "", ErrInvalidRememberToken
+	}
+	selector, validator := parts[0], parts[1]
+
+	err := model.DB.Where("selector = ? AND expires_at > ?", selector, time.Now()).First(&rt).Error
+	if err == gorm.ErrRecordNotFound {
+		return rt, "", ErrInvalidRememberToken
+	} else if err != nil {
+		return rt, "", err
+	}
+
+	// 2. 检查验证码
+	hash := token.Hash(validator)
+	if !hashEqual(hash, rt.ValidatorHash) {
+		if rt.RotatedAt != nil && time.Since(*rt.RotatedAt) < rotateGrace && hashEqual(hash, rt.PreviousValidatorHash) {
+			return rt, "", nil
+		}
+
+		// 验证码不一致，令牌可能被复制后使用过，退出该用户所有「记住我」的登录和所有设备，
+		// 盗用者用令牌登录后的会话也随之失效
+		if _, err := RevokeAllDevices(rt.UserID); err != nil {
+			return rt, "", err
+		}
+		return rt, "", ErrRememberTokenTheft
+	}
+
+	// 3. 更换验证码，并发请求同时更换时只有一个成功
+	newValidator := token.Generate()
+	now := time.Now()
+	result := model.DB.Model(&RememberToken{}).
+		Where("id = ? AND validator_hash = ?", rt.ID, rt.ValidatorHash).
+		Updates(map[string]interface{}{
+			"validator_hash":          token.Hash(newValidator),
+			"previous_validator_hash": rt.ValidatorHash,
+			"rotated_at":              now,
+			"expires_at":              now.Add(ttl),
+		})
+	if result.Error != nil {
+		return rt, "", result.Error
+	}
+	if result.RowsAffected == 0 {
+		return rt, "", nil
+	}
+	return rt, selector + ":" + newValidator, nil
+}
+
+// AttachDevice 令牌重新登录后，关联到新的登录设备
+func (rt *RememberToken) AttachDevice(deviceID uint64) error {
+	rt.DeviceID = deviceID
+	return model.DB.Model(rt).UpdateColumn("device_id", deviceID).Error
+}
+
+// DeleteRememberToken 删除 Cookie 值对应的令牌
+func DeleteRememberToken(value string) error {
+	selector := strings.SplitN(value, ":", 2)[0]
+	return model.DB.Where("selector = ?", selector).Delete(&RememberToken{}).Error
+}
+
+// DeleteRememberTokens 删除用户的所有令牌
+func DeleteRememberTokens(userID uint64) error {
+	return model.DB.Where("user_id = ?", userID).Delete(&RememberToken{}).Error
+}
+
+// PruneRememberTokens 删除已过期的令牌
+func PruneRememberTokens() error {
+	return model.DB.Where("expires_at <= ?", time.Now()).Delete(&RememberToken{}).Error
+}
+
+// randomHex 生成 n 字节的随机数，以十六进制字符串返回
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// hashEqual 以固定时间比较两个哈希值
+func hashEqual(a, b string) bool {
+	return len(b) > 0 && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,13 @@
+package usersession
+
+import (
+	"time"
+)
+
+// Session 保存在数据库中的会话，数据已签名加密
+type Session struct {
+	ID        string    `gorm:"type:varchar(64);primaryKey"`
+	Data      []byte    `gorm:"not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+	UpdatedAt time.Time
+}
@@ -0,0 +1,6 @@
+package policies
+
+func init() {
+	// 审核评论：文章作者可审核自己文章下的评论，comment.moderate.any 可审核所有评论
+	Define("comment.moderate", ownOrAny("comment.moderate"))
+}
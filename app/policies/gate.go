@@ -0,0 +1,81 @@
+package policies
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/comment"
+	"goblog/app/models/user"
+	"goblog/pkg/auth"
+	"net/http"
+)
+
+// Ability 权限规则，resource 为被操作的对象，未指定对象（如路由中间件）时为 nil
+type Ability func(_user user.User, resource interface{}) bool
+
+// abilities 已声明的权限规则
+var abilities = map[string]Ability{}
+
+// Define 声明权限规则，一般在各 policy 文件的 init 中调用
+func Define(name string, ability Ability) {
+	abilities[name] = ability
+}
+
+// Allows 当前请求的登录用户是否拥有权限，未登录时始终返回 false
+func Allows(r *http.Request, name string, resource ...interface{}) bool {
+	_user := auth.User(r)
+	if _user.ID == 0 {
+		return false
+	}
+	return AllowsUser(_user, name, resource...)
+}
+
+// AllowsUser 用户是否拥有权限，未声明规则的权限直接按角色权限判断
+// 角色要求两步验证而用户尚未启用时没有任何权限，网页、API 和 GraphQL 都经过这里检查
+func AllowsUser(_user user.User, name string, resource ...interface{}) bool {
+	if _user.NeedsTwoFactorSetup() {
+		return false
+	}
+
+	var res interface{}
+	if len(resource) > 0 {
+		res = resource[0]
+	}
+
+	if ability, ok := abilities[name]; ok {
+		return ability(_user, res)
+	}
+	return _user.HasPermission(name)
+}
+
+// ownOrAny 拥有 name.any 权限，或拥有 name.own 权限且是资源的所有者
+// 未指定资源时只要拥有其中一项权限即可，具体资源由控制器再次检查
+func ownOrAny(name string) Ability {
+	return func(_user user.User, resource interface{}) bool {
+		if _user.HasPermission(name + ".any") {
+			return true
+		}
+		if !_user.HasPermission(name + ".own") {
+			return false
+		}
+		if resource == nil {
+			return true
+		}
+		ownerID, ok := ownerOf(resource)
+		return ok && ownerID == _user.ID
+	}
+}
+
+// ownerOf 获取资源所有者的用户 ID
+func ownerOf(resource interface{}) (uint64, bool) {
+	switch r := resource.(type) {
+	case article.Article:
+		return r.UserID, true
+	case *article.Article:
+		return r.UserID, true
+	case comment.Comment:
+		// 评论归文章作者审核，需预加载 comment.Article
+		return r.Article.UserID, true
+	case *comment.Comment:
+		return r.Article.UserID, true
+	}
+	return 0, false
+}
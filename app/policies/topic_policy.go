@@ -1,10 +1,9 @@
 package policies
 
-import (
-	"goblog/app/models/article"
-	"goblog/pkg/auth"
-)
+func init() {
+	// 编辑文章，包括查看未发布的文章、历史版本和恢复版本
+	Define("article.update", ownOrAny("article.update"))
 
-func CanModifyArticle(_article article.Article) bool {
-	return auth.User().ID == _article.UserID
+	// 删除文章
+	Define("article.delete", ownOrAny("article.delete"))
 }
@@ -0,0 +1,49 @@
+package requests
+
+import (
+	"goblog/app/models/accesstoken"
+	"strings"
+
+	"github.com/thedevsaddam/govalidator"
+)
+
+// ValidateAccessTokenForm 验证创建令牌表单，返回 errs 长度等于零即通过
+func ValidateAccessTokenForm(data accesstoken.AccessToken) map[string][]string {
+
+	// 1. 定制认证规则
+	rules := govalidator.MapData{
+		"name":   []string{"required", "max_cn:50"},
+		"scopes": []string{"required"},
+	}
+
+	// 2. 定制错误消息
+	messages := govalidator.MapData{
+		"name": []string{
+			"required:令牌名称为必填项",
+			"max_cn:令牌名称不能超过 50 个字",
+		},
+		"scopes": []string{
+			"required:请至少选择一个权限范围",
+		},
+	}
+
+	// 3. 配置初始化
+	opts := govalidator.Options{
+		Data:          &data,
+		Rules:         rules,
+		TagIdentifier: "valid", // 模型中的 Struct 标签标识符
+		Messages:      messages,
+	}
+
+	// 4. 开始验证
+	errs := govalidator.New(opts).ValidateStruct()
+
+	// 5. 权限范围必须是已定义的
+	for _, scope := range data.ScopeList() {
+		if !accesstoken.IsValidScope(scope) {
+			errs["scopes"] = append(errs["scopes"], "权限范围「"+strings.TrimSpace(scope)+"」不正确")
+		}
+	}
+
+	return errs
+}
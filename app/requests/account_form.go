@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"goblog/app/models/user"
+
+	"github.com/thedevsaddam/govalidator"
+)
+
+// EmailRules 修改邮箱表单的验证规则，每次调用返回新的副本
+func EmailRules() govalidator.MapData {
+	return govalidator.MapData{
+		"email": []string{"required", "min:4", "max:30", "email", "not_exists:users,email"},
+	}
+}
+
+// ValidateEmailForm 验证修改邮箱表单，返回 errs 长度等于零即通过
+func ValidateEmailForm(data user.User) map[string][]string {
+
+	// 1. 定制认证规则
+	rules := EmailRules()
+
+	// 2. 定制错误消息
+	messages := govalidator.MapData{
+		"email": []string{
+			"required:Email 为必填项",
+			"min:Email 长度需大于 4",
+			"max:Email 长度需小于 30",
+			"email:Email 格式不正确，请提供有效的邮箱地址",
+		},
+	}
+
+	// 3. 配置初始化
+	opts := govalidator.Options{
+		Data:          &data,
+		Rules:         rules,
+		TagIdentifier: "valid", // 模型中的 Struct 标签标识符
+		Messages:      messages,
+	}
+
+	// 4. 开始验证
+	return govalidator.New(opts).ValidateStruct()
+}
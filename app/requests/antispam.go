@@ -0,0 +1,21 @@
+package requests
+
+import (
+	"goblog/pkg/antispam"
+	"net/http"
+)
+
+// ValidateFormGuard 检查表单的蜜罐字段和提交时间，未通过时将错误信息追加到 errs 的 field 字段
+func ValidateFormGuard(r *http.Request, errs map[string][]string, field string) map[string][]string {
+	switch antispam.Default.Check(r) {
+	case nil:
+		return errs
+	case antispam.ErrTooFast:
+		errs[field] = append(errs[field], "提交速度过快，请稍后再试")
+	case antispam.ErrExpired:
+		errs[field] = append(errs[field], "页面已过期，请重新提交")
+	default:
+		errs[field] = append(errs[field], "提交的表单无效，请刷新页面后重试")
+	}
+	return errs
+}
@@ -1,19 +1,35 @@
 package requests
 
 import (
+	"fmt"
 	"goblog/app/models/article"
+	"time"
+	"unicode/utf8"
 
 	"github.com/thedevsaddam/govalidator"
 )
 
+// ArticleRules 文章表单的验证规则，API 文档据此生成字段的长度等约束，每次调用返回新的副本
+func ArticleRules() govalidator.MapData {
+	return govalidator.MapData{
+		"title":  []string{"required", "min_cn:3", "max_cn:40"},
+		"body":   []string{"required", "min_cn:10"},
+		"status": []string{"required", "in:draft,published,scheduled,archived"},
+	}
+}
+
+// APIArticleRules API 发布文章的验证规则，未提供 status 时默认为草稿，因此不是必填项
+func APIArticleRules() govalidator.MapData {
+	rules := ArticleRules()
+	rules["status"] = []string{"in:draft,published,scheduled,archived"}
+	return rules
+}
+
 // ValidateArticleForm 验证表单，返回 errs 长度等于零即通过
 func ValidateArticleForm(data article.Article) map[string][]string {
 
 	// 1. 定制认证规则
-	rules := govalidator.MapData{
-		"title": []string{"required", "min_cn:3", "max_cn:40"},
-		"body":  []string{"required", "min_cn:10"},
-	}
+	rules := ArticleRules()
 
 	// 2. 定制错误消息
 	messages := govalidator.MapData{
@@ -26,6 +42,10 @@ func ValidateArticleForm(data article.Article) map[string][]string {
 			"required:文章内容为必填项",
 			"min_cn:长度需大于 10",
 		},
+		"status": []string{
+			"required:请选择文章状态",
+			"in:文章状态不正确",
+		},
 	}
 
 	// 3. 配置初始化
@@ -37,5 +57,26 @@ func ValidateArticleForm(data article.Article) map[string][]string {
 	}
 
 	// 4. 开始验证
-	return govalidator.New(opts).ValidateStruct()
+	errs := govalidator.New(opts).ValidateStruct()
+
+	// 5. 定时发布需要一个未来的发布时间
+	if data.Status == article.StatusScheduled {
+		if data.PublishedAt == nil {
+			errs["published_at"] = append(errs["published_at"], "定时发布需设置发布时间")
+		} else if !data.PublishedAt.After(time.Now()) {
+			errs["published_at"] = append(errs["published_at"], "发布时间需晚于当前时间")
+		}
+	}
+
+	// 6. 标签数量和长度
+	if len(data.Tags) > 10 {
+		errs["tags"] = append(errs["tags"], "标签不能超过 10 个")
+	}
+	for _, t := range data.Tags {
+		if utf8.RuneCountInString(t.Name) > 20 {
+			errs["tags"] = append(errs["tags"], fmt.Sprintf("标签「%s」长度不能超过 20 个字", t.Name))
+		}
+	}
+
+	return errs
 }
@@ -0,0 +1,56 @@
+package requests
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/tag"
+	"goblog/pkg/types"
+	"strings"
+	"time"
+)
+
+// ArticleInput API 和 GraphQL 创建、更新文章的参数，为 nil 的字段保持不变
+type ArticleInput struct {
+	Title          *string    `json:"title"`
+	Body           *string    `json:"body"`
+	Status         *string    `json:"status"`
+	PublishedAt    *time.Time `json:"published_at"`
+	CategoryID     *uint64    `json:"category_id"`
+	Tags           []string   `json:"tags"`
+	CommentsClosed *bool      `json:"comments_closed"`
+}
+
+// Apply 将提供的字段写入文章并验证，返回 errs 长度等于零即通过
+func (input ArticleInput) Apply(_article *article.Article) map[string][]string {
+	if input.Title != nil {
+		_article.Title = *input.Title
+	}
+	if input.Body != nil {
+		_article.Body = *input.Body
+	}
+	if input.CommentsClosed != nil {
+		_article.CommentsClosed = *input.CommentsClosed
+	}
+	if input.Tags != nil {
+		_article.SetTags(tag.ParseNames(strings.Join(input.Tags, ",")))
+	}
+
+	// 状态和发布时间一起处理，未提供时保持原值
+	status, publishAt := _article.Status, _article.PublishedAt
+	if input.Status != nil {
+		status = *input.Status
+	}
+	if input.PublishedAt != nil {
+		publishAt = input.PublishedAt
+	}
+	_article.SetStatus(status, publishAt)
+
+	errs := ValidateArticleForm(*_article)
+	if input.CategoryID != nil {
+		if _, err := category.Get(types.Uint64ToString(*input.CategoryID)); err != nil {
+			errs["category_id"] = append(errs["category_id"], "分类不存在")
+		}
+		_article.CategoryID = *input.CategoryID
+	}
+	return errs
+}
@@ -6,13 +6,18 @@ import (
 	"github.com/thedevsaddam/govalidator"
 )
 
+// CategoryRules 分类表单的验证规则，每次调用返回新的副本
+func CategoryRules() govalidator.MapData {
+	return govalidator.MapData{
+		"name": []string{"required", "min_cn:2", "max_cn:8", "not_exists:categories,name"},
+	}
+}
+
 // ValidateCategoryForm 验证表单，返回 errs 长度等于零即通过
 func ValidateCategoryForm(data category.Category) map[string][]string {
 
 	// 1. 定制认证规则
-	rules := govalidator.MapData{
-		"name": []string{"required", "min_cn:2", "max_cn:8", "not_exists:categories,name"},
-	}
+	rules := CategoryRules()
 
 	// 2. 定制错误消息
 	messages := govalidator.MapData{
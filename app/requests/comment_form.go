@@ -0,0 +1,50 @@
+package requests
+
+import (
+	"goblog/app/models/comment"
+
+	"github.com/thedevsaddam/govalidator"
+)
+
+// ValidateCommentForm 验证表单，返回 errs 长度等于零即通过，游客需填写名称和邮箱
+func ValidateCommentForm(data comment.Comment) map[string][]string {
+
+	// 1. 定制认证规则
+	rules := govalidator.MapData{
+		"body": []string{"required", "min_cn:2", "max_cn:2000"},
+	}
+	if data.UserID == nil {
+		rules["guest_name"] = []string{"required", "min_cn:2", "max_cn:20"}
+		rules["guest_email"] = []string{"required", "email", "max:255"}
+	}
+
+	// 2. 定制错误消息
+	messages := govalidator.MapData{
+		"body": []string{
+			"required:评论内容为必填项",
+			"min_cn:评论内容长度需至少 2 个字",
+			"max_cn:评论内容长度不能超过 2000 个字",
+		},
+		"guest_name": []string{
+			"required:名称为必填项",
+			"min_cn:名称长度需至少 2 个字",
+			"max_cn:名称长度不能超过 20 个字",
+		},
+		"guest_email": []string{
+			"required:Email 为必填项",
+			"email:Email 格式不正确，请提供有效的邮箱地址",
+			"max:Email 长度需小于 255",
+		},
+	}
+
+	// 3. 配置初始化
+	opts := govalidator.Options{
+		Data:          &data,
+		Rules:         rules,
+		TagIdentifier: "valid", // 模型中的 Struct 标签标识符
+		Messages:      messages,
+	}
+
+	// 4. 开始验证
+	return govalidator.New(opts).ValidateStruct()
+}
@@ -0,0 +1,46 @@
+package requests
+
+import (
+	"goblog/app/models/user"
+
+	"github.com/thedevsaddam/govalidator"
+)
+
+// ValidatePasswordResetForm 验证重置密码表单，返回 errs 长度等于零即通过
+func ValidatePasswordResetForm(data user.User) map[string][]string {
+
+	// 1. 定制认证规则
+	rules := govalidator.MapData{
+		"password":         []string{"required", "min:6"},
+		"password_confirm": []string{"required"},
+	}
+
+	// 2. 定制错误消息
+	messages := govalidator.MapData{
+		"password": []string{
+			"required:密码为必填项",
+			"min:长度需大于 6",
+		},
+		"password_confirm": []string{
+			"required:确认密码框为必填项",
+		},
+	}
+
+	// 3. 配置初始化
+	opts := govalidator.Options{
+		Data:          &data,
+		Rules:         rules,
+		TagIdentifier: "valid", // 模型中的 Struct 标签标识符
+		Messages:      messages,
+	}
+
+	// 4. 开始验证
+	errs := govalidator.New(opts).ValidateStruct()
+
+	// 5. 确认密码
+	if data.Password != data.PasswordConfirm {
+		errs["password_confirm"] = append(errs["password_confirm"], "两次输入密码不匹配！")
+	}
+
+	return errs
+}
@@ -6,16 +6,21 @@ import (
 	"github.com/thedevsaddam/govalidator"
 )
 
-// ValidateRegistrationForm 验证表单，返回 errs 长度等于零即通过
-func ValidateRegistrationForm(data user.User) map[string][]string {
-
-	// 1. 定制认证规则
-	rules := govalidator.MapData{
+// RegistrationRules 注册表单的验证规则，API 创建用户时也使用，每次调用返回新的副本
+func RegistrationRules() govalidator.MapData {
+	return govalidator.MapData{
 		"name":             []string{"required", "alpha_num", "between:3,20", "not_exists:users,name"},
 		"email":            []string{"required", "min:4", "max:30", "email", "not_exists:users,email"},
 		"password":         []string{"required", "min:6"},
 		"password_confirm": []string{"required"},
 	}
+}
+
+// ValidateRegistrationForm 验证表单，返回 errs 长度等于零即通过
+func ValidateRegistrationForm(data user.User) map[string][]string {
+
+	// 1. 定制认证规则
+	rules := RegistrationRules()
 
 	// 2. 定制错误消息
 	messages := govalidator.MapData{
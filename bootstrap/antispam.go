@@ -0,0 +1,24 @@
+package bootstrap
+
+import (
+	"goblog/app/models/spamtoken"
+	"goblog/pkg/antispam"
+	"goblog/pkg/config"
+	"goblog/pkg/logger"
+	"time"
+)
+
+// SetupAntispam 配置垃圾内容过滤器，并从数据库加载训练数据
+func SetupAntispam() {
+	filter := antispam.New(spamtoken.Store{})
+
+	filter.Threshold = config.GetFloat64("antispam.threshold")
+	filter.MinDocuments = uint64(config.GetInt("antispam.min_documents"))
+	filter.Key = []byte(config.GetString("app.key"))
+	filter.MinSubmitTime = time.Duration(config.GetInt("antispam.min_submit_seconds")) * time.Second
+	filter.MaxAge = time.Duration(config.GetInt("antispam.form_max_age")) * time.Second
+
+	logger.LogError(filter.Load())
+
+	antispam.Default = filter
+}
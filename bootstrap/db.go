@@ -1,11 +1,21 @@
 package bootstrap
 
 import (
+	"goblog/app/models/accesstoken"
 	"goblog/app/models/article"
 	"goblog/app/models/category"
+	"goblog/app/models/comment"
+	"goblog/app/models/identity"
+	"goblog/app/models/loginattempt"
+	"goblog/app/models/role"
+	"goblog/app/models/spamtoken"
+	"goblog/app/models/tag"
 	"goblog/app/models/user"
+	"goblog/app/models/usersession"
 	"goblog/pkg/config"
+	"goblog/pkg/logger"
 	"goblog/pkg/model"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
@@ -28,14 +38,105 @@ func SetupDB() {
 	sqlDB.SetConnMaxLifetime(time.Duration(config.GetInt("database.mysql.max_life_seconds")) * time.Second)
 
 	// 创建和维护数据表结构
-	migration(db)
+	Migrate(db)
 }
 
-func migration(db *gorm.DB) {
+// Migrate 创建和维护数据表结构，测试中也用它初始化数据库
+func Migrate(db *gorm.DB) {
+	// 为已有数据补全 slug
+	migrateSlugs(db)
+
+	// 已有用户视为已验证邮箱
+	migrateEmailVerification(db)
+
 	// 自动迁移
 	db.AutoMigrate(
 		&user.User{},
+		&user.PasswordReset{},
+		&user.RecoveryCode{},
 		&article.Article{},
+		&article.Revision{},
+		&article.OldSlug{},
 		&category.Category{},
+		&category.OldSlug{},
+		&tag.Tag{},
+		&comment.Comment{},
+		&spamtoken.SpamToken{},
+		&loginattempt.LoginAttempt{},
+		&loginattempt.Throttle{},
+		&usersession.Session{},
+		&usersession.Device{},
+		&usersession.RememberToken{},
+		&identity.Identity{},
+		&accesstoken.AccessToken{},
+		&role.Role{},
+		&role.Permission{},
 	)
+
+	// 已发布的旧文章补全发布时间
+	migratePublishedAt(db)
+
+	// 创建内置角色和权限，并为已有用户分配角色
+	migrateRoles(db)
+}
+
+// migrateSlugs 为已有的文章和分类补全 slug
+// 需在 AutoMigrate 创建 slug 唯一索引之前执行，否则已有数据的空 slug 会导致索引创建失败
+func migrateSlugs(db *gorm.DB) {
+	m := db.Migrator()
+
+	if m.HasTable(&article.Article{}) && !m.HasColumn(&article.Article{}, "Slug") {
+		logger.LogError(db.AutoMigrate(&article.OldSlug{}))
+		logger.LogError(m.AddColumn(&article.Article{}, "Slug"))
+		logger.LogError(article.BackfillSlugs())
+	}
+
+	if m.HasTable(&category.Category{}) && !m.HasColumn(&category.Category{}, "Slug") {
+		logger.LogError(db.AutoMigrate(&category.OldSlug{}))
+		logger.LogError(m.AddColumn(&category.Category{}, "Slug"))
+		logger.LogError(category.BackfillSlugs())
+	}
+}
+
+// migrateEmailVerification 新增邮箱验证字段时，将已有用户的邮箱标记为已验证，避免老用户被要求验证
+func migrateEmailVerification(db *gorm.DB) {
+	m := db.Migrator()
+
+	if m.HasTable(&user.User{}) && !m.HasColumn(&user.User{}, "EmailVerifiedAt") {
+		logger.LogError(m.AddColumn(&user.User{}, "EmailVerifiedAt"))
+		logger.LogError(db.Exec("UPDATE users SET email_verified_at = created_at WHERE email_verified_at IS NULL").Error)
+	}
+}
+
+// migratePublishedAt 新增文章状态前发布的文章没有发布时间，使用创建时间补全，使其按发布时间排序时位置不变
+func migratePublishedAt(db *gorm.DB) {
+	logger.LogError(db.Exec("UPDATE articles SET published_at = created_at WHERE status = ? AND published_at IS NULL",
+		article.StatusPublished).Error)
+}
+
+// migrateRoles 创建内置角色和权限；未分配角色的用户设置为默认角色，
+// 配置了 auth.admin_email 时将该邮箱对应的用户设为管理员
+func migrateRoles(db *gorm.DB) {
+	logger.LogError(role.Seed())
+
+	defaultRole, err := role.GetByName(config.GetString("auth.default_role"))
+	logger.LogError(err)
+	logger.LogError(db.Model(&user.User{}).Where("role_id = 0").UpdateColumn("role_id", defaultRole.ID).Error)
+
+	// 不再自动提升任何用户，管理员需通过配置明确指定
+	email := config.GetString("auth.admin_email")
+	if len(email) == 0 {
+		return
+	}
+	var _user user.User
+	err = db.Where("email = ?", email).First(&_user).Error
+	if err == gorm.ErrRecordNotFound {
+		log.Println("auth.admin_email 对应的用户不存在，请注册后重新启动：", email)
+		return
+	}
+	logger.LogError(err)
+
+	admin, err := role.GetByName(role.Admin)
+	logger.LogError(err)
+	logger.LogError(db.Model(&_user).UpdateColumn("role_id", admin.ID).Error)
 }
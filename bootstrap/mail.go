@@ -0,0 +1,27 @@
+package bootstrap
+
+import (
+	"goblog/pkg/config"
+	"goblog/pkg/mail"
+	netmail "net/mail"
+)
+
+// SetupMail 根据配置设置邮件驱动和默认发件人
+func SetupMail() {
+	switch config.GetString("mail.driver") {
+	case "smtp":
+		mail.Default = &mail.SMTPMailer{
+			Host:     config.GetString("mail.host"),
+			Port:     config.GetInt("mail.port"),
+			Username: config.GetString("mail.username"),
+			Password: config.GetString("mail.password"),
+		}
+	default:
+		mail.Default = &mail.FileMailer{Dir: config.GetString("mail.file_path")}
+	}
+
+	mail.From = netmail.Address{
+		Name:    config.GetString("mail.from_name"),
+		Address: config.GetString("mail.from_address"),
+	}
+}
@@ -0,0 +1,38 @@
+package bootstrap
+
+import (
+	"goblog/pkg/config"
+	"goblog/pkg/oidc"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// SetupOIDC 注册已配置 client_id 的外部身份提供方，服务发现在第一次登录时进行
+func SetupOIDC() {
+	for name := range cast.ToStringMap(config.Get("oidc.providers")) {
+		prefix := "oidc.providers." + name + "."
+		if len(config.GetString(prefix+"client_id")) == 0 {
+			continue
+		}
+
+		redirectURL := config.GetString(prefix + "redirect_url")
+		if len(redirectURL) == 0 {
+			redirectURL = strings.TrimSuffix(config.GetString("app.url"), "/") + "/auth/oidc/" + name + "/callback"
+		}
+
+		oidc.Register(&oidc.Provider{
+			Name:         name,
+			Label:        config.GetString(prefix + "label"),
+			Issuer:       config.GetString(prefix + "issuer"),
+			ClientID:     config.GetString(prefix + "client_id"),
+			ClientSecret: config.GetString(prefix + "client_secret"),
+			RedirectURL:  redirectURL,
+			Scopes:       strings.Fields(config.GetString(prefix + "scopes")),
+			AuthURL:      config.GetString(prefix + "auth_url"),
+			TokenURL:     config.GetString(prefix + "token_url"),
+			UserInfoURL:  config.GetString(prefix + "userinfo_url"),
+			TrustEmail:   config.GetBool(prefix + "trust_email"),
+		})
+	}
+}
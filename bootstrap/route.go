@@ -9,6 +9,7 @@ import (
 // SetupRoute 路由初始化
 func SetupRoute() *mux.Router {
 	router := mux.NewRouter()
+	routes.RegisterAPIRoutes(router)
 	routes.RegisterWebRoutes(router)
 	route.SetRoute(router)
 
@@ -0,0 +1,37 @@
+package bootstrap
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/loginattempt"
+	"goblog/app/models/usersession"
+	"goblog/pkg/config"
+	"goblog/pkg/scheduler"
+	"goblog/pkg/session"
+	"time"
+)
+
+// SetupScheduler 注册并启动进程内的定时任务
+func SetupScheduler() {
+
+	// 发布已到时间的定时文章
+	scheduler.Every("articles.publish_scheduled", time.Duration(config.GetInt("app.scheduler_interval"))*time.Second, func() error {
+		_, err := article.PublishScheduled()
+		return err
+	})
+
+	// 清理已过期的登录限流记录
+	scheduler.Every("login_throttles.prune", time.Hour, func() error {
+		return loginattempt.Prune(time.Now().Add(-time.Duration(config.GetInt("throttle.decay_minutes")) * time.Minute))
+	})
+
+	// 清理过期的服务端会话
+	scheduler.Every("sessions.gc", time.Duration(config.GetInt("session.gc_interval"))*time.Minute, func() error {
+		_, err := session.GC()
+		return err
+	})
+
+	// 清理过期的「记住我」令牌
+	scheduler.Every("remember_tokens.prune", time.Hour, usersession.PruneRememberTokens)
+
+	scheduler.Start()
+}
@@ -0,0 +1,17 @@
+package bootstrap
+
+import (
+	"goblog/app/models/article"
+	"goblog/pkg/logger"
+	"goblog/pkg/search"
+)
+
+// SetupSearch 启动时为所有已发布的文章建立全文索引，之后由文章模型钩子增量更新
+func SetupSearch() {
+	articles, err := article.AllPublished()
+	logger.LogError(err)
+
+	for _, _article := range articles {
+		search.Default.Add(_article.SearchDocument())
+	}
+}
@@ -0,0 +1,70 @@
+package bootstrap
+
+import (
+	"goblog/app/models/usersession"
+	"goblog/pkg/config"
+	"goblog/pkg/logger"
+	"goblog/pkg/session"
+	"log"
+	"time"
+
+	"github.com/gorilla/sessions"
+)
+
+// defaultAppKey .env.example 和 config/app.go 中公开的默认 app.key，
+// 未设置 session.encryption_key 或在生产环境中不可使用
+const defaultAppKey = "33446a9dcf9ea060a0a6532b166da32f304af0de"
+
+// SetupSession 按 session.default 配置会话存储，需在配置加载和 SetupDB 之后调用
+func SetupSession() {
+
+	// 1. 签名密钥和加密密钥，默认的 app.key 是公开的，加密密钥也由它派生时任何人都能伪造会话
+	hashKey := config.GetString("app.key")
+	encryptionKey := config.GetString("session.encryption_key")
+	if hashKey == defaultAppKey {
+		if len(encryptionKey) == 0 || config.GetString("app.env") == "production" {
+			log.Fatal("请在 .env 中设置新的 APP_KEY，可使用 openssl rand -hex 20 生成")
+		}
+		log.Println("正在使用默认的 APP_KEY，请在 .env 中设置新的值")
+	}
+
+	// 未设置 session.encryption_key 时使用 HKDF 从 app.key 派生，不与签名密钥共用
+	blockKey := session.EncryptionKey(encryptionKey, hashKey)
+
+	// 2. 会话存储
+	lifetime := time.Duration(config.GetInt("session.lifetime")) * time.Minute
+	idleTimeout := time.Duration(config.GetInt("session.idle_timeout")) * time.Minute
+
+	// 服务端会话数据在空闲时间后过期，未设置空闲时间时以最长有效时间为准
+	ttl := idleTimeout
+	if ttl == 0 || (lifetime > 0 && lifetime < ttl) {
+		ttl = lifetime
+	}
+	if ttl == 0 {
+		ttl = 24 * time.Hour
+	}
+
+	maxAge := int(lifetime / time.Second)
+	switch driver := config.GetString("session.default"); driver {
+	case "cookie":
+		store := sessions.NewCookieStore([]byte(hashKey), blockKey)
+		store.MaxAge(maxAge)
+		session.Store = store
+	case "file":
+		handler, err := session.NewFileHandler(config.GetString("session.files"))
+		logger.LogError(err)
+		store := session.NewServerStore(handler, ttl, []byte(hashKey), blockKey)
+		store.MaxAge(maxAge)
+		session.Store = store
+	case "database":
+		store := session.NewServerStore(usersession.Handler{}, ttl, []byte(hashKey), blockKey)
+		store.MaxAge(maxAge)
+		session.Store = store
+	default:
+		log.Fatal("不支持的会话驱动：" + driver)
+	}
+
+	session.Name = config.GetString("session.session_name")
+	session.Lifetime = lifetime
+	session.IdleTimeout = idleTimeout
+}
@@ -0,0 +1,39 @@
+package bootstrap
+
+import (
+	"goblog/app/models/loginattempt"
+	"goblog/pkg/auth"
+	"goblog/pkg/config"
+	"goblog/pkg/throttle"
+	"time"
+)
+
+// SetupThrottle 配置登录失败限流
+func SetupThrottle() {
+	var store throttle.Store = throttle.NewMemoryStore()
+	if config.GetString("throttle.store") == "database" {
+		store = loginattempt.Store{}
+	}
+
+	baseDelay := time.Duration(config.GetInt("throttle.base_delay")) * time.Second
+	maxDelay := time.Duration(config.GetInt("throttle.max_delay")) * time.Second
+	decay := time.Duration(config.GetInt("throttle.decay_minutes")) * time.Minute
+
+	auth.AccountLimiter = throttle.New(store, throttle.Policy{
+		FreeAttempts:    config.GetInt("throttle.account_free_attempts"),
+		BaseDelay:       baseDelay,
+		MaxDelay:        maxDelay,
+		LockoutAttempts: config.GetInt("throttle.account_lockout_attempts"),
+		LockoutDuration: time.Duration(config.GetInt("throttle.account_lockout_minutes")) * time.Minute,
+		Decay:           decay,
+	})
+
+	auth.IPLimiter = throttle.New(store, throttle.Policy{
+		FreeAttempts:    config.GetInt("throttle.ip_free_attempts"),
+		BaseDelay:       baseDelay,
+		MaxDelay:        maxDelay,
+		LockoutAttempts: config.GetInt("throttle.ip_lockout_attempts"),
+		LockoutDuration: time.Duration(config.GetInt("throttle.ip_lockout_minutes")) * time.Minute,
+		Decay:           decay,
+	})
+}
@@ -0,0 +1,20 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("antispam", config.StrMap{
+
+		// 垃圾内容概率超过此值时，评论标记为垃圾评论、注册被拒绝
+		"threshold": config.Env("ANTISPAM_THRESHOLD", 0.9),
+
+		// 每类样本至少标记多少条后分类器才开始判断
+		"min_documents": config.Env("ANTISPAM_MIN_DOCUMENTS", 5),
+
+		// 从打开表单到提交的最短秒数，快于此时间视为机器人
+		"min_submit_seconds": config.Env("ANTISPAM_MIN_SUBMIT_SECONDS", 3),
+
+		// 表单的最长有效秒数
+		"form_max_age": config.Env("ANTISPAM_FORM_MAX_AGE", 86400),
+	})
+}
@@ -21,5 +21,8 @@ func init() {
 
 		// 用以生成链接
 		"url": config.Env("APP_URL", "http://localhost:3000"),
+
+		// 进程内定时任务（如定时发布文章）的执行间隔，单位为秒，为 0 时不自动发布定时文章
+		"scheduler_interval": config.Env("APP_SCHEDULER_INTERVAL", 60),
 	})
 }
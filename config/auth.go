@@ -0,0 +1,26 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("auth", config.StrMap{
+
+		// 新注册用户的角色：admin、editor、author 或 reader
+		"default_role": config.Env("AUTH_DEFAULT_ROLE", "author"),
+
+		// 管理员邮箱，启动时将该邮箱对应的已注册用户设为管理员，用于指定第一个管理员
+		"admin_email": config.Env("AUTH_ADMIN_EMAIL", ""),
+
+		// 重置密码链接的有效时间，单位为分钟
+		"password_reset_expire": config.Env("AUTH_PASSWORD_RESET_EXPIRE", 60),
+
+		// 邮箱验证链接的有效时间，单位为分钟
+		"verify_expire": config.Env("AUTH_VERIFY_EXPIRE", 1440),
+
+		// 重发验证邮件的最短间隔，单位为秒
+		"verify_resend_interval": config.Env("AUTH_VERIFY_RESEND_INTERVAL", 60),
+
+		// 「记住我」登录的有效时间，单位为天
+		"remember_expire": config.Env("AUTH_REMEMBER_EXPIRE", 30),
+	})
+}
@@ -0,0 +1,20 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("feed", config.StrMap{
+
+		// 订阅源的说明
+		"description": config.Env("FEED_DESCRIPTION", "GoBlog 最新发布的文章"),
+
+		// 每个订阅源包含的文章数量
+		"limit": config.Env("FEED_LIMIT", 20),
+
+		// 是否输出全文，为 false 时只输出摘要
+		"full_content": config.Env("FEED_FULL_CONTENT", true),
+
+		// 摘要的字数
+		"excerpt_length": config.Env("FEED_EXCERPT_LENGTH", 200),
+	})
+}
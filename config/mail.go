@@ -0,0 +1,24 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("mail", config.StrMap{
+
+		// 邮件驱动，支持 smtp 和 file，file 会将邮件保存到 file_path 目录中
+		"driver": config.Env("MAIL_DRIVER", "file"),
+
+		// SMTP 服务器
+		"host":     config.Env("MAIL_HOST", "localhost"),
+		"port":     config.Env("MAIL_PORT", 25),
+		"username": config.Env("MAIL_USERNAME", ""),
+		"password": config.Env("MAIL_PASSWORD", ""),
+
+		// file 驱动保存邮件的目录
+		"file_path": config.Env("MAIL_FILE_PATH", "storage/mails"),
+
+		// 发件人
+		"from_address": config.Env("MAIL_FROM_ADDRESS", "noreply@example.com"),
+		"from_name":    config.Env("MAIL_FROM_NAME", "GoBlog"),
+	})
+}
@@ -0,0 +1,38 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("oidc", config.StrMap{
+
+		// 外部身份提供方，client_id 为空的提供方不会启用
+		// 设置了 issuer 的提供方通过服务发现获取各端点，并验证 ID Token；
+		// 只支持 OAuth2 的提供方需要设置 auth_url、token_url 和 userinfo_url
+		// redirect_url 为空时使用 APP_URL/auth/oidc/{provider}/callback
+		"providers": map[string]interface{}{
+
+			// 公司统一身份认证
+			"company": map[string]interface{}{
+				"label":         config.Env("OIDC_COMPANY_LABEL", "公司账号"),
+				"issuer":        config.Env("OIDC_COMPANY_ISSUER", ""),
+				"client_id":     config.Env("OIDC_COMPANY_CLIENT_ID", ""),
+				"client_secret": config.Env("OIDC_COMPANY_CLIENT_SECRET", ""),
+				"redirect_url":  config.Env("OIDC_COMPANY_REDIRECT_URL", ""),
+				"scopes":        "openid email profile",
+				// 提供方不返回 email_verified 时，是否认为邮箱已验证
+				"trust_email": config.Env("OIDC_COMPANY_TRUST_EMAIL", false),
+			},
+
+			"github": map[string]interface{}{
+				"label":         "GitHub",
+				"client_id":     config.Env("OIDC_GITHUB_CLIENT_ID", ""),
+				"client_secret": config.Env("OIDC_GITHUB_CLIENT_SECRET", ""),
+				"redirect_url":  config.Env("OIDC_GITHUB_REDIRECT_URL", ""),
+				"auth_url":      "https://github.com/login/oauth/authorize",
+				"token_url":     "https://github.com/login/oauth/access_token",
+				"userinfo_url":  "https://api.github.com/user",
+				"scopes":        "read:user user:email",
+			},
+		},
+	})
+}
@@ -0,0 +1,23 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("seo", config.StrMap{
+
+		// 页面没有单独描述时使用的默认描述
+		"description": config.Env("SEO_DESCRIPTION", "GoBlog 技术博客"),
+
+		// 分享到社交网络时默认显示的图片，可以是相对于站点的路径
+		"image": config.Env("SEO_IMAGE", ""),
+
+		// Twitter 卡片中的站点账号，如 @goblog
+		"twitter": config.Env("SEO_TWITTER", ""),
+
+		// 禁止搜索引擎抓取的路径前缀，逗号分隔，同时用于 robots.txt 和页面的 noindex 标记
+		"disallow": config.Env("SEO_DISALLOW", "/admin,/account,/settings,/auth,/comments,/search,/api/,/graphql"),
+
+		// 每个站点地图最多包含的文章数量，超出后拆分为多个站点地图并生成索引
+		"sitemap_size": config.Env("SEO_SITEMAP_SIZE", 5000),
+	})
+}
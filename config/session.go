@@ -5,10 +5,25 @@ import "goblog/pkg/config"
 func init() {
 	config.Add("session", config.StrMap{
 
-		// 目前只支持 Cookie
+		// 会话驱动：cookie 数据全部保存在 Cookie 中，file 和 database 在服务端保存数据，Cookie 中只有会话 ID
 		"default": config.Env("SESSION_DRIVER", "cookie"),
 
 		// 会话的 Cookie 名称
 		"session_name": config.Env("SESSION_NAME", "goblog-session"),
+
+		// 加密 Cookie 内容的密钥，与签名用的 app.key 分开设置；留空时使用 HKDF 由 app.key 派生
+		"encryption_key": config.Env("SESSION_ENCRYPTION_KEY", ""),
+
+		// 会话从创建起的最长有效时间，也是 Cookie 的有效期，单位为分钟，0 为关闭浏览器后失效
+		"lifetime": config.Env("SESSION_LIFETIME", 10080),
+
+		// 超过多少分钟没有访问，会话失效，0 为不限制
+		"idle_timeout": config.Env("SESSION_IDLE_TIMEOUT", 120),
+
+		// file 驱动的会话文件目录
+		"files": config.Env("SESSION_FILES", "storage/sessions"),
+
+		// 清理过期的服务端会话的间隔，单位为分钟，为 0 时不清理
+		"gc_interval": config.Env("SESSION_GC_INTERVAL", 60),
 	})
 }
@@ -0,0 +1,30 @@
+package config
+
+import "goblog/pkg/config"
+
+func init() {
+	config.Add("throttle", config.StrMap{
+
+		// 失败记录的存储：database 重启后仍然有效，memory 只保存在当前进程中
+		"store": config.Env("THROTTLE_STORE", "database"),
+
+		// 同一账号连续失败多少次以内不限制，之后每次失败需等待的秒数从 base_delay 开始翻倍，最多 max_delay 秒
+		"account_free_attempts": config.Env("THROTTLE_ACCOUNT_FREE_ATTEMPTS", 3),
+
+		// 同一账号连续失败多少次后锁定，锁定的分钟数
+		"account_lockout_attempts": config.Env("THROTTLE_ACCOUNT_LOCKOUT_ATTEMPTS", 10),
+		"account_lockout_minutes":  config.Env("THROTTLE_ACCOUNT_LOCKOUT_MINUTES", 15),
+
+		// 同一 IP 的限制，多个用户可能共用一个 IP，因此比账号宽松
+		"ip_free_attempts":    config.Env("THROTTLE_IP_FREE_ATTEMPTS", 10),
+		"ip_lockout_attempts": config.Env("THROTTLE_IP_LOCKOUT_ATTEMPTS", 50),
+		"ip_lockout_minutes":  config.Env("THROTTLE_IP_LOCKOUT_MINUTES", 60),
+
+		// 退避等待的起始和最长秒数
+		"base_delay": config.Env("THROTTLE_BASE_DELAY", 1),
+		"max_delay":  config.Env("THROTTLE_MAX_DELAY", 300),
+
+		// 距上次失败超过多少分钟后重新计数
+		"decay_minutes": config.Env("THROTTLE_DECAY_MINUTES", 60),
+	})
+}
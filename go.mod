@@ -3,15 +3,23 @@ module goblog
 go 1.13
 
 require (
+	github.com/blevesearch/go-porterstemmer v1.0.3
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/gorilla/mux v1.8.0
+	github.com/gorilla/securecookie v1.1.1
 	github.com/gorilla/sessions v1.2.1
+	github.com/graph-gophers/graphql-go v1.3.0
 	github.com/jinzhu/now v1.1.2 // indirect
+	github.com/microcosm-cc/bluemonday v1.0.27
+	github.com/mozillazg/go-pinyin v0.20.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	github.com/spf13/cast v1.3.1
 	github.com/spf13/viper v1.8.1
 	github.com/stretchr/testify v1.7.0
 	github.com/thedevsaddam/govalidator v1.9.10
-	golang.org/x/crypto v0.0.0-20210812204632-0ba0e8f03122
+	github.com/yuin/goldmark v1.4.13
+	golang.org/x/crypto v0.24.0
 	gorm.io/driver/mysql v1.0.5
+	gorm.io/driver/sqlite v1.1.4
 	gorm.io/gorm v1.21.4
 )
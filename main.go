@@ -17,6 +17,27 @@ func main() {
 	// 初始化 SQL
 	bootstrap.SetupDB()
 
+	// 建立全文搜索索引
+	bootstrap.SetupSearch()
+
+	// 配置会话存储
+	bootstrap.SetupSession()
+
+	// 配置邮件驱动
+	bootstrap.SetupMail()
+
+	// 加载垃圾内容过滤器的训练数据
+	bootstrap.SetupAntispam()
+
+	// 配置登录失败限流
+	bootstrap.SetupThrottle()
+
+	// 注册外部身份提供方
+	bootstrap.SetupOIDC()
+
+	// 启动定时任务
+	bootstrap.SetupScheduler()
+
 	// 初始化路由绑定
 	router := bootstrap.SetupRoute()
 
@@ -0,0 +1,45 @@
+// Package antispam 本地垃圾内容过滤：朴素贝叶斯分类器、蜜罐字段和最短提交时间检查
+package antispam
+
+import (
+	"time"
+)
+
+// Filter 垃圾内容过滤器
+type Filter struct {
+	*Classifier
+	Guard
+
+	// Threshold 垃圾内容概率超过此值时判定为垃圾内容
+	Threshold float64
+}
+
+// Default 默认过滤器，由 bootstrap.SetupAntispam 配置并加载训练数据
+var Default = New(nil)
+
+// New 创建过滤器
+func New(store Store) *Filter {
+	return &Filter{
+		Classifier: NewClassifier(store),
+		Guard: Guard{
+			MinSubmitTime: 3 * time.Second,
+			MaxAge:        24 * time.Hour,
+		},
+		Threshold: 0.9,
+	}
+}
+
+// IsSpam 判断样本是否为垃圾内容
+func (f *Filter) IsSpam(s Sample) bool {
+	return f.SpamProbability(Features(s)) >= f.Threshold
+}
+
+// Train 使用标记过的样本训练分类器
+func (f *Filter) Train(class Class, s Sample) error {
+	return f.Learn(class, Features(s))
+}
+
+// Untrain 撤销之前使用该样本进行的训练
+func (f *Filter) Untrain(class Class, s Sample) error {
+	return f.Unlearn(class, Features(s))
+}
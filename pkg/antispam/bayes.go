@@ -0,0 +1,196 @@
+package antispam
+
+import (
+	"math"
+	"sync"
+)
+
+// Class 样本分类
+type Class string
+
+const (
+	// Spam 垃圾内容
+	Spam Class = "spam"
+	// Ham 正常内容
+	Ham Class = "ham"
+)
+
+// Counts 特征在两类样本中出现的次数
+type Counts struct {
+	Spam uint64
+	Ham  uint64
+}
+
+// add 按分类累加一次
+func (c *Counts) add(class Class) {
+	if class == Spam {
+		c.Spam++
+	} else {
+		c.Ham++
+	}
+}
+
+// sub 按分类减少一次，不会小于 0
+func (c *Counts) sub(class Class) {
+	if class == Spam && c.Spam > 0 {
+		c.Spam--
+	} else if class != Spam && c.Ham > 0 {
+		c.Ham--
+	}
+}
+
+// Store 训练数据的持久化接口
+type Store interface {
+	// Load 读取全部训练数据，docs 为两类样本的数量
+	Load() (docs Counts, features map[string]Counts, err error)
+	// Learn 增量保存一次训练：样本数及每个特征在该分类下的次数各加一
+	Learn(class Class, features []string) error
+	// Unlearn 撤销一次训练：样本数及每个特征在该分类下的次数各减一
+	Unlearn(class Class, features []string) error
+}
+
+// Classifier 朴素贝叶斯分类器，按特征是否出现（伯努利模型）计算垃圾内容的概率
+type Classifier struct {
+	mu       sync.RWMutex
+	docs     Counts
+	features map[string]*Counts
+	store    Store
+
+	// MinDocuments 每类样本至少需要的数量，训练不足时不做判断
+	MinDocuments uint64
+}
+
+// NewClassifier 创建分类器，store 为空时训练数据仅保存在内存中
+func NewClassifier(store Store) *Classifier {
+	return &Classifier{
+		features:     map[string]*Counts{},
+		store:        store,
+		MinDocuments: 5,
+	}
+}
+
+// Load 从持久化存储中读取训练数据，替换当前内存中的数据
+func (c *Classifier) Load() error {
+	if c.store == nil {
+		return nil
+	}
+
+	docs, features, err := c.store.Load()
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docs = docs
+	c.features = make(map[string]*Counts, len(features))
+	for name, counts := range features {
+		counts := counts
+		c.features[name] = &counts
+	}
+	return nil
+}
+
+// Learn 使用一个样本训练分类器，并同步写入持久化存储
+func (c *Classifier) Learn(class Class, features []string) error {
+	features = unique(features)
+
+	if c.store != nil {
+		if err := c.store.Learn(class, features); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docs.add(class)
+	for _, name := range features {
+		counts, ok := c.features[name]
+		if !ok {
+			counts = &Counts{}
+			c.features[name] = counts
+		}
+		counts.add(class)
+	}
+	return nil
+}
+
+// Unlearn 撤销之前使用该样本进行的一次训练，用于样本的分类被更正的情况
+func (c *Classifier) Unlearn(class Class, features []string) error {
+	features = unique(features)
+
+	if c.store != nil {
+		if err := c.store.Unlearn(class, features); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.docs.sub(class)
+	for _, name := range features {
+		if counts, ok := c.features[name]; ok {
+			counts.sub(class)
+		}
+	}
+	return nil
+}
+
+// Ready 两类样本是否都已达到最少训练数量
+func (c *Classifier) Ready() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ready()
+}
+
+func (c *Classifier) ready() bool {
+	return c.docs.Spam >= c.MinDocuments && c.docs.Ham >= c.MinDocuments
+}
+
+// SpamProbability 计算样本为垃圾内容的概率，训练不足时返回 0.5
+// 两类先验概率视为相等，避免训练数据中垃圾样本占多数时误判正常内容；
+// 从未在训练中出现过的特征不参与计算
+func (c *Classifier) SpamProbability(features []string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if !c.ready() {
+		return 0.5
+	}
+
+	spamDocs := float64(c.docs.Spam)
+	hamDocs := float64(c.docs.Ham)
+
+	var logOdds float64
+	for _, name := range unique(features) {
+		counts, ok := c.features[name]
+		if !ok {
+			continue
+		}
+
+		// 拉普拉斯平滑，避免出现概率为 0
+		pSpam := (float64(counts.Spam) + 1) / (spamDocs + 2)
+		pHam := (float64(counts.Ham) + 1) / (hamDocs + 2)
+		logOdds += math.Log(pSpam) - math.Log(pHam)
+	}
+
+	return 1 / (1 + math.Exp(-logOdds))
+}
+
+// unique 去除重复特征，保持原有顺序
+func unique(features []string) []string {
+	seen := make(map[string]bool, len(features))
+	result := make([]string, 0, len(features))
+	for _, name := range features {
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+	}
+	return result
+}
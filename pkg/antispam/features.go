@@ -0,0 +1,81 @@
+package antispam
+
+import (
+	"fmt"
+	"goblog/pkg/search"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// MaxFeatureLength 特征的最大字节数
+const MaxFeatureLength = 100
+
+// Sample 待判断或用于训练的样本
+type Sample struct {
+	Content string
+	Name    string
+	Email   string
+	IP      string
+}
+
+// linkPattern 匹配内容中的链接
+var linkPattern = regexp.MustCompile(`(?i)https?://|www\.`)
+
+// Features 提取样本特征：
+// 内容分词、名称分词（name: 前缀）、链接数量区间、邮箱域名以及 IP 所在网段
+func Features(s Sample) []string {
+	var features []string
+
+	for _, t := range search.Tokenize(s.Content) {
+		features = append(features, t.Term)
+	}
+
+	for _, t := range search.Tokenize(s.Name) {
+		features = append(features, "name:"+t.Term)
+	}
+
+	features = append(features, "links:"+linkBucket(len(linkPattern.FindAllStringIndex(s.Content, -1))))
+
+	if i := strings.LastIndex(s.Email, "@"); i >= 0 && i < len(s.Email)-1 {
+		features = append(features, "domain:"+strings.ToLower(strings.TrimSpace(s.Email[i+1:])))
+	}
+
+	if network := ipNetwork(s.IP); network != "" {
+		features = append(features, "ip:"+network)
+	}
+
+	// 过长的特征多为无意义的长串，不参与统计
+	result := features[:0]
+	for _, name := range unique(features) {
+		if len(name) <= MaxFeatureLength {
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// linkBucket 链接数量分段，使不同数量的链接能共享统计数据
+func linkBucket(n int) string {
+	switch {
+	case n <= 2:
+		return fmt.Sprint(n)
+	case n <= 5:
+		return "3-5"
+	default:
+		return "6+"
+	}
+}
+
+// ipNetwork 返回 IP 所在网段，IPv4 取 /24，IPv6 取 /64
+func ipNetwork(ip string) string {
+	parsed := net.ParseIP(strings.TrimSpace(ip))
+	if parsed == nil {
+		return ""
+	}
+
+	if v4 := parsed.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return parsed.Mask(net.CIDRMask(64, 128)).String() + "/64"
+}
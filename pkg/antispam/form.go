@@ -0,0 +1,99 @@
+package antispam
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HoneypotField 蜜罐字段名，对用户隐藏，机器人往往会自动填写
+	HoneypotField = "website"
+	// TimestampField 表单生成时间字段名，带签名防止伪造
+	TimestampField = "_form_ts"
+)
+
+var (
+	// ErrHoneypot 蜜罐字段被填写
+	ErrHoneypot = errors.New("antispam: honeypot field filled")
+	// ErrInvalidTimestamp 表单时间戳缺失或签名错误
+	ErrInvalidTimestamp = errors.New("antispam: invalid form timestamp")
+	// ErrTooFast 提交速度快于正常用户
+	ErrTooFast = errors.New("antispam: form submitted too fast")
+	// ErrExpired 表单打开时间过长
+	ErrExpired = errors.New("antispam: form expired")
+)
+
+// Guard 表单防护：蜜罐字段和最短提交时间
+type Guard struct {
+	// Key 时间戳签名密钥
+	Key []byte
+	// MinSubmitTime 从打开表单到提交的最短时间
+	MinSubmitTime time.Duration
+	// MaxAge 表单的最长有效时间，为 0 时不限制
+	MaxAge time.Duration
+
+	now func() time.Time
+}
+
+// Fields 生成需放入表单中的隐藏字段
+func (g *Guard) Fields() template.HTML {
+	return template.HTML(fmt.Sprintf(
+		`<div style="position:absolute;left:-10000px;" aria-hidden="true">`+
+			`<input type="text" name="%s" value="" tabindex="-1" autocomplete="off">`+
+			`</div>`+
+			`<input type="hidden" name="%s" value="%s">`,
+		HoneypotField, TimestampField, template.HTMLEscapeString(g.Token())))
+}
+
+// Token 生成带签名的表单时间戳
+func (g *Guard) Token() string {
+	ts := strconv.FormatInt(g.clock().Unix(), 10)
+	return ts + "." + g.sign(ts)
+}
+
+// Check 检查提交的表单，未通过时返回对应错误
+func (g *Guard) Check(r *http.Request) error {
+	if r.PostFormValue(HoneypotField) != "" {
+		return ErrHoneypot
+	}
+
+	parts := strings.SplitN(r.PostFormValue(TimestampField), ".", 2)
+	if len(parts) != 2 || !hmac.Equal([]byte(parts[1]), []byte(g.sign(parts[0]))) {
+		return ErrInvalidTimestamp
+	}
+	ts, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	elapsed := g.clock().Sub(time.Unix(ts, 0))
+	if elapsed < g.MinSubmitTime {
+		return ErrTooFast
+	}
+	if g.MaxAge > 0 && elapsed > g.MaxAge {
+		return ErrExpired
+	}
+
+	return nil
+}
+
+func (g *Guard) sign(value string) string {
+	mac := hmac.New(sha256.New, g.Key)
+	mac.Write([]byte(TimestampField + ":" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (g *Guard) clock() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+	return time.Now()
+}
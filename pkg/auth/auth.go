@@ -1,14 +1,34 @@
 package auth
 
 import (
+	"context"
 	"errors"
 	"goblog/app/models/user"
+	"goblog/app/models/usersession"
+	"goblog/pkg/csrf"
+	"goblog/pkg/route"
 	"goblog/pkg/session"
+	"log"
+	"net/http"
+	"time"
+
 	"gorm.io/gorm"
 )
 
-func _getUID() string {
-	_uid := session.Get("uid")
+// twoFactorTimeout 密码验证通过后，输入两步验证码的有效时间
+const twoFactorTimeout = 5 * time.Minute
+
+var (
+	// ErrInvalidCredentials 账号不存在或密码错误，两种情况使用同一提示，避免暴露已注册的邮箱
+	ErrInvalidCredentials = errors.New("账号不存在或密码错误")
+	// ErrTwoFactorRequired 密码正确，还需要输入两步验证码
+	ErrTwoFactorRequired = errors.New("请输入两步验证码")
+	// ErrTwoFactorExpired 两步验证已超时，需重新登录
+	ErrTwoFactorExpired = errors.New("验证已超时，请重新登录")
+)
+
+func _getUID(r *http.Request) string {
+	_uid := session.Get(r, "uid")
 	uid, ok := _uid.(string)
 	if ok && len(uid) > 0 {
 		return uid
@@ -16,27 +36,108 @@ func _getUID() string {
 	return ""
 }
 
-// User 获取登录用户信息
-func User() user.User {
-	uid := _getUID()
+// requestCache 一次请求中解析出的登录用户和登录设备，由 StartSession 中间件放入请求的 context 中
+type requestCache struct {
+	userLoaded bool
+	user       user.User
+
+	deviceLoaded bool
+	device       usersession.Device
+	hasDevice    bool
+}
+
+// contextKey 缓存在 context 中的键
+type contextKey struct{}
+
+// WithCache 返回携带登录状态缓存的请求，同一请求中只查询一次登录用户和登录设备，在中间件中调用
+func WithCache(r *http.Request) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, &requestCache{}))
+}
+
+// cacheFrom 获取请求的缓存，未经过中间件时返回 nil，每次调用都会查询
+func cacheFrom(r *http.Request) *requestCache {
+	cache, _ := r.Context().Value(contextKey{}).(*requestCache)
+	return cache
+}
+
+// forgetCache 登录和退出后清空缓存，之后重新从会话中解析
+func forgetCache(r *http.Request) {
+	if cache := cacheFrom(r); cache != nil {
+		*cache = requestCache{}
+	}
+}
+
+// User 获取登录用户信息，会话版本与用户不一致（如已重置密码）或登录设备已被退出时视为未登录
+func User(r *http.Request) user.User {
+	cache := cacheFrom(r)
+	if cache == nil {
+		return loadUser(r)
+	}
+	if !cache.userLoaded {
+		cache.user, cache.userLoaded = loadUser(r), true
+	}
+	return cache.user
+}
+
+// loadUser 从数据库读取会话中的登录用户
+func loadUser(r *http.Request) user.User {
+	uid := _getUID(r)
 	if len(uid) > 0 {
 		_user, err := user.Get(uid)
-		if err == nil {
-			return _user
+		if err == nil && _getSessionVersion(r) == _user.SessionVersion {
+			if device, ok := CurrentDevice(r); ok && device.UserID == _user.ID {
+				return _user
+			}
 		}
 	}
 	return user.User{}
 }
 
-// Attempt 尝试登录
-func Attempt(email string, password string) error {
+// CurrentDevice 获取当前会话的登录设备，并更新最后访问时间
+func CurrentDevice(r *http.Request) (usersession.Device, bool) {
+	cache := cacheFrom(r)
+	if cache == nil {
+		return loadDevice(r)
+	}
+	if !cache.deviceLoaded {
+		cache.device, cache.hasDevice = loadDevice(r)
+		cache.deviceLoaded = true
+	}
+	return cache.device, cache.hasDevice
+}
+
+// loadDevice 从数据库读取会话中的登录设备，并更新最后访问时间
+func loadDevice(r *http.Request) (usersession.Device, bool) {
+	deviceToken, _ := session.Get(r, "device_token").(string)
+	if len(deviceToken) == 0 {
+		return usersession.Device{}, false
+	}
+
+	device, err := usersession.GetDevice(deviceToken)
+	if err != nil {
+		return usersession.Device{}, false
+	}
+	if err := device.Touch(route.ClientIP(r)); err != nil {
+		log.Println("更新登录设备失败：", err)
+	}
+	return device, true
+}
+
+// _getSessionVersion 获取登录时写入会话的会话版本
+func _getSessionVersion(r *http.Request) uint64 {
+	version, _ := session.Get(r, "session_version").(uint64)
+	return version
+}
+
+// Attempt 尝试登录，remember 为 true 时发放「记住我」令牌
+func Attempt(w http.ResponseWriter, r *http.Request, email string, password string, remember bool) error {
 	// 1. 根据 Email 获取用户
 	_user, err := user.GetByEmail(email)
 
 	// 2. 如果出现错误
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return errors.New("账号不存在或密码错误")
+			return ErrInvalidCredentials
 		} else {
 			return errors.New("内部错误，请稍后尝试")
 		}
@@ -44,26 +145,132 @@ func Attempt(email string, password string) error {
 
 	// 3. 匹配密码
 	if !_user.ComparePassword(password) {
-		return errors.New("账号不存在或密码错误")
+		return ErrInvalidCredentials
 	}
 
-	// 4. 登录用户，保存会话
-	session.Put("uid", _user.GetStringID())
+	// 4. 检查封禁和两步验证后登录
+	return complete(w, r, _user, remember)
+}
+
+// LoginExternal 已通过外部身份提供方验证的用户登录，同样需要检查封禁和两步验证
+func LoginExternal(w http.ResponseWriter, r *http.Request, _user user.User) error {
+	return complete(w, r, _user, false)
+}
+
+// complete 身份验证通过后完成登录
+func complete(w http.ResponseWriter, r *http.Request, _user user.User, remember bool) error {
+	// 1. 被封禁的用户无法登录
+	if _user.IsBanned() {
+		return errors.New("账号已被封禁")
+	}
+
+	// 2. 启用了两步验证的用户，会话中只记录待验证状态，输入验证码后才算登录
+	if _user.HasTwoFactor() {
+		session.Put(r, "two_factor_uid", _user.GetStringID())
+		session.Put(r, "two_factor_at", time.Now().Unix())
+		session.Put(r, "two_factor_remember", remember)
+		return ErrTwoFactorRequired
+	}
+
+	// 3. 登录用户，保存会话
+	if err := Login(r, _user); err != nil {
+		return errors.New("内部错误，请稍后尝试")
+	}
+
+	// 4. 勾选了「记住我」
+	if remember {
+		if err := Remember(w, r, _user); err != nil {
+			log.Println("发放记住我令牌失败：", err)
+		}
+	}
 
 	return nil
 }
 
-// Login 登录指定用户
-func Login(_user user.User) {
-	session.Put("uid", _user.GetStringID())
+// PendingTwoFactorUser 获取密码验证通过、等待输入两步验证码的用户，超过有效期后需重新登录
+func PendingTwoFactorUser(r *http.Request) (user.User, bool) {
+	uid, _ := session.Get(r, "two_factor_uid").(string)
+	at, _ := session.Get(r, "two_factor_at").(int64)
+	if len(uid) == 0 || time.Since(time.Unix(at, 0)) > twoFactorTimeout {
+		return user.User{}, false
+	}
+
+	_user, err := user.Get(uid)
+	if err != nil || !_user.HasTwoFactor() {
+		return user.User{}, false
+	}
+	return _user, true
 }
 
-// Logout 退出用户
-func Logout() {
-	session.Forget("uid")
+// AttemptTwoFactor 检查两步验证码或恢复码，通过后完成登录
+func AttemptTwoFactor(w http.ResponseWriter, r *http.Request, code string) error {
+	_user, ok := PendingTwoFactorUser(r)
+	if !ok {
+		return ErrTwoFactorExpired
+	}
+
+	if err := _user.VerifyTwoFactor(code); err != nil {
+		return err
+	}
+
+	remember, _ := session.Get(r, "two_factor_remember").(bool)
+	forgetTwoFactor(r)
+	if err := Login(r, _user); err != nil {
+		return err
+	}
+
+	if remember {
+		if err := Remember(w, r, _user); err != nil {
+			log.Println("发放记住我令牌失败：", err)
+		}
+	}
+	return nil
+}
+
+// forgetTwoFactor 清除待验证状态
+func forgetTwoFactor(r *http.Request) {
+	session.Forget(r, "two_factor_uid")
+	session.Forget(r, "two_factor_at")
+	session.Forget(r, "two_factor_remember")
+}
+
+// Login 登录指定用户，登录前更换会话 ID，防止会话固定攻击；同时记录登录设备，用于在其他设备上退出
+func Login(r *http.Request, _user user.User) error {
+	session.Regenerate(r)
+	csrf.Rotate(r)
+
+	deviceToken, err := usersession.CreateDevice(_user.ID, route.ClientIP(r), r.UserAgent())
+	if err != nil {
+		return err
+	}
+
+	session.Put(r, "device_token", deviceToken)
+	session.Put(r, "session_version", _user.SessionVersion)
+	session.Put(r, "uid", _user.GetStringID())
+	forgetCache(r)
+	return nil
+}
+
+// Logout 退出用户，同时删除当前浏览器的「记住我」令牌，退出后更换会话 ID
+func Logout(w http.ResponseWriter, r *http.Request) {
+	forgetRemember(w, r)
+
+	if device, ok := CurrentDevice(r); ok {
+		if err := device.Revoke(); err != nil {
+			log.Println("删除登录设备失败：", err)
+		}
+	}
+
+	session.Forget(r, "device_token")
+	session.Forget(r, "uid")
+	session.Forget(r, "session_version")
+	forgetTwoFactor(r)
+	session.Regenerate(r)
+	csrf.Rotate(r)
+	forgetCache(r)
 }
 
 // Check 检测是否登录
-func Check() bool {
-	return len(_getUID()) > 0
+func Check(r *http.Request) bool {
+	return User(r).ID > 0
 }
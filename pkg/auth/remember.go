@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"goblog/app/models/user"
+	"goblog/app/models/usersession"
+	"goblog/pkg/config"
+	"goblog/pkg/types"
+	"log"
+	"net/http"
+	"time"
+)
+
+// rememberCookie 「记住我」Cookie 的名称
+const rememberCookie = "remember_token"
+
+// Remember 为当前登录设备发放「记住我」令牌，会话失效后可自动重新登录
+func Remember(w http.ResponseWriter, r *http.Request, _user user.User) error {
+	device, _ := CurrentDevice(r)
+
+	value, err := usersession.CreateRememberToken(_user.ID, device.ID, rememberTTL())
+	if err != nil {
+		return err
+	}
+	setRememberCookie(w, value)
+	return nil
+}
+
+// LoginFromRemember 会话中没有登录用户时，使用「记住我」Cookie 自动登录，并更换令牌
+func LoginFromRemember(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(rememberCookie)
+	if err != nil || Check(r) {
+		return
+	}
+
+	// 1. 检查并更换令牌
+	rt, value, err := usersession.ConsumeRememberToken(cookie.Value, rememberTTL())
+	if err != nil {
+		if err == usersession.ErrRememberTokenTheft {
+			log.Printf("用户 %d 的记住我令牌可能已被盗用，已退出所有设备", rt.UserID)
+		} else if err != usersession.ErrInvalidRememberToken {
+			log.Println("读取记住我令牌失败：", err)
+			return
+		}
+		forgetRememberCookie(w)
+		return
+	}
+
+	// 2. 被封禁或已删除的用户不再自动登录
+	_user, err := user.Get(types.Uint64ToString(rt.UserID))
+	if err != nil || _user.IsBanned() {
+		if err := usersession.DeleteRememberToken(cookie.Value); err != nil {
+			log.Println("删除记住我令牌失败：", err)
+		}
+		forgetRememberCookie(w)
+		return
+	}
+
+	// 3. 登录，令牌关联到新的登录设备，旧设备的会话已失效，一并删除
+	oldDeviceID := rt.DeviceID
+	if err := Login(r, _user); err != nil {
+		log.Println("记住我自动登录失败：", err)
+		return
+	}
+	device, _ := CurrentDevice(r)
+	if err := rt.AttachDevice(device.ID); err != nil {
+		log.Println("更新记住我令牌失败：", err)
+	}
+	if oldDeviceID > 0 && oldDeviceID != device.ID {
+		if _, err := usersession.RevokeDevice(_user.ID, oldDeviceID); err != nil {
+			log.Println("删除旧的登录设备失败：", err)
+		}
+	}
+	if len(value) > 0 {
+		setRememberCookie(w, value)
+	}
+}
+
+// forgetRemember 退出时删除当前浏览器的「记住我」令牌和 Cookie
+func forgetRemember(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(rememberCookie)
+	if err != nil {
+		return
+	}
+	if err := usersession.DeleteRememberToken(cookie.Value); err != nil {
+		log.Println("删除记住我令牌失败：", err)
+	}
+	forgetRememberCookie(w)
+}
+
+// setRememberCookie 写入「记住我」Cookie
+func setRememberCookie(w http.ResponseWriter, value string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     rememberCookie,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(rememberTTL() / time.Second),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// forgetRememberCookie 删除「记住我」Cookie
+func forgetRememberCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     rememberCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// rememberTTL 「记住我」令牌的有效时间
+func rememberTTL() time.Duration {
+	return time.Duration(config.GetInt("auth.remember_expire")) * 24 * time.Hour
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"fmt"
+	"goblog/app/models/loginattempt"
+	"goblog/app/models/user"
+	"goblog/pkg/throttle"
+	"log"
+	"strings"
+	"time"
+)
+
+var (
+	// AccountLimiter 按账号（邮箱）限制登录失败，由 bootstrap.SetupThrottle 配置
+	AccountLimiter = throttle.New(throttle.NewMemoryStore(), throttle.Policy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAttempts: 10,
+		LockoutDuration: 15 * time.Minute,
+		Decay:           time.Hour,
+	})
+
+	// IPLimiter 按 IP 限制登录失败
+	IPLimiter = throttle.New(throttle.NewMemoryStore(), throttle.Policy{
+		FreeAttempts:    10,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAttempts: 50,
+		LockoutDuration: time.Hour,
+		Decay:           time.Hour,
+	})
+)
+
+// ThrottledError 登录失败次数过多，需等待后再试
+type ThrottledError struct {
+	Wait time.Duration
+}
+
+// Error 错误提示
+func (e ThrottledError) Error() string {
+	if e.Wait > time.Minute {
+		return fmt.Sprintf("登录失败次数过多，请 %d 分钟后再试", int((e.Wait+time.Minute-1)/time.Minute))
+	}
+	return fmt.Sprintf("登录失败次数过多，请 %d 秒后再试", int((e.Wait+time.Second-1)/time.Second))
+}
+
+// Throttled 账号或 IP 因多次登录失败需要等待时，返回 ThrottledError
+func Throttled(email, ip string) error {
+	accountWait, err := AccountLimiter.Wait(accountKey(email))
+	if err != nil {
+		log.Println("读取登录限流记录失败：", err)
+	}
+	ipWait, err := IPLimiter.Wait(ipKey(ip))
+	if err != nil {
+		log.Println("读取登录限流记录失败：", err)
+	}
+
+	if ipWait > accountWait {
+		accountWait = ipWait
+	}
+	if accountWait > 0 {
+		return ThrottledError{Wait: accountWait}
+	}
+	return nil
+}
+
+// Failed 记录一次登录失败：写入审计日志，锁定期间的尝试只记日志不计数，避免他人恶意延长锁定时间
+// 失败后需要等待时返回 ThrottledError
+func Failed(email, ip, userAgent, reason string) error {
+	attempt := loginattempt.LoginAttempt{
+		Email:     strings.TrimSpace(email),
+		IP:        ip,
+		UserAgent: userAgent,
+		Reason:    reason,
+	}
+	if _user, err := user.GetByEmail(attempt.Email); err == nil {
+		attempt.UserID = _user.ID
+	}
+	if err := attempt.Create(); err != nil {
+		log.Println("写入登录失败日志失败：", err)
+	}
+
+	if reason == loginattempt.ReasonLocked {
+		return nil
+	}
+
+	accountWait, err := AccountLimiter.Fail(accountKey(email))
+	if err != nil {
+		log.Println("写入登录限流记录失败：", err)
+	}
+	ipWait, err := IPLimiter.Fail(ipKey(ip))
+	if err != nil {
+		log.Println("写入登录限流记录失败：", err)
+	}
+
+	wait := accountWait
+	if ipWait > wait {
+		wait = ipWait
+	}
+	if wait > 0 {
+		return ThrottledError{Wait: wait}
+	}
+	return nil
+}
+
+// Succeeded 登录成功，清除账号的失败记录
+// IP 的记录保留，避免攻击者用自己的账号登录来重置对其他账号的猜测次数
+func Succeeded(email string) {
+	if err := AccountLimiter.Reset(accountKey(email)); err != nil {
+		log.Println("清除登录限流记录失败：", err)
+	}
+}
+
+// accountKey 账号的限流键，邮箱不区分大小写
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// ipKey IP 的限流键
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"goblog/app/models/accesstoken"
+	"goblog/app/models/user"
+	"goblog/pkg/types"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ErrInvalidToken 没有提供令牌，或令牌不存在、已过期、用户已被封禁
+var ErrInvalidToken = errors.New("访问令牌无效或已过期")
+
+// tokenContextKey 令牌认证的结果在 context 中的键
+type tokenContextKey struct{}
+
+// tokenAuth 令牌认证的结果
+type tokenAuth struct {
+	token accesstoken.AccessToken
+	user  user.User
+}
+
+// AuthenticateToken 使用请求头 Authorization: Bearer <令牌> 认证，返回携带令牌和用户的请求
+func AuthenticateToken(r *http.Request) (*http.Request, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return r, ErrInvalidToken
+	}
+
+	t, err := accesstoken.FindByToken(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return r, ErrInvalidToken
+	}
+	_user, err := user.Get(types.Uint64ToString(t.UserID))
+	if err != nil || _user.IsBanned() {
+		return r, ErrInvalidToken
+	}
+
+	if err := t.Touch(); err != nil {
+		log.Println("更新令牌使用时间失败：", err)
+	}
+	return r.WithContext(context.WithValue(r.Context(), tokenContextKey{}, tokenAuth{t, _user})), nil
+}
+
+// TokenUser 令牌认证的用户，未经过令牌认证时返回空用户
+func TokenUser(r *http.Request) user.User {
+	a, _ := r.Context().Value(tokenContextKey{}).(tokenAuth)
+	return a.user
+}
+
+// Token 当前请求使用的令牌
+func Token(r *http.Request) accesstoken.AccessToken {
+	a, _ := r.Context().Value(tokenContextKey{}).(tokenAuth)
+	return a.token
+}
@@ -0,0 +1,54 @@
+// Package csrf 跨站请求伪造防护：每个会话一个令牌，提交表单时需带上令牌
+package csrf
+
+import (
+	"crypto/subtle"
+	"goblog/pkg/session"
+	"goblog/pkg/token"
+	"html/template"
+	"net/http"
+)
+
+const (
+	// FieldName 表单中令牌的字段名
+	FieldName = "_csrf"
+	// HeaderName 使用 JavaScript 提交时可以放在请求头中
+	HeaderName = "X-CSRF-Token"
+
+	// sessionKey 令牌在会话中的键
+	sessionKey = "_csrf_token"
+)
+
+// Token 获取当前会话的令牌，不存在时生成，需在输出响应内容之前调用
+func Token(r *http.Request) string {
+	t, _ := session.Get(r, sessionKey).(string)
+	if len(t) == 0 {
+		t = token.Generate()
+		session.Put(r, sessionKey, t)
+	}
+	return t
+}
+
+// Field 包含令牌的隐藏表单字段
+func Field(r *http.Request) template.HTML {
+	return template.HTML(`<input type="hidden" name="` + FieldName + `" value="` + template.HTMLEscapeString(Token(r)) + `">`)
+}
+
+// Verify 检查请求中的令牌是否与会话中的一致
+func Verify(r *http.Request) bool {
+	expected, _ := session.Get(r, sessionKey).(string)
+	if len(expected) == 0 {
+		return false
+	}
+
+	actual := r.Header.Get(HeaderName)
+	if len(actual) == 0 {
+		actual = r.PostFormValue(FieldName)
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(actual)) == 1
+}
+
+// Rotate 更换令牌，登录和退出时调用，避免登录前泄露的令牌在登录后继续有效
+func Rotate(r *http.Request) {
+	session.Put(r, sessionKey, token.Generate())
+}
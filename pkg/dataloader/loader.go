@@ -0,0 +1,68 @@
+// Package dataloader 在一次请求内按 ID 批量加载数据，避免逐条查询（N+1）
+package dataloader
+
+import "sync"
+
+// BatchFunc 一次查询 ids 对应的数据，返回 ID 到数据的映射，不存在的 ID 不需要出现在结果中
+type BatchFunc func(ids []uint64) (map[uint64]interface{}, error)
+
+// Loader 按 ID 加载数据并缓存。列表中的数据先通过 Queue 登记需要的 ID，
+// 第一次 Load 时将已登记的 ID 合并为一次查询。每个请求应使用新的 Loader，缓存只在请求内有效
+type Loader struct {
+	fetch BatchFunc
+
+	mu      sync.Mutex
+	queued  map[uint64]bool
+	results map[uint64]interface{}
+}
+
+// New 创建 Loader
+func New(fetch BatchFunc) *Loader {
+	return &Loader{
+		fetch:   fetch,
+		queued:  map[uint64]bool{},
+		results: map[uint64]interface{}{},
+	}
+}
+
+// Queue 登记稍后需要加载的 ID，此时不查询
+func (l *Loader) Queue(ids ...uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := l.results[id]; !ok {
+			l.queued[id] = true
+		}
+	}
+}
+
+// Load 加载 id 对应的数据，未缓存时连同已登记的 ID 一起查询，数据不存在时返回 nil
+func (l *Loader) Load(id uint64) (interface{}, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if v, ok := l.results[id]; ok {
+		return v, nil
+	}
+
+	// 1. 合并已登记的 ID
+	l.queued[id] = true
+	ids := make([]uint64, 0, len(l.queued))
+	for queued := range l.queued {
+		ids = append(ids, queued)
+	}
+
+	// 2. 查询，失败时保留登记的 ID，下次加载时重试
+	found, err := l.fetch(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 缓存结果，不存在的 ID 缓存为 nil，避免重复查询
+	for _, queued := range ids {
+		l.results[queued] = found[queued]
+		delete(l.queued, queued)
+	}
+	return l.results[id], nil
+}
@@ -0,0 +1,256 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Op 差异片段的类型
+type Op string
+
+const (
+	// Equal 两边相同
+	Equal Op = "equal"
+	// Insert 新版本中新增
+	Insert Op = "insert"
+	// Delete 新版本中删除
+	Delete Op = "delete"
+)
+
+// Chunk 差异片段
+type Chunk struct {
+	Op   Op
+	Text string
+}
+
+// IsEqual 片段在两个版本中相同
+func (c Chunk) IsEqual() bool {
+	return c.Op == Equal
+}
+
+// IsInsert 片段为新增内容
+func (c Chunk) IsInsert() bool {
+	return c.Op == Insert
+}
+
+// IsDelete 片段为删除内容
+func (c Chunk) IsDelete() bool {
+	return c.Op == Delete
+}
+
+// Lines 按行比较两段文本，每个片段为一行（不含换行符）
+func Lines(a, b string) []Chunk {
+	return Tokens(SplitLines(a), SplitLines(b))
+}
+
+// Words 按词比较两段文本，中日韩文字逐字比较，相邻的同类片段会被合并
+func Words(a, b string) []Chunk {
+	return merge(Tokens(SplitWords(a), SplitWords(b)))
+}
+
+// SplitLines 将文本拆分为行，兼容 \r\n 换行
+func SplitLines(s string) []string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+// SplitWords 将文本拆分为词：
+// 中日韩文字每个字单独成词；字母和数字连续成词；连续空白成一个词；其他符号单独成词
+func SplitWords(s string) []string {
+	var words []string
+	var current []rune
+	var currentKind int
+
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, string(current))
+			current = current[:0]
+		}
+	}
+
+	for _, r := range s {
+		k := kind(r)
+		if k == kindCJK || k == kindSymbol || k != currentKind {
+			flush()
+		}
+		current = append(current, r)
+		currentKind = k
+	}
+	flush()
+
+	return words
+}
+
+const (
+	kindWord = iota + 1
+	kindSpace
+	kindCJK
+	kindSymbol
+)
+
+func kind(r rune) int {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+		return kindCJK
+	case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		return kindWord
+	case unicode.IsSpace(r):
+		return kindSpace
+	default:
+		return kindSymbol
+	}
+}
+
+// Tokens 使用 Myers 差分算法比较两组词元，返回逐个词元的差异片段
+// 采用线性空间的变体：每次找到最短编辑路径的中点后分治，内存占用与输入长度成正比
+func Tokens(a, b []string) []Chunk {
+	return compare(a, b, nil)
+}
+
+// compare 比较 a 和 b，差异片段追加到 chunks 后返回
+func compare(a, b []string, chunks []Chunk) []Chunk {
+
+	// 1. 去除公共前缀和后缀，减少计算量，也保证分治时每一段都有差异
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, t := range a[:prefix] {
+		chunks = append(chunks, Chunk{Equal, t})
+	}
+	tail := a[len(a)-suffix:]
+	a, b = a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+
+	// 2. 一边为空时全部为新增或删除，否则在中点处分为两段分别比较
+	switch x, y := bisect(a, b); {
+	case len(a) == 0 || len(b) == 0 || x < 0:
+		for _, t := range a {
+			chunks = append(chunks, Chunk{Delete, t})
+		}
+		for _, t := range b {
+			chunks = append(chunks, Chunk{Insert, t})
+		}
+	default:
+		chunks = compare(a[:x], b[:y], chunks)
+		chunks = compare(a[x:], b[y:], chunks)
+	}
+
+	for _, t := range tail {
+		chunks = append(chunks, Chunk{Equal, t})
+	}
+	return chunks
+}
+
+// maxEdits 单次搜索的最大编辑距离，差异过大的两段内容直接视为整体删除和新增，避免耗时过长
+const maxEdits = 5000
+
+// bisect 同时从两端搜索最短编辑路径，返回两个方向相遇的位置，
+// a 和 b 没有相同词元或编辑距离超过 maxEdits 时返回 -1
+// 参见 E. Myers《An O(ND) Difference Algorithm and Its Variations》第 4.2 节
+func bisect(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return -1, -1
+	}
+
+	// v1、v2 分别保存正向和反向搜索时，对角线 k 上能到达的最远 x
+	maxD := (n + m + 1) / 2
+	if maxD > maxEdits {
+		maxD = maxEdits
+	}
+	offset := maxD
+	v1 := make([]int, 2*maxD+2)
+	v2 := make([]int, 2*maxD+2)
+	for i := range v1 {
+		v1[i], v2[i] = -1, -1
+	}
+	v1[offset+1], v2[offset+1] = 0, 0
+
+	// 长度差为奇数时在正向搜索中检查相遇，否则在反向搜索中检查
+	delta := n - m
+	front := delta%2 != 0
+	// 越过边界的对角线不再搜索
+	k1start, k1end, k2start, k2end := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k1 := -d + k1start; k1 <= d-k1end; k1 += 2 {
+			i := offset + k1
+			var x1 int
+			if k1 == -d || (k1 != d && v1[i-1] < v1[i+1]) {
+				x1 = v1[i+1]
+			} else {
+				x1 = v1[i-1] + 1
+			}
+			y1 := x1 - k1
+			for x1 < n && y1 < m && a[x1] == b[y1] {
+				x1++
+				y1++
+			}
+			v1[i] = x1
+
+			switch {
+			case x1 > n:
+				k1end += 2
+			case y1 > m:
+				k1start += 2
+			case front:
+				if j := offset + delta - k1; j >= 0 && j < len(v2) && v2[j] != -1 && x1 >= n-v2[j] {
+					return x1, y1
+				}
+			}
+		}
+
+		for k2 := -d + k2start; k2 <= d-k2end; k2 += 2 {
+			i := offset + k2
+			var x2 int
+			if k2 == -d || (k2 != d && v2[i-1] < v2[i+1]) {
+				x2 = v2[i+1]
+			} else {
+				x2 = v2[i-1] + 1
+			}
+			y2 := x2 - k2
+			for x2 < n && y2 < m && a[n-x2-1] == b[m-y2-1] {
+				x2++
+				y2++
+			}
+			v2[i] = x2
+
+			switch {
+			case x2 > n:
+				k2end += 2
+			case y2 > m:
+				k2start += 2
+			case !front:
+				if j := offset + delta - k2; j >= 0 && j < len(v1) && v1[j] != -1 {
+					x1 := v1[j]
+					if x1 >= n-x2 {
+						return x1, x1 - (j - offset)
+					}
+				}
+			}
+		}
+	}
+
+	return -1, -1
+}
+
+// merge 合并相邻的同类片段
+func merge(chunks []Chunk) []Chunk {
+	var merged []Chunk
+	for _, c := range chunks {
+		if n := len(merged); n > 0 && merged[n-1].Op == c.Op {
+			merged[n-1].Text += c.Text
+			continue
+		}
+		merged = append(merged, c)
+	}
+	return merged
+}
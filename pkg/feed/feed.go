@@ -0,0 +1,86 @@
+// Package feed 生成 RSS 2.0、Atom 和 JSON Feed 格式的订阅源
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"time"
+)
+
+// Feed 订阅源
+type Feed struct {
+	Title       string
+	Description string
+	// Link 订阅源对应的网页
+	Link string
+	// FeedURL 订阅源自身的地址
+	FeedURL  string
+	Language string
+	// Updated 最近一篇文章的更新时间
+	Updated time.Time
+	Items   []Item
+}
+
+// Item 订阅源中的一篇文章
+type Item struct {
+	// ID 唯一标识，不随标题等内容变化
+	ID    string
+	Title string
+	Link  string
+	// Summary 纯文本摘要
+	Summary string
+	// Content HTML 全文，为空时只输出摘要
+	Content    string
+	AuthorName string
+	AuthorURL  string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+// 订阅源的格式
+const (
+	FormatRSS  = "rss"
+	FormatAtom = "atom"
+	FormatJSON = "json"
+)
+
+// contentTypes 各格式的 Content-Type
+var contentTypes = map[string]string{
+	FormatRSS:  "application/rss+xml; charset=utf-8",
+	FormatAtom: "application/atom+xml; charset=utf-8",
+	FormatJSON: "application/feed+json; charset=utf-8",
+}
+
+// Encode 按格式生成订阅源，不支持的格式按 RSS 生成
+func (f Feed) Encode(format string) ([]byte, error) {
+	switch format {
+	case FormatAtom:
+		return f.Atom()
+	case FormatJSON:
+		return f.JSON()
+	}
+	return f.RSS()
+}
+
+// Serve 输出订阅源，支持条件请求：ETag 为内容的哈希值，Last-Modified 为 Updated，
+// 客户端携带的 If-None-Match 或 If-Modified-Since 未变化时返回 304
+func (f Feed) Serve(w http.ResponseWriter, r *http.Request, format string) error {
+	body, err := f.Encode(format)
+	if err != nil {
+		return err
+	}
+
+	contentType, ok := contentTypes[format]
+	if !ok {
+		contentType = contentTypes[FormatRSS]
+	}
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+
+	http.ServeContent(w, r, "", f.Updated, bytes.NewReader(body))
+	return nil
+}
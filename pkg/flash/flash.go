@@ -3,6 +3,7 @@ package flash
 import (
 	"encoding/gob"
 	"goblog/pkg/session"
+	"net/http"
 )
 
 // Flashes Flash 消息数组类型，用以在会话中存储 map
@@ -18,42 +19,41 @@ func init() {
 }
 
 // Info 添加 Info 类型的消息提示
-func Info(message string) {
-	addFlash("info", message)
+func Info(r *http.Request, message string) {
+	addFlash(r, "info", message)
 }
 
 // Warning 添加 Warning 类型的消息提示
-func Warning(message string) {
-	addFlash("warning", message)
+func Warning(r *http.Request, message string) {
+	addFlash(r, "warning", message)
 }
 
 // Success 添加 Success 类型的消息提示
-func Success(message string) {
-	addFlash("success", message)
+func Success(r *http.Request, message string) {
+	addFlash(r, "success", message)
 }
 
 // Danger 添加 Danger 类型的消息提示
-func Danger(message string) {
-	addFlash("danger", message)
+func Danger(r *http.Request, message string) {
+	addFlash(r, "danger", message)
 }
 
 // All 获取所有消息
-func All() Flashes {
-	val := session.Get(flashKey)
+func All(r *http.Request) Flashes {
+	val := session.Get(r, flashKey)
 	// 读取是必须做类型检测
 	flashMessages, ok := val.(Flashes)
 	if !ok {
 		return nil
 	}
 	// 读取即销毁，直接删除
-	session.Forget(flashKey)
+	session.Forget(r, flashKey)
 	return flashMessages
 }
 
 // 私有方法，新增一条提示
-func addFlash(key string, message string) {
+func addFlash(r *http.Request, key string, message string) {
 	flashes := Flashes{}
 	flashes[key] = message
-	session.Put(flashKey, flashes)
-	session.Save()
+	session.Put(r, flashKey, flashes)
 }
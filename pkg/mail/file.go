@@ -0,0 +1,23 @@
+package mail
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer 将邮件保存为 .eml 文件，用于开发和测试环境，无需邮件服务器
+type FileMailer struct {
+	Dir string
+}
+
+// Send 将邮件写入 Dir 目录，文件名以发送时间开头，便于按时间排序
+func (m *FileMailer) Send(msg Message) error {
+	if err := os.MkdirAll(m.Dir, 0755); err != nil {
+		return err
+	}
+
+	name := time.Now().Format("20060102-150405.000000000") + "-" + randomID()[:8] + ".eml"
+	return ioutil.WriteFile(filepath.Join(m.Dir, name), msg.Bytes(), 0644)
+}
@@ -0,0 +1,115 @@
+// Package mail 发送邮件，支持 SMTP 和文件两种驱动
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+// Message 邮件内容，HTML 为空时只发送纯文本
+type Message struct {
+	From    mail.Address
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer 邮件驱动
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// Default 默认驱动，由 bootstrap.SetupMail 根据配置设置
+var Default Mailer = &FileMailer{Dir: "storage/mails"}
+
+// From 默认发件人，Message 未设置发件人时使用
+var From = mail.Address{Name: "GoBlog", Address: "noreply@example.com"}
+
+// Send 使用默认驱动发送邮件
+func Send(msg Message) error {
+	return Default.Send(msg)
+}
+
+// Bytes 生成 RFC 5322 格式的邮件原文
+func (msg Message) Bytes() []byte {
+	from := msg.From
+	if from.Address == "" {
+		from = From
+	}
+
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+	}
+
+	header("From", from.String())
+	header("To", strings.Join(msg.To, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	header("Message-ID", "<"+randomID()+"@"+domainOf(from.Address)+">")
+	header("MIME-Version", "1.0")
+
+	if msg.HTML == "" {
+		header("Content-Type", "text/plain; charset=utf-8")
+		header("Content-Transfer-Encoding", "quoted-printable")
+		buf.WriteString("\r\n")
+		writeQuotedPrintable(&buf, msg.Text)
+		return buf.Bytes()
+	}
+
+	// 同时包含纯文本和 HTML 时使用 multipart/alternative
+	body := multipart.NewWriter(&buf)
+	header("Content-Type", "multipart/alternative; boundary="+body.Boundary())
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		w, _ := body.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		writeQuotedPrintable(w, part.content)
+	}
+	body.Close()
+
+	return buf.Bytes()
+}
+
+// Sender 发件人地址
+func (msg Message) Sender() string {
+	if msg.From.Address == "" {
+		return From.Address
+	}
+	return msg.From.Address
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, s string) {
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(s))
+	qp.Close()
+}
+
+func randomID() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func domainOf(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+	return "localhost"
+}
@@ -0,0 +1,68 @@
+package markdown
+
+import (
+	"bytes"
+	"goblog/pkg/logger"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// md Markdown 解析器，支持 CommonMark 及 GFM 扩展（表格、任务列表、删除线、自动链接）
+var md = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(
+		parser.WithAutoHeadingID(),
+	),
+)
+
+// strict 去除所有标签的净化策略，用于提取纯文本
+var strict = bluemonday.StrictPolicy()
+
+// blockEnd 块级元素的结束标签和换行
+var blockEnd = regexp.MustCompile(`</(p|h[1-6]|li|pre|blockquote|tr|td|th)>|<br\s*/?>`)
+
+// policy HTML 白名单净化策略，在 UGC 策略的基础上允许代码高亮的 class 及任务列表的复选框
+var policy = newPolicy()
+
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+
+	// 围栏代码块的语言标识，如 <code class="language-go">
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+
+	// GFM 任务列表，如 <input checked="" disabled="" type="checkbox">
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+
+	return p
+}
+
+// Render 将 Markdown 文本渲染为净化后的 HTML
+func Render(source string) string {
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(source), &buf); err != nil {
+		logger.LogError(err)
+		return ""
+	}
+
+	return Sanitize(buf.String())
+}
+
+// Sanitize 按白名单过滤 HTML，去除脚本、事件属性等危险内容
+func Sanitize(html string) string {
+	return policy.Sanitize(html)
+}
+
+// PlainText 去除 HTML 中的所有标签，返回合并空白后的纯文本，用于搜索、摘要等
+func PlainText(htmlText string) string {
+	// 块级元素结束处补充空白，避免相邻段落的文字粘连
+	htmlText = blockEnd.ReplaceAllString(htmlText, "$0 ")
+	text := html.UnescapeString(strict.Sanitize(htmlText))
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,156 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// leeway 校验有效期时允许的时钟误差
+const leeway = time.Minute
+
+// idTokenHeader ID Token 的头部
+type idTokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// verifyIDToken 校验 ID Token 的 RS256 签名、签发者、受众、有效期和 nonce，返回其中的身份信息
+func (p *Provider) verifyIDToken(idToken, nonce string) (Claims, error) {
+	// 1. 解析 JWT 的三个部分
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return Claims{}, ErrInvalidIDToken
+	}
+
+	var header idTokenHeader
+	if err := decodeSegment(parts[0], &header); err != nil || header.Alg != "RS256" {
+		return Claims{}, ErrInvalidIDToken
+	}
+
+	// 2. 校验签名，找不到 kid 对应的公钥时重新获取一次，以支持提供方轮换密钥
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Claims{}, ErrInvalidIDToken
+	}
+	key, err := p.publicKey(header.Kid)
+	if err != nil {
+		return Claims{}, err
+	}
+	hashed := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature) != nil {
+		return Claims{}, ErrInvalidIDToken
+	}
+
+	// 3. 校验声明
+	var raw map[string]interface{}
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return Claims{}, ErrInvalidIDToken
+	}
+	now := time.Now()
+	if claimString(raw, "iss") != p.Issuer ||
+		!hasAudience(raw["aud"], p.ClientID) ||
+		!claimTimeAfter(raw, "exp", now.Add(-leeway)) ||
+		claimTimeAfter(raw, "iat", now.Add(leeway)) ||
+		claimString(raw, "nonce") != nonce || len(nonce) == 0 {
+		return Claims{}, ErrInvalidIDToken
+	}
+
+	claims := p.parseClaims(raw)
+	if len(claims.Subject) == 0 {
+		return Claims{}, ErrInvalidIDToken
+	}
+	return claims, nil
+}
+
+// publicKey 获取 kid 对应的 RSA 公钥
+func (p *Provider) publicKey(kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	keys, err := p.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrInvalidIDToken
+}
+
+// fetchKeys 从 JWKS 端点获取签名公钥，只保留 RSA 公钥
+func (p *Provider) fetchKeys() (map[string]*rsa.PublicKey, error) {
+	var jwks struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := p.getJSON(p.JWKSURL, "", &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range jwks.Keys {
+		if k.Kty != "RSA" || (len(k.Use) > 0 && k.Use != "sig") {
+			continue
+		}
+		n, errN := base64.RawURLEncoding.DecodeString(k.N)
+		e, errE := base64.RawURLEncoding.DecodeString(k.E)
+		if errN != nil || errE != nil || len(e) > 4 {
+			continue
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	return keys, nil
+}
+
+// decodeSegment 解码 JWT 中 Base64URL 编码的 JSON
+func decodeSegment(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// hasAudience aud 可以是字符串或字符串数组
+func hasAudience(aud interface{}, clientID string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == clientID
+	case []interface{}:
+		for _, a := range v {
+			if a == clientID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// claimTimeAfter 时间戳声明是否晚于 t，声明不存在时返回 false
+func claimTimeAfter(raw map[string]interface{}, key string, t time.Time) bool {
+	v, ok := raw[key].(float64)
+	return ok && time.Unix(int64(v), 0).After(t)
+}
@@ -0,0 +1,316 @@
+// Package oidc 实现 OpenID Connect 及 OAuth2 授权码登录：服务发现、PKCE、state 与 nonce 校验、ID Token 验证
+package oidc
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrInvalidState 回调的 state 与发起登录时不一致，可能是伪造的请求或登录已超时
+	ErrInvalidState = errors.New("登录请求已失效，请重新登录")
+	// ErrInvalidIDToken ID Token 签名、签发者、受众、有效期或 nonce 校验失败
+	ErrInvalidIDToken = errors.New("身份令牌无效")
+)
+
+// Provider 一个身份提供方。设置了 Issuer 时通过服务发现获取各端点；
+// 只支持 OAuth2 的提供方（如 GitHub）直接设置 AuthURL、TokenURL 和 UserInfoURL
+type Provider struct {
+	// Name 提供方标识，用于路由和关联记录，如 company、github
+	Name string
+	// Label 登录按钮上显示的名称
+	Label string
+
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	JWKSURL     string
+
+	// TrustEmail 提供方不返回 email_verified 时，是否认为邮箱已验证，仅用于可信的企业身份提供方
+	TrustEmail bool
+
+	// Client 请求提供方使用的 HTTP 客户端，为空时使用带超时的默认客户端
+	Client *http.Client
+
+	mu         sync.Mutex
+	discovered bool
+	keys       map[string]*rsa.PublicKey
+}
+
+// Token 令牌端点返回的令牌
+type Token struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Claims 用户在提供方的身份信息
+type Claims struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// defaultClient 默认的 HTTP 客户端
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// IsOIDC 是否为 OpenID Connect 提供方，否则只能通过用户信息端点获取身份
+func (p *Provider) IsOIDC() bool {
+	return len(p.Issuer) > 0
+}
+
+// Discover 从 Issuer 的 /.well-known/openid-configuration 获取各端点，已手动设置的端点不会被覆盖
+// 成功后不再重复请求，失败时下次调用会重试
+func (p *Provider) Discover() error {
+	if !p.IsOIDC() {
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovered {
+		return nil
+	}
+
+	var doc struct {
+		Issuer                string `json:"issuer"`
+		AuthorizationEndpoint string `json:"authorization_endpoint"`
+		TokenEndpoint         string `json:"token_endpoint"`
+		UserInfoEndpoint      string `json:"userinfo_endpoint"`
+		JWKSURI               string `json:"jwks_uri"`
+	}
+	wellKnown := strings.TrimSuffix(p.Issuer, "/") + "/.well-known/openid-configuration"
+	if err := p.getJSON(wellKnown, "", &doc); err != nil {
+		return err
+	}
+
+	// 返回的 issuer 必须与配置一致，防止使用被篡改的配置
+	if doc.Issuer != p.Issuer {
+		return fmt.Errorf("oidc: issuer 不一致，配置为 %q，服务发现返回 %q", p.Issuer, doc.Issuer)
+	}
+
+	setDefault(&p.AuthURL, doc.AuthorizationEndpoint)
+	setDefault(&p.TokenURL, doc.TokenEndpoint)
+	setDefault(&p.UserInfoURL, doc.UserInfoEndpoint)
+	setDefault(&p.JWKSURL, doc.JWKSURI)
+	if len(p.AuthURL) == 0 || len(p.TokenURL) == 0 || len(p.JWKSURL) == 0 {
+		return errors.New("oidc: 服务发现缺少必要的端点")
+	}
+
+	p.discovered = true
+	return nil
+}
+
+// AuthCodeURL 跳转到提供方登录的地址，verifier 用于 PKCE，nonce 只在 OpenID Connect 中使用
+func (p *Provider) AuthCodeURL(state, nonce, verifier string) string {
+	v := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.ClientID},
+		"redirect_uri":          {p.RedirectURL},
+		"state":                 {state},
+		"code_challenge":        {CodeChallenge(verifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if len(p.Scopes) > 0 {
+		v.Set("scope", strings.Join(p.Scopes, " "))
+	}
+	if p.IsOIDC() {
+		v.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(p.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.AuthURL + sep + v.Encode()
+}
+
+// Exchange 使用授权码和 PKCE verifier 换取令牌
+func (p *Provider) Exchange(code, verifier string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.RedirectURL},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest("POST", p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tok Token
+	if err := p.doJSON(req, &tok); err != nil {
+		return nil, err
+	}
+	if len(tok.AccessToken) == 0 {
+		return nil, errors.New("oidc: 令牌端点没有返回 access_token")
+	}
+	return &tok, nil
+}
+
+// Authenticate 回调时使用授权码换取令牌并获取用户身份
+func (p *Provider) Authenticate(code, verifier, nonce string) (Claims, error) {
+	if err := p.Discover(); err != nil {
+		return Claims{}, err
+	}
+	tok, err := p.Exchange(code, verifier)
+	if err != nil {
+		return Claims{}, err
+	}
+	return p.Identity(tok, nonce)
+}
+
+// Identity 获取用户身份。OpenID Connect 提供方必须返回有效的 ID Token，并校验 nonce；
+// ID Token 中没有邮箱时，再从用户信息端点补充，但 sub 必须一致
+func (p *Provider) Identity(tok *Token, nonce string) (Claims, error) {
+	if !p.IsOIDC() {
+		return p.userInfo(tok.AccessToken)
+	}
+
+	if len(tok.IDToken) == 0 {
+		return Claims{}, ErrInvalidIDToken
+	}
+	claims, err := p.verifyIDToken(tok.IDToken, nonce)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if len(claims.Email) == 0 && len(p.UserInfoURL) > 0 {
+		info, err := p.userInfo(tok.AccessToken)
+		if err != nil {
+			return Claims{}, err
+		}
+		if info.Subject != claims.Subject {
+			return Claims{}, errors.New("oidc: 用户信息与身份令牌不一致")
+		}
+		claims.Email, claims.EmailVerified = info.Email, info.EmailVerified
+		setDefault(&claims.Name, info.Name)
+		setDefault(&claims.PreferredUsername, info.PreferredUsername)
+	}
+	return claims, nil
+}
+
+// userInfo 请求用户信息端点，兼容 OAuth2 提供方常用的 id、login 字段
+func (p *Provider) userInfo(accessToken string) (Claims, error) {
+	if len(p.UserInfoURL) == 0 {
+		return Claims{}, errors.New("oidc: 没有配置用户信息端点")
+	}
+
+	var raw map[string]interface{}
+	if err := p.getJSON(p.UserInfoURL, accessToken, &raw); err != nil {
+		return Claims{}, err
+	}
+
+	claims := p.parseClaims(raw)
+	if len(claims.Subject) == 0 {
+		claims.Subject = claimString(raw, "id")
+	}
+	setDefault(&claims.PreferredUsername, claimString(raw, "login"))
+	if len(claims.Subject) == 0 {
+		return Claims{}, errors.New("oidc: 用户信息缺少用户标识")
+	}
+	return claims, nil
+}
+
+// parseClaims 从 JSON 中读取身份信息，email_verified 兼容布尔值和字符串
+func (p *Provider) parseClaims(raw map[string]interface{}) Claims {
+	claims := Claims{
+		Subject:           claimString(raw, "sub"),
+		Email:             claimString(raw, "email"),
+		Name:              claimString(raw, "name"),
+		PreferredUsername: claimString(raw, "preferred_username"),
+	}
+
+	switch v := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = v
+	case string:
+		claims.EmailVerified = v == "true"
+	case nil:
+		claims.EmailVerified = p.TrustEmail && len(claims.Email) > 0
+	}
+	return claims
+}
+
+// getJSON 发送 GET 请求并解析 JSON，accessToken 不为空时携带 Bearer 令牌
+func (p *Provider) getJSON(u, accessToken string, v interface{}) error {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(accessToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return p.doJSON(req, v)
+}
+
+// doJSON 发送请求，非 2xx 响应返回错误
+func (p *Provider) doJSON(req *http.Request, v interface{}) error {
+	client := p.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("oidc: %s 返回 %d：%s", req.URL.Path, resp.StatusCode, truncate(string(body), 200))
+	}
+	return json.Unmarshal(body, v)
+}
+
+// claimString 读取字符串或数字类型的字段
+func claimString(raw map[string]interface{}, key string) string {
+	switch v := raw[key].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+// setDefault dst 为空时设置为 value
+func setDefault(dst *string, value string) {
+	if len(*dst) == 0 {
+		*dst = value
+	}
+}
+
+// truncate 截断过长的错误内容
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
@@ -0,0 +1,29 @@
+package oidc
+
+import "sort"
+
+// providers 已启用的身份提供方，由 bootstrap.SetupOIDC 按 oidc.providers 配置注册
+var providers = map[string]*Provider{}
+
+// Register 注册身份提供方，同名的会被替换
+func Register(p *Provider) {
+	providers[p.Name] = p
+}
+
+// Get 获取已启用的身份提供方
+func Get(name string) (*Provider, bool) {
+	p, ok := providers[name]
+	return p, ok
+}
+
+// All 所有已启用的身份提供方，按名称排序
+func All() []*Provider {
+	list := make([]*Provider, 0, len(providers))
+	for _, p := range providers {
+		list = append(list, p)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
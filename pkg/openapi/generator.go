@@ -0,0 +1,235 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// Endpoint 一个接口的说明，与路由名称对应
+type Endpoint struct {
+	Summary string
+	Tag     string
+	// Scope 需要的令牌权限范围，为空时不需要认证
+	Scope string
+	// Query 查询参数
+	Query []Parameter
+	// Request 请求体的类型，Rules 为其字段的验证规则
+	Request interface{}
+	Rules   map[string][]string
+	// Partial 只修改提供的字段，验证规则中的 required 不生效
+	Partial bool
+	// Response 响应中 data 的类型，为空时响应 204 无内容
+	Response interface{}
+	// List 是否为分页列表，data 为 Response 类型的数组
+	List bool
+	// Status 成功时的状态码，默认为 200
+	Status int
+}
+
+// Generator 根据路由生成文档，只有在 Endpoints 中有说明的路由会出现在文档中
+type Generator struct {
+	Info    Info
+	Servers []Server
+	// Meta 分页列表中 meta 的类型
+	Meta interface{}
+	// Endpoints 接口说明，键为路由名称
+	Endpoints map[string]Endpoint
+
+	schemas map[string]*Schema
+}
+
+// pathVariable 路由中的变量，如 {id:[0-9]+}
+var pathVariable = regexp.MustCompile(`\{([^:}]+)(?::([^}]+))?\}`)
+
+// Generate 遍历路由生成文档
+func (g *Generator) Generate(router *mux.Router) (*Document, error) {
+	g.schemas = map[string]*Schema{
+		// 与 pkg/response 的输出一致
+		"Error": {
+			Type:       "object",
+			Properties: map[string]*Schema{"message": {Type: "string"}},
+			Required:   []string{"message"},
+		},
+		"ValidationError": {
+			Type: "object",
+			Properties: map[string]*Schema{
+				"message": {Type: "string"},
+				"errors": {
+					Type:                 "object",
+					Description:          "每个字段的错误信息",
+					AdditionalProperties: &Schema{Type: "array", Items: &Schema{Type: "string"}},
+				},
+			},
+			Required: []string{"message", "errors"},
+		},
+	}
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    g.Info,
+		Servers: g.Servers,
+		Paths:   map[string]PathItem{},
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "个人访问令牌，在账号设置中创建",
+				},
+			},
+		},
+	}
+
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		endpoint, ok := g.Endpoints[route.GetName()]
+		if !ok {
+			return nil
+		}
+		tpl, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return err
+		}
+
+		path, params := convertPath(tpl)
+		item, ok := doc.Paths[path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[path] = item
+		}
+		for _, method := range methods {
+			item[strings.ToLower(method)] = g.operation(route.GetName(), endpoint, params)
+		}
+		return nil
+	})
+	return doc, err
+}
+
+// operation 生成一个接口
+func (g *Generator) operation(name string, e Endpoint, params []Parameter) *Operation {
+	op := &Operation{
+		OperationID: name,
+		Summary:     e.Summary,
+		Parameters:  append(append([]Parameter{}, params...), e.Query...),
+		Responses:   map[string]Response{},
+	}
+	if len(e.Tag) > 0 {
+		op.Tags = []string{e.Tag}
+	}
+
+	// 1. 请求体
+	if e.Request != nil {
+		t := reflect.TypeOf(e.Request)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  jsonContent(g.structSchema(t, e.Rules)),
+		}
+		if e.Partial {
+			op.RequestBody.Content["application/json"].Schema.Required = nil
+		}
+		op.Responses["400"] = g.errorResponse("请求体不是有效的 JSON", "Error")
+		op.Responses["422"] = g.errorResponse("请求参数验证失败", "ValidationError")
+	}
+
+	// 2. 成功的响应
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	switch {
+	case e.Response == nil:
+		op.Responses[strconv.Itoa(http.StatusNoContent)] = Response{Description: "无内容"}
+	case e.List:
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: "分页列表",
+			Content: jsonContent(&Schema{
+				Type: "object",
+				Properties: map[string]*Schema{
+					"data": {Type: "array", Items: g.schemaOf(reflect.TypeOf(e.Response))},
+					"meta": g.schemaOf(reflect.TypeOf(g.Meta)),
+				},
+				Required: []string{"data", "meta"},
+			}),
+		}
+	default:
+		op.Responses[strconv.Itoa(status)] = Response{
+			Description: "成功",
+			Content: jsonContent(&Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"data": g.schemaOf(reflect.TypeOf(e.Response))},
+				Required:   []string{"data"},
+			}),
+		}
+	}
+
+	// 3. 认证和其他错误
+	if len(e.Scope) > 0 {
+		op.Security = []map[string][]string{{"bearerAuth": {e.Scope}}}
+		op.Responses["401"] = g.errorResponse("缺少或无效的访问令牌", "Error")
+		op.Responses["403"] = g.errorResponse("令牌权限范围不足或没有操作权限", "Error")
+	}
+	if len(params) > 0 {
+		op.Responses["404"] = g.errorResponse("资源未找到", "Error")
+	}
+	return op
+}
+
+// errorResponse 错误响应
+func (g *Generator) errorResponse(description, schema string) Response {
+	return Response{
+		Description: description,
+		Content:     jsonContent(&Schema{Ref: "#/components/schemas/" + schema}),
+	}
+}
+
+// convertPath 将路由模板转换为 OpenAPI 路径，如 /articles/{id:[0-9]+} 转换为 /articles/{id}，并返回路径参数
+func convertPath(tpl string) (string, []Parameter) {
+	var params []Parameter
+	path := pathVariable.ReplaceAllStringFunc(tpl, func(v string) string {
+		m := pathVariable.FindStringSubmatch(v)
+		schema := &Schema{Type: "string"}
+		switch m[2] {
+		case "":
+		case "[0-9]+":
+			schema = &Schema{Type: "integer", Format: "int64"}
+		default:
+			schema.Pattern = "^" + m[2] + "$"
+		}
+		params = append(params, Parameter{Name: m[1], In: "path", Required: true, Schema: schema})
+		return "{" + m[1] + "}"
+	})
+	return path, params
+}
+
+// jsonContent JSON 内容
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// QueryParam 查询参数
+func QueryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// SortParams 排序参数 sort 和 order，sortable 为允许排序的字段
+func SortParams(sortable []string) []Parameter {
+	fields := append([]string{}, sortable...)
+	sort.Strings(fields)
+	return []Parameter{
+		QueryParam("sort", "排序字段", &Schema{Type: "string", Enum: fields}),
+		QueryParam("order", "排序方向", &Schema{Type: "string", Enum: []string{"asc", "desc"}}),
+	}
+}
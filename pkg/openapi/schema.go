@@ -0,0 +1,189 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// schemaOf 根据 Go 类型生成 Schema，结构体注册到 components 中并返回引用
+func (g *Generator) schemaOf(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := g.schemaOf(t.Elem())
+		// 指向结构体的指针通常配合 omitempty 使用，不会输出 null
+		if typ, ok := s.Type.(string); ok {
+			s.Type = []string{typ, "null"}
+		}
+		return s
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return g.ref(t)
+	}
+	// interface{} 等无法确定的类型，允许任意值
+	return &Schema{}
+}
+
+// ref 将结构体注册到 components 中，返回引用
+func (g *Generator) ref(t reflect.Type) *Schema {
+	name := schemaName(t)
+	if _, ok := g.schemas[name]; !ok {
+		// 先占位，防止结构体引用自身时无限递归
+		g.schemas[name] = &Schema{}
+		g.schemas[name] = g.structSchema(t, nil)
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// structSchema 结构体的 Schema，字段名取自 json 标签。
+// rules 不为空时按验证规则生成约束和必填字段，否则没有 omitempty 的字段为必填
+func (g *Generator) structSchema(t reflect.Type, rules map[string][]string) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, omitempty, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+
+		// 匿名嵌入的结构体，字段展开到当前结构体
+		if f.Anonymous && len(name) == 0 && f.Type.Kind() == reflect.Struct {
+			embedded := g.structSchema(f.Type, rules)
+			for k, v := range embedded.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if len(name) == 0 {
+			name = f.Name
+		}
+
+		prop := g.schemaOf(f.Type)
+		if rules != nil {
+			if applyRules(prop, rules[name]) {
+				s.Required = append(s.Required, name)
+			}
+		} else if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// applyRules 将 govalidator 的验证规则转换为 Schema 约束，返回是否必填。
+// 字符串的 min_cn、max_cn 按字数计算，与 JSON Schema 的 minLength、maxLength 一致
+func applyRules(s *Schema, rules []string) (required bool) {
+	for _, rule := range rules {
+		name, arg := rule, ""
+		if i := strings.Index(rule, ":"); i >= 0 {
+			name, arg = rule[:i], rule[i+1:]
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "min", "min_cn":
+			setMin(s, arg)
+		case "max", "max_cn":
+			setMax(s, arg)
+		case "between":
+			if args := strings.SplitN(arg, ",", 2); len(args) == 2 {
+				setMin(s, args[0])
+				setMax(s, args[1])
+			}
+		case "in":
+			s.Enum = strings.Split(arg, ",")
+		case "email":
+			s.Format = "email"
+		case "alpha_num":
+			s.Pattern = "^[A-Za-z0-9]+$"
+		case "not_exists":
+			s.Description = "不能与已有的数据重复"
+		}
+	}
+	return required
+}
+
+// setMin 字符串设置最小长度，数字设置最小值
+func setMin(s *Schema, arg string) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return
+	}
+	if s.Is("string") {
+		s.MinLength = &n
+	} else if s.Is("integer") || s.Is("number") {
+		v := float64(n)
+		s.Minimum = &v
+	}
+}
+
+// setMax 字符串设置最大长度，数字设置最大值
+func setMax(s *Schema, arg string) {
+	n, err := strconv.Atoi(arg)
+	if err != nil {
+		return
+	}
+	if s.Is("string") {
+		s.MaxLength = &n
+	} else if s.Is("integer") || s.Is("number") {
+		v := float64(n)
+		s.Maximum = &v
+	}
+}
+
+// jsonName 读取字段的 json 标签，ok 为 false 时该字段不会输出
+func jsonName(f reflect.StructField) (name string, omitempty bool, ok bool) {
+	if len(f.PkgPath) > 0 && !f.Anonymous {
+		return "", false, false
+	}
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	parts := strings.Split(tag, ",")
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitempty = true
+		}
+	}
+	return parts[0], omitempty, true
+}
+
+// schemaName 结构体在 components 中的名称，未导出的类型首字母转为大写，如 articleInput 为 ArticleInput
+func schemaName(t reflect.Type) string {
+	name := []rune(t.Name())
+	if len(name) == 0 {
+		return "Object"
+	}
+	name[0] = unicode.ToUpper(name[0])
+	return string(name)
+}
@@ -15,30 +15,30 @@ import (
 // Page 单个分页元素
 type Page struct {
 	// 链接
-	URL string
+	URL string `json:"url"`
 	// 页码
-	Number int
+	Number int `json:"number"`
 }
 
-// ViewData 同视图渲染的数据
+// ViewData 同视图渲染的数据，API 中作为分页元数据输出
 type ViewData struct {
 	// 是否需要显示分页
-	HasPages bool
+	HasPages bool `json:"has_pages"`
 
 	// 下一页
-	Next    Page
-	HasNext bool
+	Next    Page `json:"next"`
+	HasNext bool `json:"has_next"`
 
 	// 上一页
-	Prev    Page
-	HasPrev bool
+	Prev    Page `json:"prev"`
+	HasPrev bool `json:"has_prev"`
 
-	Current Page
+	Current Page `json:"current"`
 
 	// 数据库的内容总数量
-	TotalCount int64
+	TotalCount int64 `json:"total_count"`
 	// 总页数
-	TotalPage int
+	TotalPage int `json:"total_page"`
 }
 
 // Pagination 分页对象
@@ -83,6 +83,13 @@ func New(r *http.Request, db *gorm.DB, baseURL string, PerPage int) *Pagination
 	return p
 }
 
+// NewStatic 用于非数据库数据源（如搜索结果）的分页，count 为数据总数
+func NewStatic(r *http.Request, count int64, baseURL string, PerPage int) *Pagination {
+	p := New(r, nil, baseURL, PerPage)
+	p.Count = count
+	return p
+}
+
 // Paging 返回渲染分页所需的数据
 func (p *Pagination) Paging() ViewData {
 
@@ -136,17 +143,22 @@ func (p Pagination) CurrentPage() int {
 // Results 返回请求数据，请注意 data 参数必须为 GROM 模型的 Slice 对象
 func (p Pagination) Results(data interface{}) error {
 	var err error
-	var offset int
 	page := p.CurrentPage()
 	if page == 0 {
 		return err
 	}
 
-	if page > 1 {
-		offset = (page - 1) * p.PerPage
+	return p.db.Preload(clause.Associations).Limit(p.PerPage).Offset(p.Offset()).Find(data).Error
+}
+
+// Offset 当前页第一条数据的偏移量
+func (p Pagination) Offset() int {
+	page := p.CurrentPage()
+	if page <= 1 {
+		return 0
 	}
 
-	return p.db.Preload(clause.Associations).Limit(p.PerPage).Offset(offset).Find(data).Error
+	return (page - 1) * p.PerPage
 }
 
 // TotalCount 返回的是数据库里的条数
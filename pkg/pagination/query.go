@@ -0,0 +1,106 @@
+package pagination
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Query 列表页的关键字、筛选和排序参数，从 URL 查询参数 q、sort、order 及筛选字段中读取
+type Query struct {
+	// BaseURL 列表页链接，不含查询参数
+	BaseURL string
+	// Keyword 搜索关键字
+	Keyword string
+	// Sort 排序字段，只能是允许排序的字段之一
+	Sort string
+	// Desc 是否倒序
+	Desc bool
+
+	filters map[string]string
+}
+
+// NewQuery 读取列表参数
+// sortable —— 允许排序的字段，第一个为默认排序字段，默认倒序
+// filters —— 允许的筛选字段名称
+func NewQuery(r *http.Request, baseURL string, sortable []string, filters ...string) Query {
+	values := r.URL.Query()
+
+	q := Query{
+		BaseURL: baseURL,
+		Keyword: strings.TrimSpace(values.Get("q")),
+		Sort:    sortable[0],
+		Desc:    values.Get("order") != "asc",
+		filters: map[string]string{},
+	}
+
+	for _, column := range sortable {
+		if values.Get("sort") == column {
+			q.Sort = column
+		}
+	}
+
+	for _, name := range filters {
+		if v := strings.TrimSpace(values.Get(name)); len(v) > 0 {
+			q.filters[name] = v
+		}
+	}
+
+	return q
+}
+
+// Get 获取筛选字段的值
+func (q Query) Get(name string) string {
+	return q.filters[name]
+}
+
+// OrderBy 用于 SQL 的排序语句，字段已经过白名单检查
+func (q Query) OrderBy() string {
+	if q.Desc {
+		return q.Sort + " desc"
+	}
+	return q.Sort + " asc"
+}
+
+// URL 带有当前全部参数的链接，用作分页的 baseURL
+func (q Query) URL() string {
+	return q.url(q.Sort, q.Desc)
+}
+
+// SortURL 按字段排序的链接，已按此字段排序时切换正序和倒序
+func (q Query) SortURL(column string) string {
+	desc := true
+	if q.Sort == column {
+		desc = !q.Desc
+	}
+	return q.url(column, desc)
+}
+
+// SortMark 排序字段的方向标记
+func (q Query) SortMark(column string) string {
+	if q.Sort != column {
+		return ""
+	}
+	if q.Desc {
+		return "↓"
+	}
+	return "↑"
+}
+
+func (q Query) url(sort string, desc bool) string {
+	values := url.Values{}
+	if len(q.Keyword) > 0 {
+		values.Set("q", q.Keyword)
+	}
+	for name, v := range q.filters {
+		values.Set(name, v)
+	}
+	values.Set("sort", sort)
+	if desc {
+		values.Set("order", "desc")
+	} else {
+		values.Set("order", "asc")
+	}
+
+	return q.BaseURL + "?" + values.Encode()
+}
@@ -0,0 +1,33 @@
+// Package response 输出 API 的 JSON 响应
+package response
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// JSON 以 status 状态码输出 JSON
+func JSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if status == http.StatusNoContent {
+		return
+	}
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Println("输出 JSON 失败：", err)
+	}
+}
+
+// Error 输出错误信息，如 {"message": "文章未找到"}
+func Error(w http.ResponseWriter, status int, message string) {
+	JSON(w, status, map[string]string{"message": message})
+}
+
+// ValidationError 表单验证失败，以 422 状态码输出每个字段的错误信息
+func ValidationError(w http.ResponseWriter, errs map[string][]string) {
+	JSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+		"message": "请求参数验证失败",
+		"errors":  errs,
+	})
+}
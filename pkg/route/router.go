@@ -3,6 +3,7 @@ package route
 import (
 	"goblog/pkg/config"
 	"goblog/pkg/logger"
+	"net"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -15,6 +16,11 @@ func SetRoute(r *mux.Router) {
 	route = r
 }
 
+// Router 获取路由实例，用于遍历已注册的路由
+func Router() *mux.Router {
+	return route
+}
+
 // Name2URL 通过路由名称来获取 URL
 func Name2URL(routeName string, pairs ...string) string {
 	url, err := route.Get(routeName).URL(pairs...)
@@ -31,3 +37,12 @@ func GetRouteVariable(parameterName string, r *http.Request) string {
 	vars := mux.Vars(r)
 	return vars[parameterName]
 }
+
+// ClientIP 获取客户端 IP，取自连接的远端地址
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
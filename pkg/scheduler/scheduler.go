@@ -0,0 +1,92 @@
+package scheduler
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job 定时任务，返回的错误会被记录但不会中断调度
+type Job func() error
+
+// task 已注册的任务
+type task struct {
+	name     string
+	interval time.Duration
+	job      Job
+}
+
+var (
+	tasks []task
+	stop  chan struct{}
+	mutex sync.Mutex
+)
+
+// Every 注册一个每隔 interval 执行一次的任务，需在 Start 之前调用
+// interval 不大于 0 时不注册该任务，便于通过配置关闭，返回是否已注册
+func Every(name string, interval time.Duration, job Job) bool {
+	if interval <= 0 {
+		log.Printf("scheduler: 任务 %s 的执行间隔为 %s，已禁用", name, interval)
+		return false
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	tasks = append(tasks, task{name: name, interval: interval, job: job})
+	return true
+}
+
+// Start 在后台 goroutine 中启动所有已注册的任务，每个任务启动时会立即执行一次
+func Start() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if stop != nil {
+		return
+	}
+	stop = make(chan struct{})
+
+	for _, t := range tasks {
+		go run(t, stop)
+	}
+}
+
+// Stop 停止所有任务
+func Stop() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if stop != nil {
+		close(stop)
+		stop = nil
+	}
+}
+
+func run(t task, stop <-chan struct{}) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		execute(t)
+
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// execute 执行单次任务，任务中的 panic 不会影响其他任务
+func execute(t task) {
+	defer func() {
+		if err := recover(); err != nil {
+			log.Printf("scheduler: 任务 %s 异常：%v", t.name, err)
+		}
+	}()
+
+	if err := t.job(); err != nil {
+		log.Printf("scheduler: 任务 %s 执行失败：%v", t.name, err)
+	}
+}
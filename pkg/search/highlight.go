@@ -0,0 +1,120 @@
+package search
+
+import (
+	"html/template"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// span 原文中需要高亮的字节区间
+type span struct {
+	start, end int
+}
+
+// Highlight 将文本中与搜索词匹配的部分用 <mark> 标记，其余部分做 HTML 转义
+// maxRunes 大于 0 且文本过长时，截取以第一个匹配为中心附近的片段作为摘要
+func Highlight(text, query string, maxRunes int) template.HTML {
+	spans := matches(text, QueryTerms(query))
+
+	// 1. 确定截取范围
+	from, to := 0, len(text)
+	if maxRunes > 0 && utf8.RuneCountInString(text) > maxRunes {
+		anchor := 0
+		if len(spans) > 0 {
+			anchor = spans[0].start
+		}
+		from = moveBack(text, anchor, maxRunes/4)
+		to = moveForward(text, from, maxRunes)
+	}
+
+	// 2. 拼接结果
+	var sb strings.Builder
+	if from > 0 {
+		sb.WriteString("…")
+	}
+	pos := from
+	for _, s := range spans {
+		if s.end <= from || s.start >= to {
+			continue
+		}
+		start, end := max(s.start, from), min(s.end, to)
+		sb.WriteString(template.HTMLEscapeString(text[pos:start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(template.HTMLEscapeString(text[start:end]))
+		sb.WriteString("</mark>")
+		pos = end
+	}
+	sb.WriteString(template.HTMLEscapeString(text[pos:to]))
+	if to < len(text) {
+		sb.WriteString("…")
+	}
+
+	return template.HTML(sb.String())
+}
+
+// matches 找出文本中与搜索词匹配的区间，按位置排序并合并重叠部分
+func matches(text string, terms []string) []span {
+	if len(terms) == 0 {
+		return nil
+	}
+	want := map[string]bool{}
+	for _, t := range terms {
+		want[t] = true
+	}
+
+	var spans []span
+	for _, t := range Tokenize(text) {
+		if want[t.Term] {
+			spans = append(spans, span{t.Start, t.End})
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start <= merged[n-1].end {
+			if s.end > merged[n-1].end {
+				merged[n-1].end = s.end
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	return merged
+}
+
+// moveBack 从字节位置 pos 向前移动 n 个字符
+func moveBack(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	return pos
+}
+
+// moveForward 从字节位置 pos 向后移动 n 个字符
+func moveForward(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,198 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 相关度计算参数
+const (
+	// titleBoost 标题中的词频权重
+	titleBoost = 3.0
+	// BM25 参数
+	k1 = 1.2
+	b  = 0.75
+	// recencyBoost 新文章最多获得的额外加权比例
+	recencyBoost = 0.5
+	// recencyHalfLife 新鲜度加权的半衰期
+	recencyHalfLife = 180 * 24 * time.Hour
+)
+
+// Document 被索引的文档
+type Document struct {
+	ID    uint64
+	Title string
+	Body  string
+	Time  time.Time
+}
+
+// Hit 搜索结果
+type Hit struct {
+	ID    uint64
+	Score float64
+}
+
+// posting 倒排表中一个文档的词频
+type posting struct {
+	title int
+	body  int
+}
+
+// entry 已索引的文档信息
+type entry struct {
+	time   time.Time
+	length int
+	terms  []string
+}
+
+// Index 内存中的倒排索引，可并发使用
+type Index struct {
+	mutex       sync.RWMutex
+	docs        map[uint64]entry
+	postings    map[string]map[uint64]posting
+	totalLength int
+}
+
+// Default 全站文章索引
+var Default = NewIndex()
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		docs:     map[uint64]entry{},
+		postings: map[string]map[uint64]posting{},
+	}
+}
+
+// Add 添加或替换文档
+func (idx *Index) Add(doc Document) {
+	title := Tokenize(doc.Title)
+	body := Tokenize(doc.Body)
+
+	// 1. 统计词频
+	freq := map[string]posting{}
+	for _, t := range title {
+		p := freq[t.Term]
+		p.title++
+		freq[t.Term] = p
+	}
+	for _, t := range body {
+		p := freq[t.Term]
+		p.body++
+		freq[t.Term] = p
+	}
+
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	// 2. 移除旧版本
+	idx.remove(doc.ID)
+
+	// 3. 写入倒排表
+	e := entry{time: doc.Time, length: len(title) + len(body)}
+	for term, p := range freq {
+		if idx.postings[term] == nil {
+			idx.postings[term] = map[uint64]posting{}
+		}
+		idx.postings[term][doc.ID] = p
+		e.terms = append(e.terms, term)
+	}
+	idx.docs[doc.ID] = e
+	idx.totalLength += e.length
+}
+
+// Remove 移除文档
+func (idx *Index) Remove(id uint64) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+
+	idx.remove(id)
+}
+
+func (idx *Index) remove(id uint64) {
+	e, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+
+	for _, term := range e.terms {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	delete(idx.docs, id)
+	idx.totalLength -= e.length
+}
+
+// Len 已索引的文档数量
+func (idx *Index) Len() int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	return len(idx.docs)
+}
+
+// Search 搜索并按相关度从高到低返回所有匹配的文档
+// 相关度为 BM25 得分（标题词频加权），乘以匹配词比例和新鲜度加权
+func (idx *Index) Search(query string) []Hit {
+	terms := QueryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+
+	n := float64(len(idx.docs))
+	if n == 0 {
+		return nil
+	}
+	avgLength := float64(idx.totalLength) / n
+
+	// 1. 累加每个词的 BM25 得分
+	scores := map[uint64]float64{}
+	matched := map[uint64]int{}
+	for _, term := range terms {
+		docs := idx.postings[term]
+		if len(docs) == 0 {
+			continue
+		}
+
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, p := range docs {
+			tf := titleBoost*float64(p.title) + float64(p.body)
+			norm := k1 * (1 - b + b*float64(idx.docs[id].length)/avgLength)
+			scores[id] += idf * tf * (k1 + 1) / (tf + norm)
+			matched[id]++
+		}
+	}
+
+	// 2. 匹配词比例和新鲜度加权
+	now := time.Now()
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		score *= float64(matched[id]) / float64(len(terms))
+
+		age := now.Sub(idx.docs[id].time)
+		if age < 0 {
+			age = 0
+		}
+		score *= 1 + recencyBoost*math.Pow(0.5, float64(age)/float64(recencyHalfLife))
+
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+
+	// 3. 排序，得分相同时新文档在前
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID > hits[j].ID
+	})
+
+	return hits
+}
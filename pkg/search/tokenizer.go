@@ -0,0 +1,132 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	porterstemmer "github.com/blevesearch/go-porterstemmer"
+)
+
+// Token 词元，Start 和 End 为其在原文中的字节位置
+type Token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// stopWords 英文停用词，不参与索引
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "by": true, "for": true, "from": true, "has": true, "in": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "that": true,
+	"the": true, "to": true, "was": true, "were": true, "will": true, "with": true,
+}
+
+// Tokenize 对文本分词，用于建立索引：
+// 中日韩文字同时生成单字和相邻二字（bigram）词元；拉丁字母和数字按词切分，小写并提取词干
+func Tokenize(text string) []Token {
+	return tokenize(text, true)
+}
+
+// QueryTerms 对搜索词分词并去重：中日韩文字只使用 bigram，单个字时使用单字
+func QueryTerms(query string) []string {
+	var terms []string
+	seen := map[string]bool{}
+	for _, t := range tokenize(query, false) {
+		if !seen[t.Term] {
+			seen[t.Term] = true
+			terms = append(terms, t.Term)
+		}
+	}
+	return terms
+}
+
+// cjkRune 中日韩文字及其在原文中的位置
+type cjkRune struct {
+	r          rune
+	start, end int
+}
+
+func tokenize(text string, unigrams bool) []Token {
+	var tokens []Token
+
+	// 当前正在累积的拉丁词
+	var word strings.Builder
+	wordStart := -1
+
+	// 当前正在累积的中日韩文字
+	var run []cjkRune
+
+	flushWord := func(end int) {
+		if wordStart >= 0 {
+			if term := normalizeWord(word.String()); len(term) > 0 {
+				tokens = append(tokens, Token{Term: term, Start: wordStart, End: end})
+			}
+			word.Reset()
+			wordStart = -1
+		}
+	}
+
+	flushRun := func() {
+		for i, c := range run {
+			if unigrams || len(run) == 1 {
+				tokens = append(tokens, Token{Term: string(c.r), Start: c.start, End: c.end})
+			}
+			if i+1 < len(run) {
+				next := run[i+1]
+				tokens = append(tokens, Token{Term: string([]rune{c.r, next.r}), Start: c.start, End: next.end})
+			}
+		}
+		run = run[:0]
+	}
+
+	for i, r := range text {
+		end := i + utf8.RuneLen(r)
+		r = normalizeRune(r)
+
+		switch {
+		case isCJK(r):
+			flushWord(i)
+			run = append(run, cjkRune{r, i, end})
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushRun()
+			if wordStart < 0 {
+				wordStart = i
+			}
+			word.WriteRune(r)
+		default:
+			flushWord(i)
+			flushRun()
+		}
+	}
+	flushWord(len(text))
+	flushRun()
+
+	return tokens
+}
+
+// normalizeRune 全角字符转为半角，字母转为小写
+func normalizeRune(r rune) rune {
+	if r >= 0xFF01 && r <= 0xFF5E {
+		r -= 0xFEE0
+	}
+	return unicode.ToLower(r)
+}
+
+// normalizeWord 去除停用词，对英文单词提取词干
+func normalizeWord(word string) string {
+	if stopWords[word] {
+		return ""
+	}
+	for _, r := range word {
+		if r > unicode.MaxASCII {
+			return word
+		}
+	}
+	return porterstemmer.StemString(word)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,141 @@
+// Package seo 生成页面的搜索引擎与社交网络元数据（Open Graph、Twitter 卡片、JSON-LD）以及 robots.txt
+package seo
+
+import (
+	"encoding/json"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// Meta 页面的元数据，由布局模板输出到 <head> 中
+type Meta struct {
+	Title       string
+	Description string
+	// Canonical 页面的规范链接，绝对地址
+	Canonical string
+	// Type Open Graph 类型，文章页面为 article，其余为 website
+	Type     string
+	SiteName string
+	// Image 分享时显示的图片，绝对地址，可以为空
+	Image string
+	// Twitter 站点的 Twitter 账号
+	Twitter string
+	// NoIndex 禁止搜索引擎收录，如登录、后台等页面
+	NoIndex bool
+	// Post 文章页面的结构化数据，其他页面为 nil
+	Post *BlogPosting
+}
+
+// BlogPosting 文章的结构化数据，对应 schema.org 的 BlogPosting
+type BlogPosting struct {
+	Headline    string
+	Description string
+	URL         string
+	Image       string
+	AuthorName  string
+	AuthorURL   string
+	Keywords    []string
+	Published   time.Time
+	Modified    time.Time
+}
+
+// TwitterCard 有图片时使用大图卡片
+func (m Meta) TwitterCard() string {
+	if len(m.Image) > 0 {
+		return "summary_large_image"
+	}
+	return "summary"
+}
+
+// jsonLDPerson schema.org 的 Person 与 Organization
+type jsonLDPerson struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+
+// jsonLDPosting 输出的 JSON-LD，字段顺序即输出顺序
+type jsonLDPosting struct {
+	Context          string        `json:"@context"`
+	Type             string        `json:"@type"`
+	Headline         string        `json:"headline"`
+	Description      string        `json:"description,omitempty"`
+	URL              string        `json:"url"`
+	MainEntityOfPage string        `json:"mainEntityOfPage"`
+	Image            string        `json:"image,omitempty"`
+	DatePublished    string        `json:"datePublished"`
+	DateModified     string        `json:"dateModified"`
+	Author           *jsonLDPerson `json:"author,omitempty"`
+	Publisher        jsonLDPerson  `json:"publisher"`
+	Keywords         string        `json:"keywords,omitempty"`
+}
+
+// JSONLD 文章页面的 BlogPosting 结构化数据，用于 <script type="application/ld+json">，非文章页面返回空
+// json.Marshal 会转义 <、> 和 &，内容中不会出现 </script>
+func (m Meta) JSONLD() template.JS {
+	if m.Post == nil {
+		return ""
+	}
+
+	p := m.Post
+	doc := jsonLDPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         p.Headline,
+		Description:      p.Description,
+		URL:              p.URL,
+		MainEntityOfPage: p.URL,
+		Image:            p.Image,
+		DatePublished:    p.Published.Format(time.RFC3339),
+		DateModified:     p.Modified.Format(time.RFC3339),
+		Publisher:        jsonLDPerson{Type: "Organization", Name: m.SiteName},
+		Keywords:         strings.Join(p.Keywords, ", "),
+	}
+	if len(p.AuthorName) > 0 {
+		doc.Author = &jsonLDPerson{Type: "Person", Name: p.AuthorName, URL: p.AuthorURL}
+	}
+
+	b, err := json.Marshal(doc)
+	if err != nil {
+		return ""
+	}
+	return template.JS(b)
+}
+
+// ParsePaths 解析逗号分隔的路径前缀，去除空白和空项
+func ParsePaths(s string) []string {
+	var paths []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); len(p) > 0 {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// Disallowed 路径是否以任一禁止抓取的前缀开头
+func Disallowed(path string, disallow []string) bool {
+	for _, prefix := range disallow {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Robots 生成 robots.txt，允许抓取 disallow 以外的所有页面，并声明站点地图地址
+func Robots(disallow []string, sitemapURL string) string {
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if len(disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, p := range disallow {
+		b.WriteString("Disallow: " + p + "\n")
+	}
+	if len(sitemapURL) > 0 {
+		b.WriteString("\nSitemap: " + sitemapURL + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package session
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// filePrefix 会话文件名前缀
+const filePrefix = "session_"
+
+// FileHandler 文件会话存储，每个会话一个文件，文件开头 8 字节为过期时间（Unix 秒）
+type FileHandler struct {
+	Dir string
+
+	mu sync.RWMutex
+}
+
+// NewFileHandler 创建文件会话存储，目录不存在时自动创建
+func NewFileHandler(dir string) (*FileHandler, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileHandler{Dir: dir}, nil
+}
+
+// Read 读取会话数据
+func (h *FileHandler) Read(id string) ([]byte, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	content, err := ioutil.ReadFile(h.path(id))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	expiresAt, data, ok := decodeFile(content)
+	if !ok || time.Now().After(expiresAt) {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// Write 写入会话数据
+func (h *FileHandler) Write(id string, data []byte, expiresAt time.Time) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	content := make([]byte, 8, 8+len(data))
+	binary.BigEndian.PutUint64(content, uint64(expiresAt.Unix()))
+	content = append(content, data...)
+
+	// 先写临时文件再重命名，避免读到写了一半的文件
+	tmp := h.path(id) + ".tmp"
+	if err := ioutil.WriteFile(tmp, content, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path(id))
+}
+
+// Destroy 删除会话
+func (h *FileHandler) Destroy(id string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := os.Remove(h.path(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// GC 删除已过期的会话文件
+func (h *FileHandler) GC() (int64, error) {
+	files, err := filepath.Glob(filepath.Join(h.Dir, filePrefix+"*"))
+	if err != nil {
+		return 0, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var deleted int64
+	now := time.Now()
+	for _, file := range files {
+		if strings.HasSuffix(file, ".tmp") {
+			continue
+		}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if expiresAt, _, ok := decodeFile(content); ok && now.Before(expiresAt) {
+			continue
+		}
+		if err := os.Remove(file); err == nil {
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// path 会话文件路径，会话 ID 由 newID 生成，只包含字母和数字
+func (h *FileHandler) path(id string) string {
+	return filepath.Join(h.Dir, filePrefix+filepath.Base(id))
+}
+
+// decodeFile 解析会话文件内容
+func decodeFile(content []byte) (expiresAt time.Time, data []byte, ok bool) {
+	if len(content) < 8 {
+		return time.Time{}, nil, false
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(content[:8])), 0), content[8:], true
+}
@@ -0,0 +1,28 @@
+package session
+
+import (
+	"crypto/sha256"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// encryptionKeyInfo HKDF 派生加密密钥时使用的上下文信息，使派生出的密钥只用于会话加密
+const encryptionKeyInfo = "goblog session encryption key"
+
+// EncryptionKey 返回 AES-256 加密 Cookie 使用的 32 字节密钥
+// 设置了 encryptionKey 时由它生成，未设置时使用 HKDF 从签名密钥 appKey 派生，两者互不相同
+func EncryptionKey(encryptionKey, appKey string) []byte {
+	if len(encryptionKey) > 0 {
+		key := sha256.Sum256([]byte(encryptionKey))
+		return key[:]
+	}
+
+	key := make([]byte, 32)
+	reader := hkdf.New(sha256.New, []byte(appKey), nil, []byte(encryptionKeyInfo))
+	if _, err := io.ReadFull(reader, key); err != nil {
+		// 32 字节远小于 HKDF-SHA256 的输出上限，不会出错
+		panic(err)
+	}
+	return key
+}
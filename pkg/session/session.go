@@ -1,66 +1,169 @@
 package session
 
 import (
-	"goblog/pkg/config"
-	"goblog/pkg/logger"
+	"context"
+	"log"
 	"net/http"
+	"time"
 
+	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
 )
 
-// Store gorilla sessions 的存储库
-var Store = sessions.NewCookieStore([]byte(config.GetString("app.key")))
+// Store gorilla sessions 的存储库，由 bootstrap.SetupSession 按 session.default 配置
+var Store sessions.Store = sessions.NewCookieStore(securecookie.GenerateRandomKey(32))
 
-// Session 当前会话
-var Session *sessions.Session
+// Name 会话的 Cookie 名称
+var Name = "goblog-session"
 
-// Request 用以获取会话
-var Request *http.Request
+// Lifetime 会话从创建起的最长有效时间，为 0 时不限制
+var Lifetime time.Duration
 
-// Response 用以写入会话
-var Response http.ResponseWriter
+// IdleTimeout 超过此时间没有访问，会话失效，为 0 时不限制
+var IdleTimeout time.Duration
 
-// StartSession 初始化会话，在中间件中调用
-func StartSession(w http.ResponseWriter, r *http.Request) {
-	var err error
+// touchInterval 更新最后访问时间的最短间隔，避免每个请求都写入会话
+const touchInterval = time.Minute
 
+// 会话中记录创建时间和最后访问时间的键
+const (
+	createdAtKey    = "_created_at"
+	lastActivityKey = "_last_activity"
+)
+
+// Session 一次请求的会话，由 StartSession 中间件放入请求的 context 中，不同请求之间互不影响
+type Session struct {
+	session  *sessions.Session
+	request  *http.Request
+	response http.ResponseWriter
+}
+
+// contextKey 会话在 context 中的键
+type contextKey struct{}
+
+// Start 初始化会话，返回携带会话的请求，在中间件中调用
+func Start(w http.ResponseWriter, r *http.Request) *http.Request {
 	// Store.Get() 的第二个参数是 Cookie 的名称
 	// gorilla/sessions 支持多会话，本项目我们只使用单一会话即可
-	Session, err = Store.Get(r, config.GetString("session.session_name"))
-	logger.LogError(err)
+	// Cookie 无法解码（如被篡改或更换了密钥）时会返回错误和一个新的会话，使用新会话即可
+	s, _ := Store.Get(r, Name)
+
+	sess := &Session{session: s, response: w}
+	r = r.WithContext(context.WithValue(r.Context(), contextKey{}, sess))
+	sess.request = r
 
-	Request = r
-	Response = w
+	sess.touch()
+	return r
+}
+
+// FromContext 获取 context 中的会话，未经过 StartSession 中间件时返回一个不会保存的空会话
+func FromContext(ctx context.Context) *Session {
+	if sess, ok := ctx.Value(contextKey{}).(*Session); ok {
+		return sess
+	}
+	s := sessions.NewSession(Store, Name)
+	s.Options = &sessions.Options{Path: "/"}
+	return &Session{session: s}
 }
 
 // Put 写入键值对应的会话数据
-func Put(key string, value interface{}) {
-	Session.Values[key] = value
-	Save()
+func Put(r *http.Request, key string, value interface{}) {
+	sess := FromContext(r.Context())
+	sess.session.Values[key] = value
+	sess.save()
 }
 
 // Get 获取会话数据，获取数据时请做类型检测
-func Get(key string) interface{} {
-	return Session.Values[key]
+func Get(r *http.Request, key string) interface{} {
+	return FromContext(r.Context()).session.Values[key]
 }
 
 // Forget 删除某个会话项
-func Forget(key string) {
-	delete(Session.Values, key)
-	Save()
+func Forget(r *http.Request, key string) {
+	sess := FromContext(r.Context())
+	delete(sess.session.Values, key)
+	sess.save()
 }
 
 // Flush 删除当前会话
-func Flush() {
-	Session.Options.MaxAge = -1
-	Save()
+func Flush(r *http.Request) {
+	sess := FromContext(r.Context())
+	sess.session.Options.MaxAge = -1
+	sess.save()
+}
+
+// Save 保存会话
+func Save(r *http.Request) {
+	FromContext(r.Context()).save()
+}
+
+// GC 删除已过期的服务端会话，Cookie 存储无需清理
+func GC() (int64, error) {
+	if store, ok := Store.(*ServerStore); ok {
+		return store.Handler.GC()
+	}
+	return 0, nil
 }
 
-// Save 保持会话
-func Save() {
+// Regenerate 重新生成会话 ID 并保留会话数据，登录和退出时调用，防止会话固定攻击
+func Regenerate(r *http.Request) {
+	sess := FromContext(r.Context())
+	sess.regenerate()
+	sess.save()
+}
+
+// touch 检查会话是否已超过有效时间或空闲时间，过期的会话清空数据并更换 ID；未过期则更新最后访问时间
+func (sess *Session) touch() {
+	now := time.Now()
+	values := sess.session.Values
+	createdAt, _ := values[createdAtKey].(int64)
+	lastActivity, _ := values[lastActivityKey].(int64)
+
+	if !sess.session.IsNew && (expired(createdAt, Lifetime, now) || expired(lastActivity, IdleTimeout, now)) {
+		for key := range values {
+			delete(values, key)
+		}
+		sess.regenerate()
+		createdAt, lastActivity = 0, 0
+	}
+
+	if createdAt == 0 {
+		values[createdAtKey] = now.Unix()
+	}
+	if now.Sub(time.Unix(lastActivity, 0)) >= touchInterval {
+		values[lastActivityKey] = now.Unix()
+
+		// 新会话在写入数据时才保存，避免为每个未登录的访问都创建会话
+		if !sess.session.IsNew {
+			sess.save()
+		}
+	}
+}
+
+// regenerate 服务端存储删除旧的会话 ID，下次保存时生成新的 ID；Cookie 存储没有会话 ID，无需处理
+func (sess *Session) regenerate() {
+	if regenerator, ok := sess.session.Store().(Regenerator); ok {
+		if err := regenerator.Regenerate(sess.session); err != nil {
+			log.Println("重新生成会话 ID 失败：", err)
+		}
+	}
+}
+
+// expired 时间戳 t 距今是否已超过 d，t 或 d 为 0 时不过期
+func expired(t int64, d time.Duration, now time.Time) bool {
+	return t > 0 && d > 0 && now.Sub(time.Unix(t, 0)) > d
+}
+
+// save 保存会话，写入 Cookie 需在输出响应内容之前
+func (sess *Session) save() {
+	if sess.response == nil {
+		return
+	}
+
 	// 非 HTTPS 的链接无法使用 Secure 和 HttpOnly，浏览器会报错
 	// Session.Options.Secure = true
 	// Session.Options.HttpOnly = true
-	err := Session.Save(Request, Response)
-	logger.LogError(err)
+	if err := sess.session.Save(sess.request, sess.response); err != nil {
+		log.Println("保存会话失败：", err)
+	}
 }
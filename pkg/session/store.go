@@ -0,0 +1,150 @@
+package session
+
+import (
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// Handler 服务端会话数据的存储，数据已由 ServerStore 签名加密
+type Handler interface {
+	// Read 读取会话数据，不存在或已过期时返回 nil
+	Read(id string) ([]byte, error)
+	// Write 写入会话数据，expiresAt 之后视为过期
+	Write(id string, data []byte, expiresAt time.Time) error
+	// Destroy 删除会话
+	Destroy(id string) error
+	// GC 删除所有已过期的会话，返回删除的数量
+	GC() (int64, error)
+}
+
+// Regenerator 支持重新生成会话 ID 的存储
+type Regenerator interface {
+	Regenerate(s *sessions.Session) error
+}
+
+// ServerStore 服务端会话存储，Cookie 中只保存签名加密后的会话 ID，会话数据由 Handler 保存
+type ServerStore struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options
+	Handler Handler
+
+	// TTL 每次保存后会话在服务端的有效时间
+	TTL time.Duration
+}
+
+// NewServerStore 创建服务端会话存储，keyPairs 与 sessions.NewCookieStore 相同，依次为签名密钥和加密密钥
+func NewServerStore(handler Handler, ttl time.Duration, keyPairs ...[]byte) *ServerStore {
+	s := &ServerStore{
+		Codecs:  securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{Path: "/"},
+		Handler: handler,
+		TTL:     ttl,
+	}
+
+	// 会话数据不放在 Cookie 中，不受 Cookie 4096 字节的限制
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxLength(0)
+		}
+	}
+	return s
+}
+
+// Get 获取会话，同一请求中多次获取返回同一会话
+func (s *ServerStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New 根据 Cookie 中的会话 ID 读取会话，Cookie 无效或会话已过期时返回新会话
+func (s *ServerStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	if err := securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...); err != nil {
+		session.ID = ""
+		return session, err
+	}
+
+	data, err := s.Handler.Read(session.ID)
+	if err != nil || data == nil {
+		// 已过期或已删除的会话 ID 不再使用，保存时生成新的 ID
+		session.ID = ""
+		return session, err
+	}
+	if err := securecookie.DecodeMulti(name, string(data), &session.Values, s.Codecs...); err != nil {
+		session.ID = ""
+		return session, err
+	}
+
+	session.IsNew = false
+	return session, nil
+}
+
+// Save 保存会话数据并写入 Cookie，MaxAge 小于 0 时删除会话
+func (s *ServerStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.Handler.Destroy(session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = newID()
+	}
+
+	data, err := securecookie.EncodeMulti(session.Name(), session.Values, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	if err := s.Handler.Write(session.ID, []byte(data), time.Now().Add(s.TTL)); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// Regenerate 删除旧的会话 ID，保留会话数据，下次保存时使用新的 ID
+func (s *ServerStore) Regenerate(session *sessions.Session) error {
+	if session.ID != "" {
+		if err := s.Handler.Destroy(session.ID); err != nil {
+			return err
+		}
+	}
+	session.ID = ""
+	return nil
+}
+
+// MaxAge 设置 Cookie 的有效时间，同时用于校验 Cookie 中的时间戳，单位为秒
+func (s *ServerStore) MaxAge(age int) {
+	s.Options.MaxAge = age
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
+
+// newID 生成随机的会话 ID，只包含字母和数字，可直接用作文件名
+func newID() string {
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+}
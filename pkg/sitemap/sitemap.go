@@ -0,0 +1,104 @@
+// Package sitemap 生成 sitemaps.org 协议的站点地图及站点地图索引
+package sitemap
+
+import (
+	"encoding/xml"
+	"net/http"
+	"time"
+)
+
+// xmlns 站点地图的命名空间
+const xmlns = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// MaxURLs 协议规定单个站点地图最多包含的链接数量
+const MaxURLs = 50000
+
+// URL 站点地图中的一个页面
+type URL struct {
+	Loc string
+	// LastMod 最后修改时间，零值时不输出
+	LastMod time.Time
+}
+
+// URLSet 站点地图
+type URLSet []URL
+
+// Index 站点地图索引，每项为一个站点地图的地址
+type Index []URL
+
+type xmlURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+type xmlURLSet struct {
+	XMLName xml.Name `xml:"urlset"`
+	Xmlns   string   `xml:"xmlns,attr"`
+	URLs    []xmlURL `xml:"url"`
+}
+
+type xmlIndex struct {
+	XMLName  xml.Name `xml:"sitemapindex"`
+	Xmlns    string   `xml:"xmlns,attr"`
+	Sitemaps []xmlURL `xml:"sitemap"`
+}
+
+// Encode 生成站点地图
+func (s URLSet) Encode() ([]byte, error) {
+	return marshalXML(xmlURLSet{Xmlns: xmlns, URLs: toXML(s)})
+}
+
+// Encode 生成站点地图索引
+func (i Index) Encode() ([]byte, error) {
+	return marshalXML(xmlIndex{Xmlns: xmlns, Sitemaps: toXML(i)})
+}
+
+// Size 每个站点地图包含的链接数量，限制在 1 到 MaxURLs 之间
+func Size(size int) int {
+	if size <= 0 || size > MaxURLs {
+		return MaxURLs
+	}
+	return size
+}
+
+// Chunks 按每个站点地图 size 个链接拆分 total 个链接需要的站点地图数量，至少为 1
+func Chunks(total int64, size int) int {
+	size = Size(size)
+	n := int((total + int64(size) - 1) / int64(size))
+	if n < 1 {
+		return 1
+	}
+	return n
+}
+
+// Write 输出 XML，编码失败时返回 500
+func Write(w http.ResponseWriter, encode func() ([]byte, error)) {
+	body, err := encode()
+	if err != nil {
+		http.Error(w, "500 服务器内部错误", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Write(body)
+}
+
+func toXML(urls []URL) []xmlURL {
+	items := make([]xmlURL, 0, len(urls))
+	for _, u := range urls {
+		item := xmlURL{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			item.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// marshalXML 生成带 XML 声明的文档
+func marshalXML(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
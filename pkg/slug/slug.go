@@ -0,0 +1,123 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+// MaxLength slug 的最大长度
+const MaxLength = 100
+
+// args 拼音转换参数：不带声调，多音字取常用读音
+var args = pinyin.NewArgs()
+
+// Make 根据标题生成 slug，中文转换为不带声调的拼音，如 "Go 语言入门" → "go-yu-yan-ru-men"
+// 只保留小写字母、数字和连字符，无法转换的字符会被忽略
+func Make(title string) string {
+	var words []string
+	var current strings.Builder
+
+	flush := func() {
+		if current.Len() > 0 {
+			words = append(words, current.String())
+			current.Reset()
+		}
+	}
+
+	for _, r := range title {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			// 每个汉字的拼音单独成词
+			flush()
+			if py := pinyin.SinglePinyin(r, args); len(py) > 0 {
+				words = append(words, py[0])
+			}
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			current.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return truncate(strings.Join(words, "-"))
+}
+
+// Matches 判断 s 是否由 base 生成，包括为去重追加了数字后缀的情况，如 go-yu-yan-2
+func Matches(s, base string) bool {
+	if s == base {
+		return true
+	}
+	if !strings.HasPrefix(s, base+"-") {
+		return false
+	}
+	_, err := strconv.Atoi(strings.TrimPrefix(s, base+"-"))
+	return err == nil
+}
+
+// WithSuffix 为 slug 追加用于去重的数字后缀，如 go-yu-yan-2
+func WithSuffix(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// Unique 依次尝试 base、base-2、base-3……直到 exists 返回 false，用于生成唯一的 slug
+func Unique(base string, exists func(candidate string) (bool, error)) (string, error) {
+	for n := 1; ; n++ {
+		candidate := WithSuffix(base, n)
+		taken, err := exists(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+	}
+}
+
+// IsNumeric 纯数字的 slug 会和 ID 路由冲突，需要避免
+func IsNumeric(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Safe 确保 slug 可以用作路由参数：为空时使用 fallback，
+// 纯数字（与 ID 路由冲突）或保留字（如 create）时加上 fallback 前缀
+func Safe(s, fallback string, reserved ...string) string {
+	if len(s) == 0 {
+		return fallback
+	}
+	if IsNumeric(s) {
+		return fallback + "-" + s
+	}
+	for _, word := range reserved {
+		if s == word {
+			return fallback + "-" + s
+		}
+	}
+	return s
+}
+
+// truncate 截断过长的 slug，并尽量在连字符处断开
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+	s = s[:MaxLength]
+	if i := strings.LastIndex(s, "-"); i > 0 {
+		s = s[:i]
+	}
+	return strings.Trim(s, "-")
+}
@@ -0,0 +1,132 @@
+// Package throttle 登录失败限流：按键（如 IP、账号）记录连续失败次数，超过次数后指数退避，再多则临时锁定
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+// Record 某个键的失败记录
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time
+}
+
+// Store 失败记录的存储
+type Store interface {
+	// Get 读取记录，不存在时返回零值
+	Get(key string) (Record, error)
+	Put(key string, record Record) error
+	Delete(key string) error
+}
+
+// Policy 限流规则
+type Policy struct {
+	// FreeAttempts 连续失败此次数以内不限制
+	FreeAttempts int
+	// BaseDelay 超过 FreeAttempts 后第一次失败需等待的时间，之后每次失败翻倍，最多 MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// LockoutAttempts 连续失败达到此次数后锁定 LockoutDuration，为 0 时不锁定
+	LockoutAttempts int
+	LockoutDuration time.Duration
+	// Decay 距上次失败超过此时间后重新计数
+	Decay time.Duration
+}
+
+// Limiter 限流器
+type Limiter struct {
+	Store  Store
+	Policy Policy
+
+	// Now 获取当前时间，为空时使用 time.Now
+	Now func() time.Time
+
+	// mu 保证同一进程内读取和写回记录之间不被其他请求打断
+	mu sync.Mutex
+}
+
+// New 创建限流器
+func New(store Store, policy Policy) *Limiter {
+	return &Limiter{Store: store, Policy: policy}
+}
+
+// Wait 返回需要等待多久才能再次尝试，多个键时取最长的等待时间
+func (l *Limiter) Wait(keys ...string) (time.Duration, error) {
+	now := l.now()
+
+	var wait time.Duration
+	for _, key := range keys {
+		record, err := l.Store.Get(key)
+		if err != nil {
+			return 0, err
+		}
+		if d := record.LockedUntil.Sub(now); d > wait {
+			wait = d
+		}
+	}
+	return wait, nil
+}
+
+// Fail 记录一次失败，返回下一次尝试前需等待的时间
+func (l *Limiter) Fail(key string) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	record, err := l.Store.Get(key)
+	if err != nil {
+		return 0, err
+	}
+
+	now := l.now()
+	if !record.LastFailure.IsZero() && now.Sub(record.LastFailure) > l.Policy.Decay {
+		record = Record{}
+	}
+	record.Failures++
+	record.LastFailure = now
+
+	if delay := l.Policy.delay(record.Failures); delay > 0 {
+		record.LockedUntil = now.Add(delay)
+	}
+
+	if err := l.Store.Put(key, record); err != nil {
+		return 0, err
+	}
+	if record.LockedUntil.After(now) {
+		return record.LockedUntil.Sub(now), nil
+	}
+	return 0, nil
+}
+
+// Reset 清除失败记录
+func (l *Limiter) Reset(key string) error {
+	return l.Store.Delete(key)
+}
+
+// now 当前时间
+func (l *Limiter) now() time.Time {
+	if l.Now != nil {
+		return l.Now()
+	}
+	return time.Now()
+}
+
+// delay 连续失败 failures 次后需等待的时间
+func (p Policy) delay(failures int) time.Duration {
+	if p.LockoutAttempts > 0 && failures >= p.LockoutAttempts {
+		return p.LockoutDuration
+	}
+	if failures <= p.FreeAttempts || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
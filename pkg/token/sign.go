@@ -0,0 +1,20 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Sign 使用 key 对 parts 生成 HMAC-SHA256 签名，用于生成带签名的链接
+func Sign(key []byte, parts ...string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 检查签名是否正确，使用常量时间比较
+func Verify(key []byte, signature string, parts ...string) bool {
+	return hmac.Equal([]byte(signature), []byte(Sign(key, parts...)))
+}
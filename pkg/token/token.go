@@ -0,0 +1,23 @@
+// Package token 生成随机令牌，数据库中只保存令牌的哈希值
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Generate 生成 32 字节的随机令牌，以十六进制字符串返回
+func Generate() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// Hash 令牌的 SHA-256 哈希值，用于保存和查找令牌
+func Hash(t string) string {
+	sum := sha256.Sum256([]byte(t))
+	return hex.EncodeToString(sum[:])
+}
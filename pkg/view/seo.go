@@ -0,0 +1,97 @@
+package view
+
+import (
+	"bytes"
+	"goblog/app/models/article"
+	"goblog/app/models/tag"
+	"goblog/pkg/config"
+	"goblog/pkg/seo"
+	"html"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// descriptionLength 文章页面描述的字数，搜索结果中通常只显示这么多
+const descriptionLength = 120
+
+// pageMeta 根据传给视图的数据生成页面元数据：标题取自视图定义的 title 模板，
+// 描述可通过 Description 指定；文章详情页面使用文章的摘要、链接和发布信息
+func pageMeta(r *http.Request, tmpl *template.Template, data D, isArticle bool) seo.Meta {
+	siteName := config.GetString("app.name")
+	meta := seo.Meta{
+		Title:       siteName,
+		Description: config.GetString("seo.description"),
+		Canonical:   canonicalURL(r),
+		Type:        "website",
+		SiteName:    siteName,
+		Image:       absoluteURL(config.GetString("seo.image")),
+		Twitter:     config.GetString("seo.twitter"),
+		NoIndex:     seo.Disallowed(r.URL.Path, seo.ParsePaths(config.GetString("seo.disallow"))),
+	}
+
+	// 1. 标题，模板输出的是转义后的 HTML，还原为文本后由布局模板重新转义
+	if t := tmpl.Lookup("title"); t != nil {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err == nil {
+			if title := strings.TrimSpace(html.UnescapeString(buf.String())); len(title) > 0 {
+				meta.Title = title
+			}
+		}
+	}
+	if description, ok := data["Description"].(string); ok && len(description) > 0 {
+		meta.Description = description
+	}
+
+	// 2. 文章详情页面
+	_article, ok := data["Article"].(article.Article)
+	if !isArticle || !ok || _article.ID == 0 {
+		return meta
+	}
+	if excerpt := _article.Excerpt(descriptionLength); len(excerpt) > 0 {
+		meta.Description = excerpt
+	}
+	meta.Canonical = _article.Link()
+	meta.Type = "article"
+	// 草稿和定时发布的文章只有作者可见，不应被收录
+	meta.NoIndex = meta.NoIndex || !_article.IsPublished()
+
+	post := &seo.BlogPosting{
+		Headline:    _article.Title,
+		Description: meta.Description,
+		URL:         meta.Canonical,
+		Image:       meta.Image,
+		Keywords:    tag.Names(_article.Tags),
+		Published:   _article.CreatedAt,
+		Modified:    _article.UpdatedAt,
+	}
+	if _article.PublishedAt != nil {
+		post.Published = *_article.PublishedAt
+	}
+	if _article.User.ID > 0 {
+		post.AuthorName = _article.User.Name
+		post.AuthorURL = _article.User.Link()
+	}
+	meta.Post = post
+	return meta
+}
+
+// canonicalURL 当前页面的规范链接，去掉查询参数，分页页面保留页码
+func canonicalURL(r *http.Request) string {
+	canonical := config.GetString("app.url") + r.URL.EscapedPath()
+	pageQuery := config.GetString("pagination.url_query")
+	if page, err := strconv.Atoi(r.URL.Query().Get(pageQuery)); err == nil && page > 1 {
+		canonical += "?" + url.Values{pageQuery: {strconv.Itoa(page)}}.Encode()
+	}
+	return canonical
+}
+
+// absoluteURL 相对于站点的路径加上 app.url
+func absoluteURL(s string) string {
+	if len(s) == 0 || strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return s
+	}
+	return config.GetString("app.url") + "/" + strings.TrimPrefix(s, "/")
+}
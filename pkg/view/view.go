@@ -1,14 +1,21 @@
 package view
 
 import (
+	"bytes"
+	"goblog/app/models/article"
 	"goblog/app/models/category"
 	"goblog/app/models/user"
+	"goblog/app/policies"
+	"goblog/pkg/antispam"
 	"goblog/pkg/auth"
+	"goblog/pkg/csrf"
 	"goblog/pkg/flash"
 	"goblog/pkg/logger"
 	"goblog/pkg/route"
 	"html/template"
 	"io"
+	"log"
+	"net/http"
 	"path/filepath"
 	"strings"
 )
@@ -17,36 +24,58 @@ import (
 type D map[string]interface{}
 
 // Render 渲染通用视图
-func Render(w io.Writer, data D, tplFiles ...string) {
-	RenderTemplate(w, "app", data, tplFiles...)
+func Render(w io.Writer, r *http.Request, data D, tplFiles ...string) {
+	RenderTemplate(w, r, "app", data, tplFiles...)
 }
 
 // RenderSimple 渲染简单的视图
-func RenderSimple(w io.Writer, data D, tplFiles ...string) {
-	RenderTemplate(w, "simple", data, tplFiles...)
+func RenderSimple(w io.Writer, r *http.Request, data D, tplFiles ...string) {
+	RenderTemplate(w, r, "simple", data, tplFiles...)
 }
 
-// RenderTemplate 渲染视图
-func RenderTemplate(w io.Writer, name string, data D, tplFiles ...string) {
+// RenderTemplate 渲染视图，登录状态、消息提示和模板中的权限判断均来自当前请求的会话
+func RenderTemplate(w io.Writer, r *http.Request, name string, data D, tplFiles ...string) {
 
-	// 1. 通用模板数据
-	data["isLogined"] = auth.Check()
-	data["flash"] = flash.All()
-	data["Users"], _ = user.All()
-	data["Categories"], _ = category.All()
+	// 1. 通用模板数据，侧栏数据使用 Sidebar 前缀，避免覆盖页面自己的同名数据
+	data["isLogined"] = auth.Check(r)
+	data["flash"] = flash.All(r)
+	data["SidebarUsers"], _ = user.All()
+	data["SidebarCategories"], _ = category.All()
+	data["TagCloud"], _ = article.TagCloud(30)
 
-	// 2. 生成模板文件
+	// 2. 生成模板文件，文章详情页面需输出文章的结构化数据
+	isArticle := false
+	for _, f := range tplFiles {
+		isArticle = isArticle || f == "articles.show"
+	}
 	allFiles := getTemplateFiles(tplFiles...)
 
 	// 3. 解析所有模板文件
 	tmpl, err := template.New("").
 		Funcs(template.FuncMap{
 			"RouteName2URL": route.Name2URL,
+			// 只有包含表单的页面才生成 CSRF 令牌，避免为每个未登录的访问都创建会话
+			"csrfField": func() template.HTML {
+				return csrf.Field(r)
+			},
+			"can": func(name string, resource ...interface{}) bool {
+				return policies.Allows(r, name, resource...)
+			},
+			"AntispamFields": func() template.HTML {
+				return antispam.Default.Fields()
+			},
 		}).ParseFiles(allFiles...)
 	logger.LogError(err)
 
-	// 4. 渲染模板
-	tmpl.ExecuteTemplate(w, name, data)
+	// 4. 页面的标题、描述等元数据，标题需在模板解析后才能生成
+	data["SEO"] = pageMeta(r, tmpl, data, isArticle)
+
+	// 5. 渲染模板，生成 CSRF 令牌时会写入会话 Cookie，需先渲染到缓冲区再输出
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
+		log.Println("渲染模板失败：", err)
+	}
+	w.Write(buf.Bytes())
 }
 
 func getTemplateFiles(tplFiles ...string) []string {
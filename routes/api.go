@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"goblog/app/http/controllers"
+	"goblog/app/http/middlewares"
+	"goblog/pkg/response"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterAPIRoutes 注册 API 相关路由，使用个人访问令牌认证，请求和响应均为 JSON
+func RegisterAPIRoutes(r *mux.Router) {
+	api := r.PathPrefix("/api/v1").Subrouter()
+	api.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, http.StatusNotFound, "接口不存在")
+	})
+	api.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response.Error(w, http.StatusMethodNotAllowed, "不支持该请求方法")
+	})
+
+	// API 文档
+	adc := new(controllers.APIDocsController)
+	r.HandleFunc("/api/openapi.json", adc.Spec).Methods("GET").Name("api.openapi")
+
+	// GraphQL，令牌可选，修改数据时在解析器中检查
+	gc := new(controllers.GraphQLController)
+	r.HandleFunc("/graphql", gc.Query).Methods("POST").Name("graphql")
+
+	read := middlewares.APIAuth("read")
+	write := middlewares.APIAuth("write")
+	admin := middlewares.APIAuth("admin")
+
+	// 文章
+	aac := new(controllers.APIArticlesController)
+	api.HandleFunc("/articles", read(aac.Index)).Methods("GET").Name("api.articles.index")
+	api.HandleFunc("/articles/{id:[0-9]+}", read(aac.Show)).Methods("GET").Name("api.articles.show")
+	api.HandleFunc("/articles", write(aac.Store)).Methods("POST").Name("api.articles.store")
+	api.HandleFunc("/articles/{id:[0-9]+}", write(aac.Update)).Methods("PATCH", "PUT").Name("api.articles.update")
+	api.HandleFunc("/articles/{id:[0-9]+}", write(aac.Delete)).Methods("DELETE").Name("api.articles.delete")
+
+	// 分类
+	acc := new(controllers.APICategoriesController)
+	api.HandleFunc("/categories", read(acc.Index)).Methods("GET").Name("api.categories.index")
+	api.HandleFunc("/categories/{id:[0-9]+}", read(acc.Show)).Methods("GET").Name("api.categories.show")
+	api.HandleFunc("/categories", admin(acc.Store)).Methods("POST").Name("api.categories.store")
+	api.HandleFunc("/categories/{id:[0-9]+}", admin(acc.Update)).Methods("PATCH", "PUT").Name("api.categories.update")
+	api.HandleFunc("/categories/{id:[0-9]+}", admin(acc.Delete)).Methods("DELETE").Name("api.categories.delete")
+
+	// 用户
+	auc := new(controllers.APIUsersController)
+	api.HandleFunc("/user", read(auc.Me)).Methods("GET").Name("api.user")
+	api.HandleFunc("/users", read(auc.Index)).Methods("GET").Name("api.users.index")
+	api.HandleFunc("/users/{id:[0-9]+}", read(auc.Show)).Methods("GET").Name("api.users.show")
+	api.HandleFunc("/users", admin(auc.Store)).Methods("POST").Name("api.users.store")
+	api.HandleFunc("/users/{id:[0-9]+}", admin(auc.Update)).Methods("PATCH", "PUT").Name("api.users.update")
+	api.HandleFunc("/users/{id:[0-9]+}", admin(auc.Delete)).Methods("DELETE").Name("api.users.delete")
+}
@@ -20,11 +20,19 @@ func RegisterWebRoutes(r *mux.Router) {
 	r.HandleFunc("/", ac.Index).Methods("GET").Name("home")
 	r.HandleFunc("/articles/{id:[0-9]+}", ac.Show).Methods("GET").Name("articles.show")
 	r.HandleFunc("/articles", ac.Index).Methods("GET").Name("articles.index")
-	r.HandleFunc("/articles/create", middlewares.Auth(ac.Create)).Methods("GET").Name("articles.create")
-	r.HandleFunc("/articles", middlewares.Auth(ac.Store)).Methods("POST").Name("articles.store")
-	r.HandleFunc("/articles/{id:[0-9]+}/edit", middlewares.Auth(ac.Edit)).Methods("GET").Name("articles.edit")
-	r.HandleFunc("/articles/{id:[0-9]+}", middlewares.Auth(ac.Update)).Methods("POST").Name("articles.update")
-	r.HandleFunc("/articles/{id:[0-9]+}/delete", middlewares.Auth(ac.Delete)).Methods("POST").Name("articles.delete")
+	r.HandleFunc("/articles/create", middlewares.Verified(middlewares.Can("article.create")(ac.Create))).Methods("GET").Name("articles.create")
+	r.HandleFunc("/articles", middlewares.Verified(middlewares.Can("article.create")(ac.Store))).Methods("POST").Name("articles.store")
+	r.HandleFunc("/articles/{id:[0-9]+}/edit", middlewares.Verified(middlewares.Can("article.update")(ac.Edit))).Methods("GET").Name("articles.edit")
+	r.HandleFunc("/articles/{id:[0-9]+}", middlewares.Verified(middlewares.Can("article.update")(ac.Update))).Methods("POST").Name("articles.update")
+	r.HandleFunc("/articles/{id:[0-9]+}/delete", middlewares.Verified(middlewares.Can("article.delete")(ac.Delete))).Methods("POST").Name("articles.delete")
+	// slug 路由需注册在 /articles/create 之后，避免被其匹配
+	r.HandleFunc("/articles/{slug:[a-z0-9-]+}", ac.ShowBySlug).Methods("GET").Name("articles.slug")
+
+	// 文章历史版本
+	rc := new(controllers.RevisionsController)
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions", middlewares.Can("article.update")(rc.Index)).Methods("GET").Name("articles.revisions")
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions/diff", middlewares.Can("article.update")(rc.Diff)).Methods("GET").Name("articles.revisions.diff")
+	r.HandleFunc("/articles/{id:[0-9]+}/revisions/{revision:[0-9]+}/restore", middlewares.Can("article.update")(rc.Restore)).Methods("POST").Name("articles.revisions.restore")
 
 	// 用户相关
 	uc := new(controllers.UserController)
@@ -45,12 +53,116 @@ func RegisterWebRoutes(r *mux.Router) {
 	r.HandleFunc("/auth/dologin", middlewares.Guest(auc.DoLogin)).Methods("POST").Name("auth.dologin")
 	r.HandleFunc("/auth/logout", middlewares.Auth(auc.Logout)).Methods("POST").Name("auth.logout")
 
+	// 邮箱验证
+	vc := new(controllers.VerificationController)
+	r.HandleFunc("/auth/verify", middlewares.Auth(vc.Notice)).Methods("GET").Name("auth.verify.notice")
+	r.HandleFunc("/auth/verify/resend", middlewares.Auth(vc.Resend)).Methods("POST").Name("auth.verify.resend")
+	r.HandleFunc("/auth/verify/{id:[0-9]+}", vc.Verify).Methods("GET").Name("auth.verify")
+
+	// 账号设置
+	acct := new(controllers.AccountController)
+	r.HandleFunc("/account", middlewares.Auth(acct.Edit)).Methods("GET").Name("account.edit")
+	r.HandleFunc("/account/email", middlewares.Auth(acct.UpdateEmail)).Methods("POST").Name("account.email")
+	r.HandleFunc("/account/password", middlewares.Auth(acct.UpdatePassword)).Methods("POST").Name("account.password")
+
+	// 已登录的设备
+	ssc := new(controllers.SessionsController)
+	r.HandleFunc("/settings/sessions", middlewares.Auth(ssc.Index)).Methods("GET").Name("settings.sessions")
+	r.HandleFunc("/settings/sessions/{id:[0-9]+}/revoke", middlewares.Auth(ssc.Revoke)).Methods("POST").Name("settings.sessions.revoke")
+	r.HandleFunc("/settings/sessions/revoke-others", middlewares.Auth(ssc.RevokeOthers)).Methods("POST").Name("settings.sessions.revoke_others")
+
+	// 外部身份提供方登录和关联
+	idc := new(controllers.IdentitiesController)
+	r.HandleFunc("/auth/oidc/{provider:[a-z0-9_-]+}", middlewares.Guest(idc.Redirect)).Methods("GET").Name("auth.oidc.redirect")
+	r.HandleFunc("/auth/oidc/{provider:[a-z0-9_-]+}/callback", idc.Callback).Methods("GET").Name("auth.oidc.callback")
+	r.HandleFunc("/settings/identities", middlewares.Auth(idc.Index)).Methods("GET").Name("settings.identities")
+	r.HandleFunc("/settings/identities/{provider:[a-z0-9_-]+}/link", middlewares.Auth(idc.Link)).Methods("POST").Name("settings.identities.link")
+	r.HandleFunc("/settings/identities/{id:[0-9]+}/unlink", middlewares.Auth(idc.Unlink)).Methods("POST").Name("settings.identities.unlink")
+
+	// 个人访问令牌
+	tkc := new(controllers.TokensController)
+	r.HandleFunc("/settings/tokens", middlewares.Auth(tkc.Index)).Methods("GET").Name("settings.tokens")
+	r.HandleFunc("/settings/tokens", middlewares.Auth(tkc.Store)).Methods("POST").Name("settings.tokens.store")
+	r.HandleFunc("/settings/tokens/{id:[0-9]+}/revoke", middlewares.Auth(tkc.Revoke)).Methods("POST").Name("settings.tokens.revoke")
+
+	// 两步验证
+	tfc := new(controllers.TwoFactorController)
+	r.HandleFunc("/auth/two-factor", middlewares.Guest(tfc.Challenge)).Methods("GET").Name("auth.two_factor")
+	r.HandleFunc("/auth/two-factor", middlewares.Guest(tfc.DoChallenge)).Methods("POST").Name("auth.two_factor.verify")
+	r.HandleFunc("/account/two-factor", middlewares.Auth(tfc.Show)).Methods("GET").Name("account.two_factor")
+	r.HandleFunc("/account/two-factor/enable", middlewares.Auth(tfc.Enable)).Methods("POST").Name("account.two_factor.enable")
+	r.HandleFunc("/account/two-factor/confirm", middlewares.Auth(tfc.Confirm)).Methods("POST").Name("account.two_factor.confirm")
+	r.HandleFunc("/account/two-factor/disable", middlewares.Auth(tfc.Disable)).Methods("POST").Name("account.two_factor.disable")
+	r.HandleFunc("/account/two-factor/recovery-codes", middlewares.Auth(tfc.RegenerateCodes)).Methods("POST").Name("account.two_factor.codes")
+
+	// 找回密码
+	pwc := new(controllers.PasswordController)
+	r.HandleFunc("/auth/password/forgot", middlewares.Guest(pwc.Forgot)).Methods("GET").Name("auth.password.forgot")
+	r.HandleFunc("/auth/password/email", middlewares.Guest(pwc.SendResetLink)).Methods("POST").Name("auth.password.email")
+	r.HandleFunc("/auth/password/reset/{token:[0-9a-f]{64}}", middlewares.Guest(pwc.ResetForm)).Methods("GET").Name("auth.password.reset")
+	r.HandleFunc("/auth/password/reset/{token:[0-9a-f]{64}}", middlewares.Guest(pwc.Reset)).Methods("POST").Name("auth.password.update")
+
 	// 文章分类
 	cc := new(controllers.CategoriesController)
-	r.HandleFunc("/categories/create", middlewares.Auth(cc.Create)).Methods("GET").Name("categories.create")
-	r.HandleFunc("/categories", middlewares.Auth(cc.Store)).Methods("POST").Name("categories.store")
+	r.HandleFunc("/categories/create", middlewares.Can("category.create")(cc.Create)).Methods("GET").Name("categories.create")
+	r.HandleFunc("/categories", middlewares.Can("category.create")(cc.Store)).Methods("POST").Name("categories.store")
 	r.HandleFunc("/categories/{id:[0-9]+}", middlewares.Auth(cc.Show)).Methods("GET").Name("categories.show")
+	r.HandleFunc("/categories/{slug:[a-z0-9-]+}", middlewares.Auth(cc.ShowBySlug)).Methods("GET").Name("categories.slug")
+
+	// 文章标签
+	tc := new(controllers.TagsController)
+	r.HandleFunc("/tags", tc.Index).Methods("GET").Name("tags.index")
+	r.HandleFunc("/tags/{slug:[a-z0-9-]+}", tc.Show).Methods("GET").Name("tags.show")
+	r.HandleFunc("/tags/{id:[0-9]+}/rename", middlewares.Can("tag.manage")(tc.Rename)).Methods("POST").Name("tags.rename")
+	r.HandleFunc("/tags/{id:[0-9]+}/merge", middlewares.Can("tag.manage")(tc.Merge)).Methods("POST").Name("tags.merge")
+
+	// 文章评论
+	cmc := new(controllers.CommentsController)
+	r.HandleFunc("/articles/{id:[0-9]+}/comments", middlewares.Verified(cmc.Store)).Methods("POST").Name("comments.store")
+	r.HandleFunc("/comments", middlewares.Can("comment.moderate")(cmc.Index)).Methods("GET").Name("comments.index")
+	r.HandleFunc("/comments/{id:[0-9]+}/approve", middlewares.Can("comment.moderate")(cmc.Approve)).Methods("POST").Name("comments.approve")
+	r.HandleFunc("/comments/{id:[0-9]+}/reject", middlewares.Can("comment.moderate")(cmc.Reject)).Methods("POST").Name("comments.reject")
+	r.HandleFunc("/comments/{id:[0-9]+}/spam", middlewares.Can("comment.moderate")(cmc.Spam)).Methods("POST").Name("comments.spam")
+	r.HandleFunc("/comments/{id:[0-9]+}/ham", middlewares.Can("comment.moderate")(cmc.Ham)).Methods("POST").Name("comments.ham")
+	r.HandleFunc("/comments/{id:[0-9]+}/delete", middlewares.Can("comment.moderate")(cmc.Delete)).Methods("POST").Name("comments.delete")
+
+	// 订阅源
+	fc := new(controllers.FeedsController)
+	r.HandleFunc("/{format:feed\\.xml|atom\\.xml|feed\\.json}", fc.Index).Methods("GET", "HEAD").Name("feeds.index")
+	r.HandleFunc("/categories/{id:[0-9]+}/{format:feed|feed\\.xml|atom\\.xml|feed\\.json}", fc.Category).Methods("GET", "HEAD").Name("feeds.category")
+	r.HandleFunc("/tags/{slug:[a-z0-9-]+}/{format:feed|feed\\.xml|atom\\.xml|feed\\.json}", fc.Tag).Methods("GET", "HEAD").Name("feeds.tag")
+	r.HandleFunc("/users/{id:[0-9]+}/{format:feed|feed\\.xml|atom\\.xml|feed\\.json}", fc.User).Methods("GET", "HEAD").Name("feeds.user")
+
+	// 站点地图和 robots.txt
+	seoc := new(controllers.SEOController)
+	r.HandleFunc("/sitemap.xml", seoc.Sitemap).Methods("GET").Name("seo.sitemap")
+	r.HandleFunc("/sitemaps/pages.xml", seoc.SitemapPages).Methods("GET").Name("seo.sitemap.pages")
+	r.HandleFunc("/sitemaps/articles-{page:[0-9]+}.xml", seoc.SitemapArticles).Methods("GET").Name("seo.sitemap.articles")
+	r.HandleFunc("/robots.txt", seoc.Robots).Methods("GET").Name("seo.robots")
+
+	// 全文搜索
+	sc := new(controllers.SearchController)
+	r.HandleFunc("/search", sc.Index).Methods("GET").Name("search")
+
+	// 管理后台
+	adc := new(controllers.AdminController)
+	r.HandleFunc("/admin", middlewares.Can("admin.access")(adc.Dashboard)).Methods("GET").Name("admin.dashboard")
+	amu := new(controllers.AdminUsersController)
+	r.HandleFunc("/admin/users", middlewares.Can("admin.access")(amu.Index)).Methods("GET").Name("admin.users")
+	r.HandleFunc("/admin/users/bulk", middlewares.Can("admin.access")(amu.Bulk)).Methods("POST").Name("admin.users.bulk")
+	aac := new(controllers.AdminArticlesController)
+	r.HandleFunc("/admin/articles", middlewares.Can("admin.access")(aac.Index)).Methods("GET").Name("admin.articles")
+	r.HandleFunc("/admin/articles/bulk", middlewares.Can("admin.access")(aac.Bulk)).Methods("POST").Name("admin.articles.bulk")
+	acc := new(controllers.AdminCategoriesController)
+	r.HandleFunc("/admin/categories", middlewares.Can("admin.access")(acc.Index)).Methods("GET").Name("admin.categories")
+	r.HandleFunc("/admin/categories/bulk", middlewares.Can("admin.access")(acc.Bulk)).Methods("POST").Name("admin.categories.bulk")
+	arc := new(controllers.AdminRolesController)
+	r.HandleFunc("/admin/roles", middlewares.Can("admin.access")(arc.Index)).Methods("GET").Name("admin.roles")
+	r.HandleFunc("/admin/roles/{id:[0-9]+}/two-factor", middlewares.Can("admin.access")(arc.TwoFactor)).Methods("POST").Name("admin.roles.two_factor")
 
 	// 开始会话
 	r.Use(middlewares.StartSession)
+
+	// 检查 CSRF 令牌，需在会话开始之后
+	r.Use(middlewares.VerifyCSRFToken)
 }
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"goblog/app/models/accesstoken"
+	"goblog/app/models/role"
+	"goblog/app/policies"
+	"goblog/pkg/auth"
+	"goblog/pkg/model"
+	"goblog/pkg/token"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAccessTokenScopes(t *testing.T) {
+	read := accesstoken.AccessToken{Scopes: "read"}
+	write := accesstoken.AccessToken{Scopes: "write"}
+	admin := accesstoken.AccessToken{Scopes: "read,admin"}
+
+	// 高级别的权限范围包含低级别
+	assert.True(t, read.Can(accesstoken.ScopeRead))
+	assert.False(t, read.Can(accesstoken.ScopeWrite))
+	assert.True(t, write.Can(accesstoken.ScopeRead))
+	assert.False(t, write.Can(accesstoken.ScopeAdmin))
+	assert.True(t, admin.Can(accesstoken.ScopeWrite))
+
+	// 未定义的权限范围不匹配
+	assert.False(t, admin.Can("delete"))
+	assert.False(t, accesstoken.AccessToken{Scopes: "delete"}.Can(accesstoken.ScopeRead))
+	assert.False(t, accesstoken.AccessToken{}.Can(accesstoken.ScopeRead))
+	assert.True(t, accesstoken.IsValidScope("write"))
+	assert.False(t, accesstoken.IsValidScope(""))
+}
+
+func TestAccessTokenHashing(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+
+	_token := accesstoken.AccessToken{UserID: alice.ID, Name: "cli", Scopes: accesstoken.ScopeRead}
+	plain, err := _token.Create()
+	require.NoError(t, err)
+
+	// 1. 数据库只保存哈希值
+	assert.True(t, strings.HasPrefix(plain, accesstoken.Prefix))
+	assert.Equal(t, token.Hash(plain), _token.TokenHash)
+	var count int64
+	require.NoError(t, model.DB.Model(&accesstoken.AccessToken{}).Where("token_hash = ?", plain).Count(&count).Error)
+	assert.Zero(t, count)
+
+	// 2. 通过明文查找，忽略首尾空白
+	found, err := accesstoken.FindByToken(" " + plain + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, _token.ID, found.ID)
+	_, err = accesstoken.FindByToken(plain + "x")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}
+
+func TestAccessTokenExpiry(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+
+	past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+	expired := accesstoken.AccessToken{UserID: alice.ID, Name: "old", Scopes: accesstoken.ScopeRead, ExpiresAt: &past}
+	expiredPlain, err := expired.Create()
+	require.NoError(t, err)
+	valid := accesstoken.AccessToken{UserID: alice.ID, Name: "new", Scopes: accesstoken.ScopeRead, ExpiresAt: &future}
+	validPlain, err := valid.Create()
+	require.NoError(t, err)
+
+	assert.True(t, expired.IsExpired())
+	assert.False(t, valid.IsExpired())
+	assert.False(t, accesstoken.AccessToken{}.IsExpired())
+
+	_, err = accesstoken.FindByToken(expiredPlain)
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+	_, err = accesstoken.FindByToken(validPlain)
+	assert.NoError(t, err)
+
+	// 过期的令牌不能通过 API 认证
+	req := httptest.NewRequest("GET", "/api/v1/me", nil)
+	req.Header.Set("Authorization", "Bearer "+expiredPlain)
+	_, err = auth.AuthenticateToken(req)
+	assert.Equal(t, auth.ErrInvalidToken, err)
+}
+
+func TestTwoFactorRequiredForAllEntryPoints(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	_token := accesstoken.AccessToken{UserID: alice.ID, Name: "cli", Scopes: accesstoken.ScopeWrite}
+	plain, err := _token.Create()
+	require.NoError(t, err)
+
+	author, err := role.GetByName(role.Author)
+	require.NoError(t, err)
+	require.NoError(t, author.SetRequireTwoFactor(true))
+
+	// 1. 通过令牌认证的用户同样需要先启用两步验证
+	req := httptest.NewRequest("POST", "/api/v1/articles", nil)
+	req.Header.Set("Authorization", "Bearer "+plain)
+	req, err = auth.AuthenticateToken(req)
+	require.NoError(t, err)
+	tokenUser := auth.TokenUser(req)
+	assert.True(t, tokenUser.NeedsTwoFactorSetup())
+	assert.False(t, policies.AllowsUser(tokenUser, "article.create"))
+
+	// 2. 启用两步验证后恢复角色权限
+	now := time.Now()
+	tokenUser.TwoFactorConfirmedAt = &now
+	assert.True(t, policies.AllowsUser(tokenUser, "article.create"))
+
+	// 3. 角色不再要求时也恢复
+	require.NoError(t, author.SetRequireTwoFactor(false))
+	assert.True(t, policies.AllowsUser(alice, "article.create"))
+}
@@ -0,0 +1,197 @@
+package tests
+
+import (
+	"fmt"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/comment"
+	"goblog/app/models/role"
+	"goblog/app/models/user"
+	"goblog/pkg/pagination"
+	"goblog/pkg/route"
+	"goblog/pkg/session"
+	"goblog/pkg/types"
+	"goblog/routes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// adminQuery 以 rawQuery 作为查询参数读取后台列表参数
+func adminQuery(rawQuery string, sortable []string, filters ...string) (pagination.Query, *httptest.ResponseRecorder) {
+	r := httptest.NewRequest("GET", "/admin/list?"+rawQuery, nil)
+	return pagination.NewQuery(r, "/admin/list", sortable, filters...), httptest.NewRecorder()
+}
+
+// userIDs 用户的 ID 列表
+func userIDs(users []user.User) []uint64 {
+	ids := make([]uint64, len(users))
+	for i, u := range users {
+		ids[i] = u.ID
+	}
+	return ids
+}
+
+func TestPaginationQuery(t *testing.T) {
+	q, _ := adminQuery("q=+go+&sort=name&order=asc&role=2&other=1", user.AdminSortable, "role", "banned")
+
+	// 1. 只接受白名单中的排序字段和筛选字段
+	assert.Equal(t, "go", q.Keyword)
+	assert.Equal(t, "name asc", q.OrderBy())
+	assert.Equal(t, "2", q.Get("role"))
+	assert.Empty(t, q.Get("other"))
+	assert.Equal(t, "/admin/list?order=asc&q=go&role=2&sort=name", q.URL())
+	assert.Equal(t, "/admin/list?order=desc&q=go&role=2&sort=name", q.SortURL("name"))
+
+	// 2. 未知的排序字段使用默认字段倒序
+	q, _ = adminQuery("sort=password", user.AdminSortable)
+	assert.Equal(t, "id desc", q.OrderBy())
+}
+
+func TestAdminUserList(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	carol := createUser(t, "carol")
+	editor, err := role.GetByName(role.Editor)
+	require.NoError(t, err)
+
+	// 1. 批量封禁和修改角色
+	n, err := user.SetBanned([]uint64{alice.ID, bob.ID}, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	_, err = user.SetBanned([]uint64{bob.ID}, false)
+	require.NoError(t, err)
+	_, err = user.SetRole([]uint64{carol.ID}, editor.ID)
+	require.NoError(t, err)
+
+	// 2. 按关键字、封禁状态和角色筛选
+	r := httptest.NewRequest("GET", "/admin/users", nil)
+	q, _ := adminQuery("q=example.com&sort=id&order=asc", user.AdminSortable, "role", "banned")
+	users, _, err := user.GetForAdmin(r, q, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{alice.ID, bob.ID, carol.ID}, userIDs(users))
+
+	q, _ = adminQuery("banned=1", user.AdminSortable, "role", "banned")
+	users, _, err = user.GetForAdmin(r, q, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{alice.ID}, userIDs(users))
+
+	q, _ = adminQuery("banned=0&role="+types.Uint64ToString(editor.ID), user.AdminSortable, "role", "banned")
+	users, _, err = user.GetForAdmin(r, q, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{carol.ID}, userIDs(users))
+}
+
+func TestAdminArticleList(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	// 测试文章默认使用 ID 为 1 的分类
+	require.NoError(t, (&category.Category{Name: "默认分类"}).Create())
+	news := category.Category{Name: "新闻"}
+	require.NoError(t, news.Create())
+
+	golang := createArticle(t, alice.ID, "golang tips", article.StatusPublished, nil)
+	draft := createArticle(t, alice.ID, "golang draft", article.StatusDraft, nil)
+	other := createArticle(t, bob.ID, "rust tips", article.StatusPublished, nil)
+
+	// 1. 后台列表包括所有状态的文章
+	r := httptest.NewRequest("GET", "/admin/articles", nil)
+	q, _ := adminQuery("q=golang&sort=id&order=asc", article.AdminSortable, "status", "category_id", "user_id")
+	articles, _, err := article.GetForAdmin(r, q, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{golang.ID, draft.ID}, articleIDs(articles))
+
+	q, _ = adminQuery("status=draft", article.AdminSortable, "status", "category_id", "user_id")
+	articles, _, err = article.GetForAdmin(r, q, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{draft.ID}, articleIDs(articles))
+
+	// 2. 批量修改分类后可按分类筛选
+	n, err := article.SetCategory([]uint64{golang.ID, other.ID}, news.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	q, _ = adminQuery("category_id="+types.Uint64ToString(news.ID), article.AdminSortable, "status", "category_id", "user_id")
+	articles, _, err = article.GetForAdmin(r, q, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{other.ID, golang.ID}, articleIDs(articles))
+
+	counts, err := article.CountByCategory([]uint64{1, news.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint64]int64{1: 1, news.ID: 2}, counts)
+}
+
+func TestCommentDetachUser(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	_article := createArticle(t, alice.ID, "hello", article.StatusPublished, nil)
+	_comment := comment.Comment{ArticleID: _article.ID, UserID: &bob.ID, Body: "hi", Status: comment.StatusApproved}
+	require.NoError(t, _comment.Create())
+
+	// 删除用户前评论转为游客评论，保留名称和邮箱
+	require.NoError(t, comment.DetachUser(bob))
+	_, err := bob.Delete()
+	require.NoError(t, err)
+
+	detached, err := comment.Get(types.Uint64ToString(_comment.ID))
+	require.NoError(t, err)
+	assert.Nil(t, detached.UserID)
+	assert.Equal(t, "bob", detached.GuestName)
+	assert.Equal(t, "bob@example.com", detached.GuestEmail)
+}
+
+func TestAdminListPagesRenderFilteredRows(t *testing.T) {
+	setupDB(t)
+	chdirRoot(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	admin := createUserWithRole(t, "admin", role.Admin)
+	createUser(t, "bob")
+	createUser(t, "carol")
+	require.NoError(t, (&category.Category{Name: "新闻"}).Create())
+	require.NoError(t, (&category.Category{Name: "随笔"}).Create())
+
+	// 1. 以管理员身份登录
+	cookies := loginCookies(t, admin)
+
+	get := func(path string) string {
+		req := httptest.NewRequest("GET", path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code, path)
+		return rec.Body.String()
+	}
+
+	// 2. 列表只包含筛选后的行，侧栏的作者和分类列表不会覆盖页面数据
+	body := get("/admin/users?q=bob")
+	assert.Contains(t, body, "bob@example.com")
+	assert.NotContains(t, body, "carol@example.com")
+	assert.NotContains(t, body, "admin@example.com")
+
+	// 3. 每页 20 个用户，第二页只有第 21 个用户
+	for i := 1; i <= 18; i++ {
+		createUser(t, fmt.Sprintf("user%02d", i))
+	}
+	body = get("/admin/users?sort=id&order=asc&page=2")
+	assert.Contains(t, body, "user18@example.com")
+	assert.NotContains(t, body, "user17@example.com")
+	assert.NotContains(t, body, "admin@example.com")
+
+	// 4. 分类列表同样只包含搜索结果
+	body = get("/admin/categories?q=" + url.QueryEscape("新闻"))
+	assert.Contains(t, body, "?category_id=1")
+	assert.NotContains(t, body, "?category_id=2")
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"goblog/pkg/antispam"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAntispamFeatures(t *testing.T) {
+	features := antispam.Features(antispam.Sample{
+		Content: "cheap pills http://a.example http://b.example http://c.example",
+		Email:   "bot@Spam.Example",
+		IP:      "203.0.113.57",
+	})
+
+	assert.Contains(t, features, "cheap")
+	assert.Contains(t, features, "links:3-5")
+	assert.Contains(t, features, "domain:spam.example")
+	assert.Contains(t, features, "ip:203.0.113.0/24")
+}
+
+func TestAntispamClassifier(t *testing.T) {
+	filter := antispam.New(nil)
+
+	spam := antispam.Sample{Content: "便宜代开发票 http://spam.example 加微信", Email: "a@spam.example"}
+	ham := antispam.Sample{Content: "这篇文章写得很清楚，学到了 goroutine 的用法", Email: "reader@example.com"}
+
+	// 1. 训练不足时不做判断
+	assert.False(t, filter.IsSpam(spam))
+	assert.False(t, filter.Ready())
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, filter.Train(antispam.Spam, spam))
+		assert.NoError(t, filter.Train(antispam.Ham, ham))
+	}
+
+	// 2. 训练后能区分相似的内容
+	assert.True(t, filter.Ready())
+	assert.True(t, filter.IsSpam(antispam.Sample{Content: "代开发票，加微信 http://spam.example"}))
+	assert.False(t, filter.IsSpam(antispam.Sample{Content: "写得很清楚，goroutine 部分很有帮助"}))
+}
+
+func TestAntispamGuard(t *testing.T) {
+	guard := antispam.Guard{Key: []byte("secret")}
+
+	post := func(values url.Values) *http.Request {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(values.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	token := guard.Token()
+	assert.NoError(t, guard.Check(post(url.Values{antispam.TimestampField: {token}})))
+
+	// 1. 蜜罐字段被填写
+	assert.Equal(t, antispam.ErrHoneypot, guard.Check(post(url.Values{
+		antispam.TimestampField: {token},
+		antispam.HoneypotField:  {"http://spam.example"},
+	})))
+
+	// 2. 篡改时间戳
+	forged := "1." + strings.SplitN(token, ".", 2)[1]
+	assert.Equal(t, antispam.ErrInvalidTimestamp, guard.Check(post(url.Values{antispam.TimestampField: {forged}})))
+	assert.Equal(t, antispam.ErrInvalidTimestamp, guard.Check(post(url.Values{})))
+
+	// 3. 提交过快
+	guard.MinSubmitTime = time.Minute
+	assert.Equal(t, antispam.ErrTooFast, guard.Check(post(url.Values{antispam.TimestampField: {token}})))
+}
+
+func TestAntispamUntrain(t *testing.T) {
+	filter := antispam.New(nil)
+	spam := antispam.Sample{Content: "便宜代开发票 http://spam.example 加微信", Email: "a@spam.example"}
+	ham := antispam.Sample{Content: "这篇文章写得很清楚，学到了 goroutine 的用法", Email: "reader@example.com"}
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, filter.Train(antispam.Spam, spam))
+		assert.NoError(t, filter.Train(antispam.Ham, ham))
+	}
+	assert.True(t, filter.Ready())
+
+	// 撤销训练后样本数量不足，不再做判断；计数不会小于 0
+	assert.NoError(t, filter.Untrain(antispam.Spam, spam))
+	assert.False(t, filter.Ready())
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, filter.Untrain(antispam.Spam, spam))
+	}
+	assert.NoError(t, filter.Train(antispam.Spam, spam))
+	assert.Equal(t, 0.5, filter.SpamProbability(antispam.Features(spam)))
+}
@@ -0,0 +1,209 @@
+package tests
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/user"
+	"goblog/bootstrap"
+	"goblog/pkg/model"
+	"goblog/pkg/route"
+	"goblog/routes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// createArticle 创建一篇指定状态和发布时间的文章
+func createArticle(t *testing.T, userID uint64, title, status string, publishedAt *time.Time) article.Article {
+	_article := article.Article{Title: title, Body: title + " body", UserID: userID, CategoryID: 1}
+	_article.SetStatus(status, publishedAt)
+	require.NoError(t, _article.Create())
+	return _article
+}
+
+// createUser 创建一个已验证邮箱的用户，密码为 secret123
+// 使用最低 cost 预先生成的哈希，避免每个测试都执行一次高 cost 的 bcrypt
+func createUser(t *testing.T, name string) user.User {
+	now := time.Now()
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret123"), bcrypt.MinCost)
+	require.NoError(t, err)
+	_user := user.User{Name: name, Email: name + "@example.com", Password: string(hash), EmailVerifiedAt: &now}
+	require.NoError(t, _user.Create())
+	return _user
+}
+
+// articleIDs 文章的 ID 列表
+func articleIDs(articles []article.Article) []uint64 {
+	ids := make([]uint64, len(articles))
+	for i, a := range articles {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func TestArticleStatusFiltering(t *testing.T) {
+	setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	author := createUser(t, "alice")
+
+	future := time.Now().Add(time.Hour)
+	published := createArticle(t, author.ID, "published", article.StatusPublished, nil)
+	draft := createArticle(t, author.ID, "draft", article.StatusDraft, nil)
+	scheduled := createArticle(t, author.ID, "scheduled", article.StatusScheduled, &future)
+	archived := createArticle(t, author.ID, "archived", article.StatusArchived, nil)
+
+	// 1. 首页和分类列表只包含已发布的文章
+	r := httptest.NewRequest("GET", "/", nil)
+	articles, _, err := article.GetAll(r, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{published.ID}, articleIDs(articles))
+
+	articles, _, err = article.GetByCategoryID("1", route.Name2URL("categories.show", "id", "1"), r, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{published.ID}, articleIDs(articles))
+
+	// 2. 其他人查看作者的文章列表时只有已发布的文章
+	articles, err = article.GetByUserID(author.GetStringID(), false)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{published.ID}, articleIDs(articles))
+
+	// 3. 作者本人可以看到自己的草稿、定时和已归档的文章
+	articles, err = article.GetByUserID(author.GetStringID(), true)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uint64{published.ID, draft.ID, scheduled.ID, archived.ID}, articleIDs(articles))
+}
+
+func TestArticleSetStatus(t *testing.T) {
+	var _article article.Article
+
+	// 1. 发布时记录发布时间，再次保存时不变
+	_article.SetStatus(article.StatusPublished, nil)
+	require.NotNil(t, _article.PublishedAt)
+	publishedAt := *_article.PublishedAt
+	_article.SetStatus(article.StatusPublished, nil)
+	assert.Equal(t, publishedAt, *_article.PublishedAt)
+	assert.True(t, _article.IsPublished())
+
+	// 2. 定时发布使用预定的时间，改为草稿时清空
+	future := time.Now().Add(time.Hour)
+	_article.SetStatus(article.StatusScheduled, &future)
+	assert.Equal(t, future, *_article.PublishedAt)
+	assert.False(t, _article.IsPublished())
+	_article.SetStatus(article.StatusDraft, nil)
+	assert.Nil(t, _article.PublishedAt)
+}
+
+func TestPublishScheduled(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+
+	future := time.Now().Add(time.Hour)
+	due := createArticle(t, author.ID, "due", article.StatusScheduled, &future)
+	later := createArticle(t, author.ID, "later", article.StatusScheduled, &future)
+
+	// 1. 未到预定时间时不发布
+	rows, err := article.PublishScheduled()
+	require.NoError(t, err)
+	assert.Zero(t, rows)
+
+	// 2. 到达预定时间的文章被发布，其他定时文章不变
+	require.NoError(t, model.DB.Model(&due).UpdateColumn("published_at", time.Now().Add(-time.Minute)).Error)
+	rows, err = article.PublishScheduled()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	reloaded, err := article.Get(due.GetStringID())
+	require.NoError(t, err)
+	assert.Equal(t, article.StatusPublished, reloaded.Status)
+	reloaded, err = article.Get(later.GetStringID())
+	require.NoError(t, err)
+	assert.Equal(t, article.StatusScheduled, reloaded.Status)
+}
+
+func TestArticleListsOrderByPublishedAt(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+
+	// 定时文章先创建，到期发布的时间晚于之后直接发布的文章
+	future := time.Now().Add(time.Hour)
+	scheduled := createArticle(t, author.ID, "scheduled", article.StatusScheduled, &future)
+	published := createArticle(t, author.ID, "published", article.StatusPublished, nil)
+
+	articles, err := article.GetByUserID(author.GetStringID(), false)
+	require.NoError(t, err)
+	assert.Len(t, articles, 1)
+
+	// 预定时间到达后由调度器发布
+	require.NoError(t, model.DB.Model(&scheduled).UpdateColumn("published_at", time.Now()).Error)
+	rows, err := article.PublishScheduled()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	// 按发布时间排列，而不是创建时间
+	articles, err = article.GetByUserID(author.GetStringID(), false)
+	require.NoError(t, err)
+	if assert.Len(t, articles, 2) {
+		assert.Equal(t, scheduled.ID, articles[0].ID)
+		assert.Equal(t, published.ID, articles[1].ID)
+	}
+	articles, err = article.GetForFeed(article.CursorFilter{}, 10)
+	require.NoError(t, err)
+	if assert.Len(t, articles, 2) {
+		assert.Equal(t, scheduled.ID, articles[0].ID)
+	}
+}
+
+func TestRevisionRecordsEditor(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	editor := createUser(t, "bob")
+
+	_article := createArticle(t, author.ID, "title", article.StatusPublished, nil)
+	_article.Body = "edited by bob"
+	_, err := _article.Update(editor.ID)
+	require.NoError(t, err)
+
+	// 新版本在前，修改者为编辑，创建时的版本为作者
+	revisions, err := article.GetRevisions(_article.ID)
+	require.NoError(t, err)
+	if assert.Len(t, revisions, 2) {
+		assert.Equal(t, editor.ID, revisions[0].UserID)
+		assert.Equal(t, author.ID, revisions[1].UserID)
+	}
+
+	// 文章的作者不变
+	saved, err := article.Get(_article.GetStringID())
+	require.NoError(t, err)
+	assert.Equal(t, author.ID, saved.UserID)
+}
+
+func TestMigrateBackfillsPublishedAt(t *testing.T) {
+	db := setupDB(t)
+	author := createUser(t, "alice")
+	old := createArticle(t, author.ID, "old", article.StatusPublished, nil)
+	draft := createArticle(t, author.ID, "draft", article.StatusDraft, nil)
+
+	// 新增状态字段前发布的文章没有发布时间
+	createdAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	require.NoError(t, db.Model(&old).UpdateColumns(map[string]interface{}{
+		"created_at":   createdAt,
+		"published_at": nil,
+	}).Error)
+
+	// 迁移时使用创建时间补全，草稿不受影响
+	bootstrap.Migrate(db)
+	reloaded, err := article.Get(old.GetStringID())
+	require.NoError(t, err)
+	if assert.NotNil(t, reloaded.PublishedAt) {
+		assert.True(t, createdAt.Equal(*reloaded.PublishedAt))
+	}
+	reloaded, err = article.Get(draft.GetStringID())
+	require.NoError(t, err)
+	assert.Nil(t, reloaded.PublishedAt)
+}
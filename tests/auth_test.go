@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"goblog/app/http/middlewares"
+	"goblog/app/models/user"
+	"goblog/pkg/auth"
+	"goblog/pkg/session"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// loginCookies 登录用户，返回保存会话的 Cookie，需先设置 session.Store
+func loginCookies(t *testing.T, _user user.User) []*http.Cookie {
+	rec := httptest.NewRecorder()
+	r := session.Start(rec, httptest.NewRequest("POST", "/auth/login", nil))
+	require.NoError(t, auth.Login(r, _user))
+	cookies := rec.Result().Cookies()
+	return cookies[len(cookies)-1:]
+}
+
+func TestAuthUserResolvedOncePerRequest(t *testing.T) {
+	db := setupDB(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+
+	// 1. 登录，记录会话 Cookie
+	rec := httptest.NewRecorder()
+	r := session.Start(rec, httptest.NewRequest("POST", "/auth/login", nil))
+	require.NoError(t, auth.Login(r, alice))
+	cookies := rec.Result().Cookies()
+	cookies = cookies[len(cookies)-1:]
+
+	// 统计查询次数
+	queries := 0
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count", func(*gorm.DB) {
+		queries++
+	}))
+	t.Cleanup(func() { db.Callback().Query().Remove("test:count") })
+
+	serve := func(handle func(r *http.Request)) {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		middlewares.StartSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handle(r)
+		})).ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	// 2. 同一请求中多次获取登录用户和设备，只查询一次用户和一次设备
+	serve(func(r *http.Request) {
+		queries = 0
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, alice.ID, auth.User(r).ID)
+			assert.True(t, auth.Check(r))
+			device, ok := auth.CurrentDevice(r)
+			assert.True(t, ok)
+			assert.Equal(t, alice.ID, device.UserID)
+		}
+		assert.Equal(t, 2, queries)
+	})
+
+	// 3. 请求中更换登录用户后，重新解析
+	serve(func(r *http.Request) {
+		assert.Equal(t, alice.ID, auth.User(r).ID)
+		require.NoError(t, auth.Login(r, bob))
+		assert.Equal(t, bob.ID, auth.User(r).ID)
+		device, _ := auth.CurrentDevice(r)
+		assert.Equal(t, bob.ID, device.UserID)
+
+		auth.Logout(httptest.NewRecorder(), r)
+		assert.False(t, auth.Check(r))
+	})
+}
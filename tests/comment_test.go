@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/comment"
+	"goblog/app/models/spamtoken"
+	"goblog/pkg/antispam"
+	"goblog/pkg/route"
+	"goblog/pkg/types"
+	"goblog/routes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createComment 以游客身份发表一条指定状态的评论
+func createComment(t *testing.T, articleID uint64, parentID *uint64, status string) comment.Comment {
+	_comment := comment.Comment{
+		ArticleID:  articleID,
+		ParentID:   parentID,
+		GuestName:  "guest",
+		GuestEmail: "guest@example.com",
+		Body:       "**评论**",
+		Status:     status,
+	}
+	require.NoError(t, _comment.Create())
+	return _comment
+}
+
+// commentCount 从数据库读取文章的评论数量
+func commentCount(t *testing.T, articleID uint64) uint64 {
+	_article, err := article.Get(types.Uint64ToString(articleID))
+	require.NoError(t, err)
+	return _article.CommentCount
+}
+
+// commentIDs 评论的 ID 列表
+func commentIDs(comments []comment.Comment) []uint64 {
+	ids := make([]uint64, len(comments))
+	for i, c := range comments {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func TestCommentCount(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+
+	// 1. 只统计已通过的评论
+	approved := createComment(t, _article.ID, nil, comment.StatusApproved)
+	pending := createComment(t, _article.ID, nil, comment.StatusPending)
+	assert.Equal(t, "<p><strong>评论</strong></p>\n", approved.BodyHTML)
+	assert.Equal(t, uint64(1), commentCount(t, _article.ID))
+
+	// 2. 审核通过后计入
+	require.NoError(t, pending.SetStatus(comment.StatusApproved))
+	assert.Equal(t, uint64(2), commentCount(t, _article.ID))
+
+	// 3. 删除评论时一并删除回复
+	reply := createComment(t, _article.ID, &approved.ID, comment.StatusApproved)
+	createComment(t, _article.ID, &reply.ID, comment.StatusApproved)
+	assert.Equal(t, uint64(4), commentCount(t, _article.ID))
+	rows, err := approved.Delete()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rows)
+	assert.Equal(t, uint64(1), commentCount(t, _article.ID))
+}
+
+func TestCommentTree(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+
+	root := createComment(t, _article.ID, nil, comment.StatusApproved)
+	reply := createComment(t, _article.ID, &root.ID, comment.StatusApproved)
+	nested := createComment(t, _article.ID, &reply.ID, comment.StatusApproved)
+	hidden := createComment(t, _article.ID, nil, comment.StatusPending)
+	orphan := createComment(t, _article.ID, &hidden.ID, comment.StatusApproved)
+
+	// 1. 只包含已通过的评论，回复挂在父评论下
+	tree, err := comment.GetApprovedTree(_article.ID)
+	require.NoError(t, err)
+	require.Len(t, tree, 2)
+	assert.Equal(t, root.ID, tree[0].ID)
+	require.Len(t, tree[0].Children, 1)
+	assert.Equal(t, reply.ID, tree[0].Children[0].ID)
+	require.Len(t, tree[0].Children[0].Children, 1)
+	assert.Equal(t, nested.ID, tree[0].Children[0].Children[0].ID)
+
+	// 2. 父评论未通过审核时，回复作为顶层评论显示
+	assert.Equal(t, orphan.ID, tree[1].ID)
+	assert.Empty(t, tree[1].Children)
+}
+
+func TestCommentModeration(t *testing.T) {
+	setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	aliceArticle := createArticle(t, alice.ID, "alice", article.StatusPublished, nil)
+	bobArticle := createArticle(t, bob.ID, "bob", article.StatusPublished, nil)
+
+	first := createComment(t, aliceArticle.ID, nil, comment.StatusPending)
+	second := createComment(t, aliceArticle.ID, nil, comment.StatusPending)
+	createComment(t, aliceArticle.ID, nil, comment.StatusApproved)
+	createComment(t, bobArticle.ID, nil, comment.StatusPending)
+
+	// 1. 审核队列只包含自己文章下指定状态的评论，新评论在前
+	r := httptest.NewRequest("GET", "/comments?status=pending", nil)
+	pending, _, err := comment.GetForModeration(alice.ID, false, comment.StatusPending, r, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{second.ID, first.ID}, commentIDs(pending))
+
+	// 2. 审核后离开待审核队列，进入对应状态的列表
+	require.NoError(t, first.SetStatus(comment.StatusSpam))
+	pending, _, err = comment.GetForModeration(alice.ID, false, comment.StatusPending, r, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{second.ID}, commentIDs(pending))
+	spam, _, err := comment.GetForModeration(alice.ID, false, comment.StatusSpam, r, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{first.ID}, commentIDs(spam))
+	assert.Equal(t, uint64(1), commentCount(t, aliceArticle.ID))
+}
+
+func TestArticleUpdateKeepsCommentCount(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+
+	// 编辑文章期间有新的评论，文章中的评论数量已过期
+	stale, err := article.Get(types.Uint64ToString(_article.ID))
+	require.NoError(t, err)
+	createComment(t, _article.ID, nil, comment.StatusApproved)
+
+	stale.Body = "*updated*"
+	_, err = stale.Update(author.ID)
+	require.NoError(t, err)
+
+	updated, err := article.Get(types.Uint64ToString(_article.ID))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), updated.CommentCount)
+	assert.Equal(t, "*updated*", updated.Body)
+	assert.Equal(t, "<p><em>updated</em></p>\n", updated.BodyHTML)
+}
+
+func TestCommentErrorsAreReturned(t *testing.T) {
+	db := setupDB(t)
+	author := createUser(t, "alice")
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+	_comment := createComment(t, _article.ID, nil, comment.StatusPending)
+
+	// 数据库不可用时返回错误，而不是退出进程
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+
+	assert.Error(t, _comment.SetStatus(comment.StatusApproved))
+	_, err = _comment.Delete()
+	assert.Error(t, err)
+	_new := comment.Comment{ArticleID: _article.ID, GuestName: "guest", Body: "hi"}
+	assert.Error(t, _new.Create())
+}
+
+func TestCommentTrainOnlyWhenLabelChanges(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+	_comment := createComment(t, _article.ID, nil, comment.StatusPending)
+	filter := antispam.New(spamtoken.Store{})
+	store := spamtoken.Store{}
+
+	// 1. 重复标记为垃圾评论只训练一次
+	require.NoError(t, _comment.Train(filter, antispam.Spam))
+	require.NoError(t, _comment.Train(filter, antispam.Spam))
+	docs, features, err := store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, antispam.Counts{Spam: 1}, docs)
+	assert.Equal(t, antispam.Counts{Spam: 1}, features["评论"])
+
+	// 2. 改为正常评论时撤销之前的训练，分类记录在评论中
+	reloaded, err := comment.Get(types.Uint64ToString(_comment.ID))
+	require.NoError(t, err)
+	assert.Equal(t, antispam.Spam, reloaded.TrainedAs)
+	require.NoError(t, reloaded.Train(filter, antispam.Ham))
+	docs, features, err = store.Load()
+	require.NoError(t, err)
+	assert.Equal(t, antispam.Counts{Ham: 1}, docs)
+	assert.Equal(t, antispam.Counts{Ham: 1}, features["评论"])
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"goblog/app/http/middlewares"
+	"goblog/pkg/csrf"
+	"goblog/pkg/route"
+	"goblog/pkg/session"
+	"goblog/routes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSRFVerify(t *testing.T) {
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+
+	// 1. 打开表单时生成令牌，写入会话
+	rec := httptest.NewRecorder()
+	r := session.Start(rec, httptest.NewRequest("GET", "/articles/create", nil))
+	token := csrf.Token(r)
+	assert.Contains(t, string(csrf.Field(r)), token)
+	cookies := rec.Result().Cookies()
+
+	post := func(form url.Values, header string) bool {
+		req := httptest.NewRequest("POST", "/articles", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if len(header) > 0 {
+			req.Header.Set(csrf.HeaderName, header)
+		}
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		return csrf.Verify(session.Start(httptest.NewRecorder(), req))
+	}
+
+	// 2. 表单字段或请求头中的令牌一致才通过
+	assert.True(t, post(url.Values{csrf.FieldName: {token}}, ""))
+	assert.True(t, post(url.Values{}, token))
+	assert.False(t, post(url.Values{csrf.FieldName: {"forged"}}, ""))
+	assert.False(t, post(url.Values{}, ""))
+
+	// 3. 没有会话（如跨站请求未带 Cookie）时始终不通过
+	cookies = nil
+	assert.False(t, post(url.Values{csrf.FieldName: {token}}, ""))
+
+	// 4. 更换令牌后旧令牌失效
+	rec = httptest.NewRecorder()
+	r = session.Start(rec, httptest.NewRequest("GET", "/", nil))
+	old := csrf.Token(r)
+	csrf.Rotate(r)
+	assert.NotEqual(t, old, csrf.Token(r))
+}
+
+// chdirRoot 切换到项目根目录，使视图能找到 resources/views 下的模板
+func chdirRoot(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(".."))
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestCSRFTokenOnlyForForms(t *testing.T) {
+	setupDB(t)
+	chdirRoot(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+
+	// 1. 没有表单的页面不生成令牌，未登录的访问不创建会话
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Result().Cookies())
+
+	// 2. 包含表单的页面生成令牌，并在输出内容之前写入会话 Cookie
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/auth/login", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `name="`+csrf.FieldName+`"`)
+	assert.NotEmpty(t, rec.Result().Cookies())
+}
+
+func TestVerifyCSRFToken(t *testing.T) {
+	setupDB(t)
+	chdirRoot(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	route.SetRoute(mux.NewRouter())
+
+	router := mux.NewRouter()
+	router.PathPrefix("/").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	router.Use(middlewares.StartSession, middlewares.VerifyCSRFToken)
+
+	post := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("POST", path, nil))
+		return rec
+	}
+
+	// 1. 豁免的路由无需令牌，/graphql 需完全一致
+	assert.Equal(t, http.StatusOK, post("/api/v1/articles").Code)
+	assert.Equal(t, http.StatusOK, post("/graphql").Code)
+
+	// 2. 令牌无效时显示 419 页面
+	for _, path := range []string{"/graphql-admin", "/graphql/x", "/articles"} {
+		rec := post(path)
+		assert.Equal(t, 419, rec.Code, path)
+		assert.Contains(t, rec.Body.String(), "419 页面已过期", path)
+	}
+}
@@ -0,0 +1,47 @@
+package tests
+
+import (
+	"goblog/app/models/role"
+	"goblog/bootstrap"
+	_ "goblog/config"
+	"goblog/pkg/model"
+	"goblog/pkg/search"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// setupDB 为测试创建独立的 SQLite 数据库并执行迁移，测试结束后删除，同时清空搜索索引
+// 模型通过全局的 model.DB 访问数据库，使用数据库的测试不能并行执行
+func setupDB(t *testing.T) *gorm.DB {
+	dir, err := ioutil.TempDir("", "goblog-test")
+	require.NoError(t, err)
+
+	// 模型钩子会在事务中通过 model.DB 查询，WAL 模式下读操作不会被事务阻塞
+	dsn := filepath.Join(dir, "test.db") + "?_journal_mode=WAL&_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: gormlogger.Default.LogMode(gormlogger.Silent),
+	})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB.Close()
+		os.RemoveAll(dir)
+	})
+
+	model.DB = db
+	bootstrap.Migrate(db)
+
+	// 搜索索引和角色权限缓存与数据库对应，同样为每个测试重新创建
+	search.Default = search.NewIndex()
+	role.Flush()
+	return db
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"errors"
+	"goblog/pkg/dataloader"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDataloaderBatchesQueuedIDs(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]uint64
+	loader := dataloader.New(func(ids []uint64) (map[uint64]interface{}, error) {
+		mu.Lock()
+		batches = append(batches, ids)
+		mu.Unlock()
+		found := map[uint64]interface{}{}
+		for _, id := range ids {
+			if id != 404 {
+				found[id] = id * 10
+			}
+		}
+		return found, nil
+	})
+
+	// 列表中登记的 ID，并发加载时只查询一次
+	loader.Queue(1, 2, 3, 2, 404)
+	var wg sync.WaitGroup
+	for _, id := range []uint64{1, 2, 3, 404} {
+		wg.Add(1)
+		go func(id uint64) {
+			defer wg.Done()
+			v, err := loader.Load(id)
+			assert.NoError(t, err)
+			if id == 404 {
+				assert.Nil(t, v)
+			} else {
+				assert.Equal(t, id*10, v)
+			}
+		}(id)
+	}
+	wg.Wait()
+	assert.Len(t, batches, 1)
+	assert.ElementsMatch(t, []uint64{1, 2, 3, 404}, batches[0])
+
+	// 已缓存的 ID 不再查询，未登记的 ID 单独查询
+	loader.Queue(1)
+	v, _ := loader.Load(5)
+	assert.Equal(t, uint64(50), v)
+	assert.Len(t, batches, 2)
+	assert.Equal(t, []uint64{5}, batches[1])
+}
+
+func TestDataloaderRetriesAfterError(t *testing.T) {
+	calls := 0
+	loader := dataloader.New(func(ids []uint64) (map[uint64]interface{}, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("db down")
+		}
+		return map[uint64]interface{}{1: "a"}, nil
+	})
+
+	_, err := loader.Load(1)
+	assert.Error(t, err)
+	v, err := loader.Load(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", v)
+}
@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"goblog/app/http/middlewares"
+	"goblog/app/models/usersession"
+	"goblog/pkg/auth"
+	"goblog/pkg/model"
+	"goblog/pkg/session"
+	"goblog/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// countRows 数据表中属于用户的记录数量
+func countRows(t *testing.T, db *gorm.DB, table string, userID uint64) int64 {
+	var count int64
+	require.NoError(t, db.Table(table).Where("user_id = ?", userID).Count(&count).Error)
+	return count
+}
+
+// createDevice 为用户创建登录设备
+func createDevice(t *testing.T, userID uint64, ip string) (usersession.Device, string) {
+	deviceToken, err := usersession.CreateDevice(userID, ip, "Mozilla/5.0 (Windows NT 10.0) Chrome/90.0")
+	require.NoError(t, err)
+	device, err := usersession.GetDevice(deviceToken)
+	require.NoError(t, err)
+	return device, deviceToken
+}
+
+func TestDeviceCreateAndTouch(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+
+	// 1. 数据库只保存令牌的哈希值，过长的 User-Agent 会被截断
+	device, deviceToken := createDevice(t, alice.ID, "10.0.0.1")
+	assert.Equal(t, token.Hash(deviceToken), device.TokenHash)
+	assert.NotEqual(t, deviceToken, device.TokenHash)
+	assert.Equal(t, "Chrome · Windows", device.Name())
+	_, err := usersession.GetDevice("missing")
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+
+	longToken, err := usersession.CreateDevice(alice.ID, "10.0.0.1", strings.Repeat("a", 300))
+	require.NoError(t, err)
+	long, err := usersession.GetDevice(longToken)
+	require.NoError(t, err)
+	assert.Len(t, long.UserAgent, 255)
+
+	// 2. 一分钟内同一 IP 的访问不更新
+	seenAt := time.Now().Add(-30 * time.Second)
+	require.NoError(t, model.DB.Model(&device).UpdateColumn("last_seen_at", seenAt).Error)
+	device.LastSeenAt = seenAt
+	require.NoError(t, device.Touch("10.0.0.1"))
+	reloaded, err := usersession.GetDevice(deviceToken)
+	require.NoError(t, err)
+	assert.WithinDuration(t, seenAt, reloaded.LastSeenAt, time.Second)
+
+	// 3. IP 变化时立即更新
+	require.NoError(t, reloaded.Touch("10.0.0.2"))
+	reloaded, err = usersession.GetDevice(deviceToken)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", reloaded.IP)
+	assert.WithinDuration(t, time.Now(), reloaded.LastSeenAt, 5*time.Second)
+}
+
+func TestDeviceRevoke(t *testing.T) {
+	db := setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	first, _ := createDevice(t, alice.ID, "10.0.0.1")
+	second, _ := createDevice(t, alice.ID, "10.0.0.2")
+	third, _ := createDevice(t, alice.ID, "10.0.0.3")
+	bobDevice, _ := createDevice(t, bob.ID, "10.0.0.4")
+	for _, device := range []usersession.Device{first, second, third, bobDevice} {
+		_, err := usersession.CreateRememberToken(device.UserID, device.ID, time.Hour)
+		require.NoError(t, err)
+	}
+
+	// 1. 不能退出其他用户的设备
+	rows, err := usersession.RevokeDevice(alice.ID, bobDevice.ID)
+	require.NoError(t, err)
+	assert.Zero(t, rows)
+
+	// 2. 退出设备时一并删除该设备的「记住我」令牌
+	rows, err = usersession.RevokeDevice(alice.ID, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	devices, err := usersession.GetDevicesByUser(alice.ID)
+	require.NoError(t, err)
+	assert.Len(t, devices, 2)
+	assert.Equal(t, int64(2), countRows(t, db, "remember_tokens", alice.ID))
+
+	// 3. 退出其他设备时保留当前设备
+	rows, err = usersession.RevokeOtherDevices(alice.ID, third.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	devices, err = usersession.GetDevicesByUser(alice.ID)
+	require.NoError(t, err)
+	if assert.Len(t, devices, 1) {
+		assert.Equal(t, third.ID, devices[0].ID)
+	}
+
+	// 4. 退出所有设备，包括未关联设备的「记住我」令牌
+	_, err = usersession.CreateRememberToken(alice.ID, 0, time.Hour)
+	require.NoError(t, err)
+	rows, err = usersession.RevokeAllDevices(alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	assert.Zero(t, countRows(t, db, "user_sessions", alice.ID))
+	assert.Zero(t, countRows(t, db, "remember_tokens", alice.ID))
+
+	// 其他用户不受影响
+	assert.Equal(t, int64(1), countRows(t, db, "user_sessions", bob.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "remember_tokens", bob.ID))
+}
+
+func TestRevokedDeviceIsLoggedOut(t *testing.T) {
+	setupDB(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	alice := createUser(t, "alice")
+
+	// 1. 登录后记录会话 Cookie
+	rec := httptest.NewRecorder()
+	r := session.Start(rec, httptest.NewRequest("POST", "/auth/login", nil))
+	require.NoError(t, auth.Login(r, alice))
+	device, ok := auth.CurrentDevice(r)
+	require.True(t, ok)
+	cookies := rec.Result().Cookies()
+	cookies = cookies[len(cookies)-1:]
+
+	check := func() bool {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		logined := false
+		middlewares.StartSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			logined = auth.Check(r)
+		})).ServeHTTP(httptest.NewRecorder(), req)
+		return logined
+	}
+
+	// 2. 在其他设备上退出该设备后，会话不再有效
+	assert.True(t, check())
+	_, err := usersession.RevokeDevice(alice.ID, device.ID)
+	require.NoError(t, err)
+	assert.False(t, check())
+}
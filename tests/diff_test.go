@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"goblog/pkg/diff"
+	"math/rand"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffSplitWords(t *testing.T) {
+	assert.Equal(t,
+		[]string{"Go", " ", "语", "言", "，", "hello_world", " ", "2021"},
+		diff.SplitWords("Go 语言，hello_world 2021"))
+}
+
+func TestDiffWords(t *testing.T) {
+	chunks := diff.Words("我爱北京 hello world", "我爱上海 hello there world")
+
+	assert.Equal(t, []diff.Chunk{
+		{Op: diff.Equal, Text: "我爱"},
+		{Op: diff.Delete, Text: "北京"},
+		{Op: diff.Insert, Text: "上海"},
+		{Op: diff.Equal, Text: " hello"},
+		{Op: diff.Insert, Text: " there"},
+		{Op: diff.Equal, Text: " world"},
+	}, chunks)
+}
+
+func TestDiffLines(t *testing.T) {
+	chunks := diff.Lines("a\nb\nc", "a\nc\nd")
+
+	assert.Equal(t, []diff.Chunk{
+		{Op: diff.Equal, Text: "a"},
+		{Op: diff.Delete, Text: "b"},
+		{Op: diff.Equal, Text: "c"},
+		{Op: diff.Insert, Text: "d"},
+	}, chunks)
+}
+
+func TestDiffTokensMinimal(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomTokens := func() []string {
+		tokens := make([]string, rnd.Intn(30))
+		for i := range tokens {
+			tokens[i] = string(rune('a' + rnd.Intn(4)))
+		}
+		return tokens
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomTokens(), randomTokens()
+		chunks := diff.Tokens(a, b)
+
+		// 片段能还原两个版本，且编辑次数等于最短编辑距离
+		var gotA, gotB []string
+		edits := 0
+		for _, c := range chunks {
+			if !c.IsInsert() {
+				gotA = append(gotA, c.Text)
+			}
+			if !c.IsDelete() {
+				gotB = append(gotB, c.Text)
+			}
+			if !c.IsEqual() {
+				edits++
+			}
+		}
+		assert.Equal(t, strings.Join(a, ""), strings.Join(gotA, ""))
+		assert.Equal(t, strings.Join(b, ""), strings.Join(gotB, ""))
+		assert.Equal(t, len(a)+len(b)-2*lcs(a, b), edits, "%v %v", a, b)
+	}
+}
+
+func TestDiffTokensLargeInput(t *testing.T) {
+	a := make([]string, 200000)
+	b := make([]string, 200000)
+	for i := range a {
+		a[i] = strconv.Itoa(i)
+		b[i] = strconv.Itoa(i + len(a))
+	}
+	b[100000] = a[100000]
+
+	// 差异过大时整体替换，不会占用大量内存和时间
+	chunks := diff.Tokens(a, b)
+	assert.Len(t, chunks, len(a)+len(b))
+}
+
+// lcs 最长公共子序列的长度
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				dp[i][j] = dp[i-1][j-1] + 1
+			case dp[i-1][j] > dp[i][j-1]:
+				dp[i][j] = dp[i-1][j]
+			default:
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"goblog/app/models/article"
+	_ "goblog/config"
+	"goblog/pkg/feed"
+	"goblog/pkg/route"
+	"goblog/routes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFeed() feed.Feed {
+	published := time.Date(2021, 5, 1, 8, 0, 0, 0, time.UTC)
+	return feed.Feed{
+		Title:    "GoBlog",
+		Link:     "http://localhost:3000/",
+		FeedURL:  "http://localhost:3000/feed.xml",
+		Language: "zh-CN",
+		Updated:  published.Add(time.Hour),
+		Items: []feed.Item{{
+			ID:         "http://localhost:3000/articles/hello",
+			Title:      "你好 & <世界>",
+			Link:       "http://localhost:3000/articles/hello",
+			Summary:    "摘要",
+			Content:    "<p>全文</p>",
+			AuthorName: "alice",
+			Categories: []string{"go"},
+			Published:  published,
+			Updated:    published.Add(time.Hour),
+		}},
+	}
+}
+
+func TestFeedFormats(t *testing.T) {
+	f := testFeed()
+
+	// RSS 2.0
+	body, err := f.RSS()
+	assert.NoError(t, err)
+	var rss struct {
+		Items []struct {
+			Title   string `xml:"title"`
+			PubDate string `xml:"pubDate"`
+			Content string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Creator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+		} `xml:"channel>item"`
+	}
+	assert.NoError(t, xml.Unmarshal(body, &rss))
+	if assert.Len(t, rss.Items, 1) {
+		assert.Equal(t, "你好 & <世界>", rss.Items[0].Title)
+		assert.Equal(t, "Sat, 01 May 2021 08:00:00 +0000", rss.Items[0].PubDate)
+		assert.Equal(t, "<p>全文</p>", rss.Items[0].Content)
+		assert.Equal(t, "alice", rss.Items[0].Creator)
+	}
+
+	// Atom，updated 来自文章的更新时间
+	body, err = f.Atom()
+	assert.NoError(t, err)
+	var atom struct {
+		XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+		Updated string   `xml:"updated"`
+		Entries []struct {
+			Updated string `xml:"updated"`
+			Content string `xml:"content"`
+		} `xml:"entry"`
+	}
+	assert.NoError(t, xml.Unmarshal(body, &atom))
+	assert.Equal(t, "2021-05-01T09:00:00Z", atom.Updated)
+	if assert.Len(t, atom.Entries, 1) {
+		assert.Equal(t, "2021-05-01T09:00:00Z", atom.Entries[0].Updated)
+		assert.Equal(t, "<p>全文</p>", atom.Entries[0].Content)
+	}
+
+	// JSON Feed，只输出摘要时使用 content_text
+	f.Items[0].Content = ""
+	body, err = f.JSON()
+	assert.NoError(t, err)
+	var jf map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &jf))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", jf["version"])
+	item := jf["items"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "摘要", item["content_text"])
+	assert.Nil(t, item["content_html"])
+}
+
+func TestFeedConditionalGet(t *testing.T) {
+	f := testFeed()
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, f.Serve(rec, httptest.NewRequest("GET", "/atom.xml", nil), feed.FormatAtom))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, strings.HasPrefix(rec.Header().Get("Content-Type"), "application/atom+xml"))
+	assert.Equal(t, "Sat, 01 May 2021 09:00:00 GMT", rec.Header().Get("Last-Modified"))
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	// ETag 未变化
+	req := httptest.NewRequest("GET", "/atom.xml", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	f.Serve(rec, req, feed.FormatAtom)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	// 没有更新的文章
+	req = httptest.NewRequest("GET", "/atom.xml", nil)
+	req.Header.Set("If-Modified-Since", "Sat, 01 May 2021 09:00:00 GMT")
+	rec = httptest.NewRecorder()
+	f.Serve(rec, req, feed.FormatAtom)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// 文章更新后 ETag 改变
+	f.Items[0].Title = "新标题"
+	req = httptest.NewRequest("GET", "/atom.xml", nil)
+	req.Header.Set("If-None-Match", etag)
+	rec = httptest.NewRecorder()
+	f.Serve(rec, req, feed.FormatAtom)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestFeedRoutes(t *testing.T) {
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+
+	for path, name := range map[string]string{
+		"/feed.xml":                "feeds.index",
+		"/atom.xml":                "feeds.index",
+		"/feed.json":               "feeds.index",
+		"/categories/3/feed":       "feeds.category",
+		"/categories/3/atom.xml":   "feeds.category",
+		"/tags/go-lang/feed.xml":   "feeds.tag",
+		"/users/7/feed.json":       "feeds.user",
+		"/categories/3/feed.xhtml": "",
+		"/feedxxml":                "",
+	} {
+		var match mux.RouteMatch
+		matched := router.Match(httptest.NewRequest("GET", path, nil), &match) && match.Route != nil
+		if name == "" {
+			assert.False(t, matched && strings.HasPrefix(match.Route.GetName(), "feeds."), path)
+			continue
+		}
+		if assert.True(t, matched, path) {
+			assert.Equal(t, name, match.Route.GetName(), path)
+		}
+	}
+
+	assert.Equal(t, "http://localhost:3000/categories/3/atom.xml", route.Name2URL("feeds.category", "id", "3", "format", "atom.xml"))
+}
+
+func TestFeedDatabaseError(t *testing.T) {
+	db := setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+
+	// 数据库不可用时返回 500，而不是退出进程
+	sqlDB, err := db.DB()
+	assert.NoError(t, err)
+	assert.NoError(t, sqlDB.Close())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/feed.xml", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestFeedItemIDSurvivesRetitle(t *testing.T) {
+	setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	author := createUser(t, "alice")
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+
+	item := func() map[string]interface{} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/feed.json", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		var doc struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &doc))
+		require.Len(t, doc.Items, 1)
+		return doc.Items[0]
+	}
+
+	// 1. 唯一标识使用数字 ID 的链接
+	before := item()
+	assert.Equal(t, route.Name2URL("articles.show", "id", _article.GetStringID()), before["id"])
+
+	// 2. 修改标题后链接变化，唯一标识不变
+	_article.Title = "hello again"
+	_, err := _article.Update(author.ID)
+	require.NoError(t, err)
+	after := item()
+	assert.NotEqual(t, before["url"], after["url"])
+	assert.Equal(t, before["id"], after["id"])
+}
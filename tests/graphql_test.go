@@ -0,0 +1,194 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"goblog/app/graphql"
+	"goblog/app/models/accesstoken"
+	"goblog/app/models/article"
+	"goblog/app/models/category"
+	"goblog/app/models/user"
+	"goblog/pkg/types"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func execGraphQL(query string, viewer user.User, token accesstoken.AccessToken) (map[string]interface{}, []string) {
+	res := graphql.Execute(context.Background(), viewer, token, graphql.Params{Query: query})
+
+	var data map[string]interface{}
+	json.Unmarshal(res.Data, &data)
+	var messages []string
+	for _, err := range res.Errors {
+		messages = append(messages, err.Message)
+	}
+	return data, messages
+}
+
+func TestGraphQLSchema(t *testing.T) {
+	data, errs := execGraphQL(`{ __type(name: "Article") { fields { name } } }`, user.User{}, accesstoken.AccessToken{})
+	assert.Empty(t, errs)
+
+	var names []string
+	for _, f := range data["__type"].(map[string]interface{})["fields"].([]interface{}) {
+		names = append(names, f.(map[string]interface{})["name"].(string))
+	}
+	assert.Subset(t, names, []string{"id", "title", "bodyHtml", "author", "category", "tags", "commentCount"})
+
+	// 未携带令牌时 viewer 为 null
+	data, errs = execGraphQL(`{ viewer { id } }`, user.User{}, accesstoken.AccessToken{})
+	assert.Empty(t, errs)
+	assert.Nil(t, data["viewer"])
+
+	// 查询语法和字段在执行前校验
+	_, errs = execGraphQL(`{ articles { edges { node { password } } } }`, user.User{}, accesstoken.AccessToken{})
+	assert.NotEmpty(t, errs)
+}
+
+func TestGraphQLArguments(t *testing.T) {
+	// 无效的游标和 ID 在查询数据库之前返回错误
+	_, errs := execGraphQL(`{ articles(after: "bogus") { totalCount } }`, user.User{}, accesstoken.AccessToken{})
+	assert.Equal(t, []string{"无效的游标"}, errs)
+
+	_, errs = execGraphQL(`{ user(id: "abc") { name } }`, user.User{}, accesstoken.AccessToken{})
+	assert.Equal(t, []string{"无效的 ID"}, errs)
+}
+
+func TestGraphQLMutationRequiresWriteToken(t *testing.T) {
+	mutation := `mutation { createArticle(input: {title: "标题"}) { article { id } } }`
+	viewer := user.User{Name: "alice"}
+	viewer.ID = 1
+
+	_, errs := execGraphQL(mutation, user.User{}, accesstoken.AccessToken{})
+	assert.Equal(t, []string{"需要拥有 write 权限范围的访问令牌"}, errs)
+
+	_, errs = execGraphQL(mutation, viewer, accesstoken.AccessToken{Scopes: "read"})
+	assert.Equal(t, []string{"需要拥有 write 权限范围的访问令牌"}, errs)
+}
+
+// countQueries 统计之后执行的查询次数
+func countQueries(t *testing.T, db *gorm.DB) *int64 {
+	var queries int64
+	count := func(tx *gorm.DB) {
+		// 子查询在生成 SQL 时以 DryRun 模式执行回调，不会查询数据库
+		if !tx.DryRun {
+			atomic.AddInt64(&queries, 1)
+		}
+	}
+	require.NoError(t, db.Callback().Query().After("gorm:query").Register("test:count_queries", count))
+	require.NoError(t, db.Callback().Row().After("gorm:row").Register("test:count_queries", count))
+	t.Cleanup(func() {
+		db.Callback().Query().Remove("test:count_queries")
+		db.Callback().Row().Remove("test:count_queries")
+	})
+	return &queries
+}
+
+func TestGraphQLNestedArticlesAreBatched(t *testing.T) {
+	db := setupDB(t)
+	query := `{ users { edges { node { name articles(first: 2) {
+		totalCount pageInfo { hasNextPage } edges { node { title tags } }
+	} } } } }`
+
+	// userArticles 查询结果中每个用户的文章标题和总数
+	userArticles := func() (map[string][]string, map[string]float64) {
+		data, errs := execGraphQL(query, user.User{}, accesstoken.AccessToken{})
+		require.Empty(t, errs)
+		titles, totals := map[string][]string{}, map[string]float64{}
+		for _, edge := range data["users"].(map[string]interface{})["edges"].([]interface{}) {
+			node := edge.(map[string]interface{})["node"].(map[string]interface{})
+			name := node["name"].(string)
+			articles := node["articles"].(map[string]interface{})
+			totals[name] = articles["totalCount"].(float64)
+			titles[name] = []string{}
+			for _, a := range articles["edges"].([]interface{}) {
+				titles[name] = append(titles[name], a.(map[string]interface{})["node"].(map[string]interface{})["title"].(string))
+			}
+		}
+		return titles, totals
+	}
+
+	// 1. 每个用户只返回自己最新的两篇已发布文章，总数分别统计
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	createUser(t, "carol")
+	for _, title := range []string{"a1", "a2", "a3"} {
+		createArticle(t, alice.ID, title, article.StatusPublished, nil)
+	}
+	createArticle(t, bob.ID, "b1", article.StatusPublished, nil)
+	createArticle(t, bob.ID, "b2", article.StatusDraft, nil)
+
+	queries := countQueries(t, db)
+	titles, totals := userArticles()
+	assert.Equal(t, map[string][]string{"alice": {"a3", "a2"}, "bob": {"b1"}, "carol": {}}, titles)
+	assert.Equal(t, map[string]float64{"alice": 3, "bob": 1, "carol": 0}, totals)
+	batched := atomic.LoadInt64(queries)
+
+	// 2. 查询次数不随用户数量增加
+	for _, name := range []string{"dave", "erin", "frank"} {
+		_user := createUser(t, name)
+		createArticle(t, _user.ID, name+" post", article.StatusPublished, nil)
+	}
+	atomic.StoreInt64(queries, 0)
+	titles, _ = userArticles()
+	assert.Len(t, titles, 6)
+	assert.Equal(t, []string{"dave post"}, titles["dave"])
+	assert.Equal(t, batched, atomic.LoadInt64(queries))
+}
+
+func TestGraphQLCategoryArticlesAreBatched(t *testing.T) {
+	db := setupDB(t)
+	require.NoError(t, db.Create(&category.Category{Name: "默认分类"}).Error)
+	author := createUser(t, "alice")
+	first := createArticle(t, author.ID, "first", article.StatusPublished, nil)
+	createArticle(t, author.ID, "second", article.StatusPublished, nil)
+
+	// 分页参数中的游标对列表中的每个分类生效
+	data, errs := execGraphQL(`{ categories { edges { node { articles(first: 1, after: "`+graphqlCursor(t, first.ID+1)+`") {
+		edges { node { title } } pageInfo { hasNextPage }
+	} } } } }`, user.User{}, accesstoken.AccessToken{})
+	require.Empty(t, errs)
+	edges := data["categories"].(map[string]interface{})["edges"].([]interface{})
+	require.NotEmpty(t, edges)
+	articles := edges[0].(map[string]interface{})["node"].(map[string]interface{})["articles"].(map[string]interface{})
+	assert.Equal(t, "first", articles["edges"].([]interface{})[0].(map[string]interface{})["node"].(map[string]interface{})["title"])
+	assert.Equal(t, false, articles["pageInfo"].(map[string]interface{})["hasNextPage"])
+}
+
+// graphqlCursor 读取 ID 对应的游标
+func graphqlCursor(t *testing.T, id uint64) string {
+	data, errs := execGraphQL(`{ articles(first: 100) { edges { cursor node { id } } } }`, user.User{}, accesstoken.AccessToken{})
+	require.Empty(t, errs)
+	for _, edge := range data["articles"].(map[string]interface{})["edges"].([]interface{}) {
+		e := edge.(map[string]interface{})
+		if e["node"].(map[string]interface{})["id"] == types.Uint64ToString(id) {
+			return e["cursor"].(string)
+		}
+	}
+	t.Fatalf("没有 ID 为 %d 的文章", id)
+	return ""
+}
+
+func TestGraphQLCostLimit(t *testing.T) {
+	setupDB(t)
+
+	// 1. 每页条数在限制之内
+	_, errs := execGraphQL(`{ articles(first: 100) { totalCount } users(first: 100) { totalCount } }`, user.User{}, accesstoken.AccessToken{})
+	assert.Empty(t, errs)
+
+	// 2. 使用别名重复读取列表，超过限制的列表返回错误
+	var query strings.Builder
+	query.WriteString("{")
+	for i := 0; i < 25; i++ {
+		fmt.Fprintf(&query, " a%d: articles(first: 100) { totalCount }", i)
+	}
+	query.WriteString(" }")
+	_, errs = execGraphQL(query.String(), user.User{}, accesstoken.AccessToken{})
+	assert.Contains(t, errs, "查询过于复杂，请减少列表的数量或每页条数")
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"goblog/app/models/identity"
+	"goblog/app/models/user"
+	"goblog/pkg/model"
+	"goblog/pkg/oidc"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityResolve(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	claims := oidc.Claims{Subject: "42", Email: alice.Email, EmailVerified: true}
+
+	// 1. 邮箱已注册且双方都已验证时自动关联，之后按关联记录登录
+	_user, err := identity.Resolve("github", claims)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, _user.ID)
+	_user, err = identity.Resolve("github", oidc.Claims{Subject: "42"})
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, _user.ID)
+
+	// 2. 提供方未验证邮箱时不能自动关联
+	_, err = identity.Resolve("company", oidc.Claims{Subject: "7", Email: alice.Email})
+	assert.Equal(t, identity.ErrEmailNotVerified, err)
+
+	// 3. 邮箱未注册时创建新用户
+	_user, err = identity.Resolve("github", oidc.Claims{Subject: "43", Email: "bob@example.com", EmailVerified: true, PreferredUsername: "bob"})
+	require.NoError(t, err)
+	assert.NotEqual(t, alice.ID, _user.ID)
+	assert.True(t, _user.IsVerified())
+}
+
+func TestIdentityResolveDeletedUser(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	_, err := identity.Link(alice.ID, "github", oidc.Claims{Subject: "42", Email: alice.Email})
+	require.NoError(t, err)
+
+	// 关联的用户已被删除（如删除用户时未清理的旧数据），按未关联处理并清理旧记录
+	require.NoError(t, model.DB.Delete(&user.User{}, alice.ID).Error)
+	_user, err := identity.Resolve("github", oidc.Claims{Subject: "42", Email: bob.Email, EmailVerified: true})
+	require.NoError(t, err)
+	assert.Equal(t, bob.ID, _user.ID)
+
+	identities, err := identity.GetByUser(alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, identities)
+	identities, err = identity.GetByUser(bob.ID)
+	require.NoError(t, err)
+	assert.Len(t, identities, 1)
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"goblog/pkg/mail"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	mailer := &mail.FileMailer{Dir: dir}
+
+	err := mailer.Send(mail.Message{
+		To:      []string{"summer@example.com"},
+		Subject: "重置密码",
+		Text:    "请打开以下链接设置新密码",
+	})
+	assert.NoError(t, err)
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.Len(t, files, 1)
+
+	content, _ := ioutil.ReadFile(files[0])
+	raw := string(content)
+	assert.Contains(t, raw, "To: summer@example.com\r\n")
+	assert.Contains(t, raw, "Subject: =?utf-8?q?")
+	assert.Contains(t, raw, "Content-Type: text/plain; charset=utf-8")
+	assert.True(t, strings.HasPrefix(raw, "From: \"GoBlog\" <noreply@example.com>\r\n"))
+}
+
+func TestMailMultipart(t *testing.T) {
+	raw := string(mail.Message{
+		To:      []string{"summer@example.com"},
+		Subject: "Welcome",
+		Text:    "plain",
+		HTML:    "<p>html</p>",
+	}.Bytes())
+
+	assert.Contains(t, raw, "Content-Type: multipart/alternative; boundary=")
+	assert.Contains(t, raw, "Content-Type: text/html; charset=utf-8")
+	assert.Contains(t, raw, "<p>html</p>")
+}
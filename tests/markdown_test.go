@@ -0,0 +1,24 @@
+package tests
+
+import (
+	"goblog/pkg/markdown"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdownRender(t *testing.T) {
+
+	// 1. GFM 语法
+	html := markdown.Render("# 标题\n\n- [x] 完成\n- [ ] 未完成\n\n| a | b |\n|---|---|\n| 1 | 2 |\n\n```go\nfmt.Println(1)\n```")
+	assert.Contains(t, html, "<h1 id=")
+	assert.Contains(t, html, `type="checkbox"`)
+	assert.Contains(t, html, "<table>")
+	assert.Contains(t, html, `<code class="language-go">`)
+
+	// 2. 危险内容会被过滤
+	html = markdown.Render("<script>alert(1)</script>\n\n[链接](javascript:alert(1))\n\n<img src=x onerror=alert(1)>")
+	assert.NotContains(t, html, "<script")
+	assert.NotContains(t, html, "javascript:")
+	assert.NotContains(t, html, "onerror")
+}
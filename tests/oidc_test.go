@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"goblog/pkg/oidc"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockProvider 进程内的身份提供方，支持服务发现、JWKS、授权码（PKCE）和用户信息端点
+type mockProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]url.Values
+	// claims 签发 ID Token 时覆盖的声明
+	claims map[string]interface{}
+	// signer 签名使用的私钥，为空时使用 key
+	signer *rsa.PrivateKey
+}
+
+func newMockProvider(t *testing.T) *mockProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	m := &mockProvider{key: key, codes: map[string]url.Values{}, claims: map[string]interface{}{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"userinfo_endpoint":      m.server.URL + "/userinfo",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, map[string]interface{}{"id": 42, "login": "octocat", "email": "octo@example.com"})
+	})
+	m.server = httptest.NewServer(mux)
+	return m
+}
+
+// authorize 模拟用户在提供方登录并同意授权，返回授权码
+func (m *mockProvider) authorize(t *testing.T, authURL string) string {
+	u, err := url.Parse(authURL)
+	assert.NoError(t, err)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.codes["code-1"] = u.Query()
+	return "code-1"
+}
+
+// token 令牌端点，校验客户端凭据、授权码和 PKCE
+func (m *mockProvider) token(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	params, ok := m.codes[r.PostFormValue("code")]
+	delete(m.codes, r.PostFormValue("code"))
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("client_secret") != "secret" ||
+		r.PostFormValue("redirect_uri") != params.Get("redirect_uri") ||
+		base64.RawURLEncoding.EncodeToString(sum[:]) != params.Get("code_challenge") {
+		w.WriteHeader(http.StatusBadRequest)
+		writeJSON(w, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	claims := map[string]interface{}{
+		"iss": m.server.URL, "aud": params.Get("client_id"), "sub": "u-1",
+		"exp": time.Now().Add(time.Hour).Unix(), "iat": time.Now().Unix(),
+		"nonce": params.Get("nonce"), "email": "alice@example.com", "email_verified": true,
+		"preferred_username": "alice",
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+	writeJSON(w, map[string]string{"access_token": "access-token", "token_type": "Bearer", "id_token": m.sign(claims)})
+}
+
+// sign 生成 RS256 签名的 ID Token
+func (m *mockProvider) sign(claims map[string]interface{}) string {
+	signer := m.signer
+	if signer == nil {
+		signer = m.key
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signing := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	hashed := sha256.Sum256([]byte(signing))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, signer, crypto.SHA256, hashed[:])
+	return signing + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func (m *mockProvider) provider() *oidc.Provider {
+	return &oidc.Provider{
+		Name:         "mock",
+		Issuer:       m.server.URL,
+		ClientID:     "goblog",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/auth/oidc/mock/callback",
+		Scopes:       []string{"openid", "email"},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	m := newMockProvider(t)
+	defer m.server.Close()
+	p := m.provider()
+
+	// 1. 服务发现
+	assert.NoError(t, p.Discover())
+	assert.Equal(t, m.server.URL+"/token", p.TokenURL)
+
+	// 2. 跳转地址携带 state、nonce 和 S256 code_challenge
+	state, nonce, verifier := oidc.RandomString(), oidc.RandomString(), oidc.RandomString()
+	authURL := p.AuthCodeURL(state, nonce, verifier)
+	u, _ := url.Parse(authURL)
+	assert.Equal(t, state, u.Query().Get("state"))
+	assert.Equal(t, nonce, u.Query().Get("nonce"))
+	assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+	assert.Equal(t, "openid email", u.Query().Get("scope"))
+
+	// 3. 换取令牌并验证 ID Token
+	claims, err := p.Authenticate(m.authorize(t, authURL), verifier, nonce)
+	assert.NoError(t, err)
+	assert.Equal(t, oidc.Claims{Subject: "u-1", Email: "alice@example.com", EmailVerified: true, PreferredUsername: "alice"}, claims)
+
+	// 4. 授权码只能使用一次
+	_, err = p.Authenticate("code-1", verifier, nonce)
+	assert.Error(t, err)
+}
+
+func TestOIDCRejectsInvalidResponses(t *testing.T) {
+	m := newMockProvider(t)
+	defer m.server.Close()
+	p := m.provider()
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	authenticate := func(verifierOverride string, nonceOverride string) error {
+		nonce, verifier := oidc.RandomString(), oidc.RandomString()
+		code := m.authorize(t, p.AuthCodeURL(oidc.RandomString(), nonce, verifier))
+		if len(verifierOverride) > 0 {
+			verifier = verifierOverride
+		}
+		if len(nonceOverride) > 0 {
+			nonce = nonceOverride
+		}
+		_, err := p.Authenticate(code, verifier, nonce)
+		return err
+	}
+
+	// PKCE verifier 不一致，令牌端点拒绝
+	assert.Error(t, authenticate("wrong-verifier", ""))
+
+	// nonce 不一致，可能是重放的 ID Token
+	assert.Equal(t, oidc.ErrInvalidIDToken, authenticate("", "wrong-nonce"))
+
+	// 受众、签发者、有效期和签名
+	for _, c := range []struct {
+		claims map[string]interface{}
+		signer *rsa.PrivateKey
+	}{
+		{claims: map[string]interface{}{"aud": "other-client"}},
+		{claims: map[string]interface{}{"iss": "https://evil.example.com"}},
+		{claims: map[string]interface{}{"exp": time.Now().Add(-time.Hour).Unix()}},
+		{signer: otherKey},
+	} {
+		m.claims, m.signer = c.claims, c.signer
+		assert.Equal(t, oidc.ErrInvalidIDToken, authenticate("", ""))
+	}
+
+	// aud 为数组时包含 client_id 即可
+	m.claims, m.signer = map[string]interface{}{"aud": []string{"other-client", "goblog"}}, nil
+	assert.NoError(t, authenticate("", ""))
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	m := newMockProvider(t)
+	defer m.server.Close()
+
+	p := m.provider()
+	p.Issuer = m.server.URL + "/"
+	assert.Error(t, p.Discover())
+}
+
+func TestOAuth2UserInfo(t *testing.T) {
+	m := newMockProvider(t)
+	defer m.server.Close()
+
+	// 只支持 OAuth2 的提供方，通过用户信息端点获取身份，不返回 email_verified 时视为未验证
+	p := &oidc.Provider{
+		Name:         "github",
+		ClientID:     "goblog",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/auth/oidc/github/callback",
+		AuthURL:      m.server.URL + "/authorize",
+		TokenURL:     m.server.URL + "/token",
+		UserInfoURL:  m.server.URL + "/userinfo",
+	}
+	verifier := oidc.RandomString()
+	authURL := p.AuthCodeURL(oidc.RandomString(), "", verifier)
+	u, _ := url.Parse(authURL)
+	assert.Empty(t, u.Query().Get("nonce"))
+
+	claims, err := p.Authenticate(m.authorize(t, authURL), verifier, "")
+	assert.NoError(t, err)
+	assert.Equal(t, oidc.Claims{Subject: "42", Email: "octo@example.com", PreferredUsername: "octocat"}, claims)
+
+	p.TrustEmail = true
+	claims, err = p.Authenticate(m.authorize(t, p.AuthCodeURL(oidc.RandomString(), "", verifier)), verifier, "")
+	assert.NoError(t, err)
+	assert.True(t, claims.EmailVerified)
+}
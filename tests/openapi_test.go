@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"goblog/app/http/controllers"
+	"goblog/app/models/article"
+	"goblog/app/requests"
+	"goblog/pkg/openapi"
+	"goblog/pkg/route"
+	"goblog/routes"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func apiSpec(t *testing.T) (*openapi.Document, *mux.Router) {
+	router := mux.NewRouter()
+	routes.RegisterAPIRoutes(router)
+	route.SetRoute(router)
+
+	doc, err := controllers.APISpec(router)
+	assert.NoError(t, err)
+	return doc, router
+}
+
+func TestOpenAPICoversAPIRoutes(t *testing.T) {
+	doc, router := apiSpec(t)
+	assert.Equal(t, "3.1.0", doc.OpenAPI)
+
+	// 每个注册的 API 路由都必须出现在文档中
+	variable := regexp.MustCompile(`\{([^:}]+)(:[^}]+)?\}`)
+	count := 0
+	router.Walk(func(r *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tpl, err := r.GetPathTemplate()
+		methods, errMethods := r.GetMethods()
+		if err != nil || errMethods != nil || !strings.HasPrefix(tpl, "/api/v1/") {
+			return nil
+		}
+		path := variable.ReplaceAllString(tpl, "{$1}")
+		for _, method := range methods {
+			count++
+			op := doc.Paths[path][strings.ToLower(method)]
+			if assert.NotNil(t, op, "%s %s（%s）没有出现在 API 文档中", method, path, r.GetName()) {
+				assert.Equal(t, r.GetName(), op.OperationID)
+				assert.NotEmpty(t, op.Security, "%s %s 没有说明认证方式", method, path)
+			}
+		}
+		return nil
+	})
+	assert.True(t, count > 0)
+}
+
+func TestOpenAPIValidationRules(t *testing.T) {
+	doc, _ := apiSpec(t)
+
+	// min_cn、max_cn 转换为长度限制，in 转换为枚举
+	store := doc.Paths["/api/v1/articles"]["post"]
+	body := store.RequestBody.Content["application/json"].Schema
+	title := body.Properties["title"]
+	if assert.NotNil(t, title.MinLength) && assert.NotNil(t, title.MaxLength) {
+		assert.Equal(t, 3, *title.MinLength)
+		assert.Equal(t, 40, *title.MaxLength)
+	}
+	assert.Equal(t, []string{"draft", "published", "scheduled", "archived"}, body.Properties["status"].Enum)
+	// status 未提供时默认为草稿，不是必填项
+	assert.ElementsMatch(t, []string{"title", "body"}, body.Required)
+	assert.Contains(t, store.Responses, "201")
+	assert.Contains(t, store.Responses, "422")
+
+	// 修改时所有字段可选，但约束不变
+	update := doc.Paths["/api/v1/articles/{id}"]["patch"].RequestBody.Content["application/json"].Schema
+	assert.Empty(t, update.Required)
+	assert.Equal(t, 40, *update.Properties["title"].MaxLength)
+
+	// 路径参数和分页列表
+	show := doc.Paths["/api/v1/articles/{id}"]["get"]
+	assert.Equal(t, "id", show.Parameters[0].Name)
+	assert.True(t, show.Parameters[0].Schema.Is("integer"))
+	index := doc.Paths["/api/v1/articles"]["get"].Responses["200"].Content["application/json"].Schema
+	assert.Equal(t, "#/components/schemas/ViewData", index.Properties["meta"].Ref)
+	assert.Contains(t, doc.Components.Schemas, "ArticleResource")
+}
+
+func TestArticleRulesAreCopies(t *testing.T) {
+	// 修改返回的规则不影响之后的验证
+	rules := requests.ArticleRules()
+	delete(rules, "title")
+	assert.Contains(t, requests.ArticleRules(), "title")
+	assert.Contains(t, requests.ArticleRules()["status"], "required")
+	assert.NotContains(t, requests.APIArticleRules()["status"], "required")
+
+	// API 发布文章时可以不提供 status，默认为草稿
+	title, body := "文章标题", "文章内容需要至少十个字"
+	_article := article.Article{Status: article.StatusDraft}
+	errs := requests.ArticleInput{Title: &title, Body: &body}.Apply(&_article)
+	assert.Empty(t, errs)
+	assert.Equal(t, article.StatusDraft, _article.Status)
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"goblog/app/http/middlewares"
+	"goblog/app/models/user"
+	"goblog/app/models/usersession"
+	"goblog/pkg/auth"
+	"goblog/pkg/session"
+	"goblog/pkg/token"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRememberTokenRotation(t *testing.T) {
+	db := setupDB(t)
+	alice := createUser(t, "alice")
+
+	// 1. Cookie 的值为「选择符:验证码」，数据库只保存验证码的哈希值
+	value, err := usersession.CreateRememberToken(alice.ID, 0, time.Hour)
+	require.NoError(t, err)
+	parts := strings.SplitN(value, ":", 2)
+	require.Len(t, parts, 2)
+	var stored usersession.RememberToken
+	require.NoError(t, db.Where("selector = ?", parts[0]).First(&stored).Error)
+	assert.Equal(t, token.Hash(parts[1]), stored.ValidatorHash)
+
+	// 2. 使用后更换验证码
+	rt, rotated, err := usersession.ConsumeRememberToken(value, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, alice.ID, rt.UserID)
+	assert.NotEmpty(t, rotated)
+	assert.NotEqual(t, value, rotated)
+	assert.True(t, strings.HasPrefix(rotated, parts[0]+":"))
+
+	// 3. 宽限时间内使用旧验证码仍然有效，但不再更换
+	_, again, err := usersession.ConsumeRememberToken(value, time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, again)
+
+	// 4. 新的验证码可以继续使用
+	_, rotated, err = usersession.ConsumeRememberToken(rotated, time.Hour)
+	require.NoError(t, err)
+	assert.NotEmpty(t, rotated)
+}
+
+func TestRememberTokenInvalid(t *testing.T) {
+	db := setupDB(t)
+	alice := createUser(t, "alice")
+
+	// 1. 格式错误或不存在
+	for _, value := range []string{"", "abc", "abc:def", strings.Repeat("a", 24) + ":def"} {
+		_, _, err := usersession.ConsumeRememberToken(value, time.Hour)
+		assert.Equal(t, usersession.ErrInvalidRememberToken, err, value)
+	}
+
+	// 2. 已过期的令牌无效，并由 PruneRememberTokens 删除
+	expired, err := usersession.CreateRememberToken(alice.ID, 0, -time.Minute)
+	require.NoError(t, err)
+	_, _, err = usersession.ConsumeRememberToken(expired, time.Hour)
+	assert.Equal(t, usersession.ErrInvalidRememberToken, err)
+	require.NoError(t, usersession.PruneRememberTokens())
+	assert.Zero(t, countRows(t, db, "remember_tokens", alice.ID))
+}
+
+func TestRememberTokenTheft(t *testing.T) {
+	db := setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	stolen, err := usersession.CreateRememberToken(alice.ID, 0, time.Hour)
+	require.NoError(t, err)
+	_, err = usersession.CreateRememberToken(alice.ID, 0, time.Hour)
+	require.NoError(t, err)
+	_, err = usersession.CreateRememberToken(bob.ID, 0, time.Hour)
+	require.NoError(t, err)
+	createDevice(t, alice.ID, "10.0.0.1")
+	createDevice(t, bob.ID, "10.0.0.2")
+
+	// 1. 盗用者先使用了令牌，超过宽限时间后原用户使用旧验证码
+	_, _, err = usersession.ConsumeRememberToken(stolen, time.Hour)
+	require.NoError(t, err)
+	selector := strings.SplitN(stolen, ":", 2)[0]
+	require.NoError(t, db.Model(&usersession.RememberToken{}).Where("selector = ?", selector).
+		UpdateColumn("rotated_at", time.Now().Add(-2*time.Minute)).Error)
+
+	rt, value, err := usersession.ConsumeRememberToken(stolen, time.Hour)
+	assert.Equal(t, usersession.ErrRememberTokenTheft, err)
+	assert.Equal(t, alice.ID, rt.UserID)
+	assert.Empty(t, value)
+
+	// 2. 删除该用户的所有令牌并退出所有设备，其他用户不受影响
+	assert.Zero(t, countRows(t, db, "remember_tokens", alice.ID))
+	assert.Zero(t, countRows(t, db, "user_sessions", alice.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "remember_tokens", bob.ID))
+	assert.Equal(t, int64(1), countRows(t, db, "user_sessions", bob.ID))
+
+	// 3. 验证码错误同样视为盗用
+	bobValue, err := usersession.CreateRememberToken(bob.ID, 0, time.Hour)
+	require.NoError(t, err)
+	forged := strings.SplitN(bobValue, ":", 2)[0] + ":forged"
+	_, _, err = usersession.ConsumeRememberToken(forged, time.Hour)
+	assert.Equal(t, usersession.ErrRememberTokenTheft, err)
+	assert.Zero(t, countRows(t, db, "remember_tokens", bob.ID))
+}
+
+// rememberRequest 只带「记住我」Cookie 发起请求，返回是否已登录、响应中的「记住我」Cookie 和会话 Cookie
+func rememberRequest(value string) (bool, *http.Cookie, *http.Cookie) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "remember_token", Value: value})
+	rec := httptest.NewRecorder()
+	logined := false
+	middlewares.StartSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logined = auth.Check(r)
+	})).ServeHTTP(rec, req)
+
+	var rememberCookie, sessionCookie *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		switch c.Name {
+		case "remember_token":
+			rememberCookie = c
+		case session.Name:
+			sessionCookie = c
+		}
+	}
+	return logined, rememberCookie, sessionCookie
+}
+
+// sessionRequest 只带会话 Cookie 发起请求，返回是否已登录
+func sessionRequest(cookie *http.Cookie) bool {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+	logined := false
+	middlewares.StartSession(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logined = auth.Check(r)
+	})).ServeHTTP(httptest.NewRecorder(), req)
+	return logined
+}
+
+func TestLoginFromRemember(t *testing.T) {
+	db := setupDB(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	alice := createUser(t, "alice")
+	oldDevice, _ := createDevice(t, alice.ID, "10.0.0.1")
+	value, err := usersession.CreateRememberToken(alice.ID, oldDevice.ID, time.Hour)
+	require.NoError(t, err)
+
+	// 1. 自动登录，更换令牌，令牌关联到新的登录设备，旧设备被退出
+	logined, cookie, sessionCookie := rememberRequest(value)
+	assert.True(t, logined)
+	require.NotNil(t, cookie)
+	assert.NotEqual(t, value, cookie.Value)
+	assert.Positive(t, cookie.MaxAge)
+
+	devices, err := usersession.GetDevicesByUser(alice.ID)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.NotEqual(t, oldDevice.ID, devices[0].ID)
+	var rt usersession.RememberToken
+	require.NoError(t, db.Where("user_id = ?", alice.ID).First(&rt).Error)
+	assert.Equal(t, devices[0].ID, rt.DeviceID)
+
+	// 2. 超过宽限时间后使用旧令牌视为盗用，不登录并删除 Cookie 和所有令牌
+	require.NoError(t, db.Model(&rt).UpdateColumn("rotated_at", time.Now().Add(-2*time.Minute)).Error)
+	logined, cookie, _ = rememberRequest(value)
+	assert.False(t, logined)
+	require.NotNil(t, cookie)
+	assert.Negative(t, cookie.MaxAge)
+	assert.Zero(t, countRows(t, db, "remember_tokens", alice.ID))
+
+	// 先用令牌登录的一方（可能是盗用者）的会话同时失效
+	require.NotNil(t, sessionCookie)
+	assert.False(t, sessionRequest(sessionCookie))
+
+	// 3. 被封禁的用户不再自动登录，令牌被删除
+	value, err = usersession.CreateRememberToken(alice.ID, 0, time.Hour)
+	require.NoError(t, err)
+	now := time.Now()
+	require.NoError(t, db.Model(&user.User{}).Where("id = ?", alice.ID).UpdateColumn("banned_at", &now).Error)
+	logined, cookie, _ = rememberRequest(value)
+	assert.False(t, logined)
+	require.NotNil(t, cookie)
+	assert.Negative(t, cookie.MaxAge)
+	assert.Zero(t, countRows(t, db, "remember_tokens", alice.ID))
+}
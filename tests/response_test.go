@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"goblog/app/http/controllers"
+	"goblog/pkg/response"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestResponseValidationError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.ValidationError(rec, map[string][]string{"title": {"标题为必填项"}})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "application/json")
+
+	var body struct {
+		Message string              `json:"message"`
+		Errors  map[string][]string `json:"errors"`
+	}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.NotEmpty(t, body.Message)
+	assert.Equal(t, []string{"标题为必填项"}, body.Errors["title"])
+}
+
+func TestResponseNoContent(t *testing.T) {
+	rec := httptest.NewRecorder()
+	response.JSON(rec, http.StatusNoContent, nil)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	response.Error(rec, http.StatusNotFound, "资源未找到")
+	assert.JSONEq(t, `{"message":"资源未找到"}`, rec.Body.String())
+}
+
+func TestResponseForSQLError(t *testing.T) {
+	var bc controllers.BaseController
+
+	rec := httptest.NewRecorder()
+	bc.ResponseForSQLError(rec, gorm.ErrRecordNotFound)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	// 数据库错误返回 500，而不是退出进程
+	rec = httptest.NewRecorder()
+	bc.ResponseForSQLError(rec, errors.New("database is closed"))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
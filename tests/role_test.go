@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/comment"
+	"goblog/app/models/role"
+	"goblog/app/models/user"
+	"goblog/app/policies"
+	"goblog/bootstrap"
+	"goblog/pkg/auth"
+	"goblog/pkg/config"
+	"goblog/pkg/csrf"
+	"goblog/pkg/model"
+	"goblog/pkg/route"
+	"goblog/pkg/session"
+	"goblog/routes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createUserWithRole 创建指定角色的用户
+func createUserWithRole(t *testing.T, name, roleName string) user.User {
+	_user := createUser(t, name)
+	_role, err := role.GetByName(roleName)
+	require.NoError(t, err)
+	require.NoError(t, model.DB.Model(&_user).UpdateColumn("role_id", _role.ID).Error)
+	_user.RoleID = _role.ID
+	return _user
+}
+
+func TestMigrateDoesNotPromoteUsers(t *testing.T) {
+	db := setupDB(t)
+	first := createUser(t, "alice")
+	createUser(t, "bob")
+
+	// 1. 没有管理员时也不会自动提升最早注册的用户
+	bootstrap.Migrate(db)
+	first, err := user.Get(first.GetStringID())
+	require.NoError(t, err)
+	assert.False(t, first.HasPermission("admin.access"))
+
+	// 2. 通过 auth.admin_email 明确指定管理员
+	config.Viper.Set("auth.admin_email", "bob@example.com")
+	t.Cleanup(func() { config.Viper.Set("auth.admin_email", "") })
+	bootstrap.Migrate(db)
+
+	var admins []user.User
+	admin, err := role.GetByName(role.Admin)
+	require.NoError(t, err)
+	require.NoError(t, model.DB.Where("role_id = ?", admin.ID).Find(&admins).Error)
+	if assert.Len(t, admins, 1) {
+		assert.Equal(t, "bob", admins[0].Name)
+	}
+}
+
+func TestRoleDefaults(t *testing.T) {
+	setupDB(t)
+
+	// 新用户使用默认角色
+	alice := createUser(t, "alice")
+	author, err := role.GetByName(role.Author)
+	require.NoError(t, err)
+	assert.Equal(t, author.ID, alice.RoleID)
+
+	// 管理员拥有全部权限，读者没有任何权限
+	admin, err := role.GetByName(role.Admin)
+	require.NoError(t, err)
+	assert.Contains(t, admin.PermissionNames(), "admin.access")
+	assert.Contains(t, admin.PermissionNames(), "tag.manage")
+	reader, err := role.GetByName(role.Reader)
+	require.NoError(t, err)
+	assert.Empty(t, reader.PermissionNames())
+}
+
+func TestGateOwnOrAny(t *testing.T) {
+	setupDB(t)
+	author := createUserWithRole(t, "alice", role.Author)
+	other := createUserWithRole(t, "bob", role.Author)
+	editor := createUserWithRole(t, "carol", role.Editor)
+	reader := createUserWithRole(t, "dave", role.Reader)
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+
+	// 1. 作者只能编辑自己的文章，编辑可以编辑所有文章，读者不能编辑
+	assert.True(t, policies.AllowsUser(author, "article.update", _article))
+	assert.False(t, policies.AllowsUser(other, "article.update", _article))
+	assert.True(t, policies.AllowsUser(editor, "article.update", _article))
+	assert.False(t, policies.AllowsUser(reader, "article.update", _article))
+
+	// 2. 未指定资源时只检查是否拥有其中一项权限
+	assert.True(t, policies.AllowsUser(other, "article.update"))
+	assert.False(t, policies.AllowsUser(reader, "article.update"))
+
+	// 3. 评论由文章作者审核
+	_comment := comment.Comment{ArticleID: _article.ID, Article: _article}
+	assert.True(t, policies.AllowsUser(author, "comment.moderate", _comment))
+	assert.False(t, policies.AllowsUser(other, "comment.moderate", _comment))
+	assert.True(t, policies.AllowsUser(editor, "comment.moderate", &_comment))
+
+	// 4. 未声明规则的权限按角色权限判断
+	assert.True(t, policies.AllowsUser(editor, "tag.manage"))
+	assert.False(t, policies.AllowsUser(author, "tag.manage"))
+	assert.False(t, policies.AllowsUser(editor, "admin.access"))
+	assert.False(t, policies.AllowsUser(user.User{}, "article.create"))
+}
+
+func TestRolePermissionChangesFlushCache(t *testing.T) {
+	setupDB(t)
+	author := createUserWithRole(t, "alice", role.Author)
+	assert.False(t, policies.AllowsUser(author, "tag.manage"))
+
+	// 修改角色权限后立即生效
+	_role, err := role.GetByName(role.Author)
+	require.NoError(t, err)
+	require.NoError(t, _role.SyncPermissions(append(_role.PermissionNames(), "tag.manage")))
+	assert.True(t, policies.AllowsUser(author, "tag.manage"))
+
+	require.NoError(t, _role.SyncPermissions(nil))
+	assert.False(t, policies.AllowsUser(author, "article.create"))
+}
+
+func TestArticleDeleteRequiresVerifiedEmail(t *testing.T) {
+	db := setupDB(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	author := createUserWithRole(t, "alice", role.Author)
+	require.NoError(t, db.Model(&author).UpdateColumn("email_verified_at", nil).Error)
+	_article := createArticle(t, author.ID, "hello", article.StatusPublished, nil)
+
+	// 1. 登录并取得 CSRF 令牌
+	rec := httptest.NewRecorder()
+	r := session.Start(rec, httptest.NewRequest("POST", "/auth/login", nil))
+	require.NoError(t, auth.Login(r, author))
+	form := url.Values{csrf.FieldName: {csrf.Token(r)}}
+	cookies := rec.Result().Cookies()
+	cookies = cookies[len(cookies)-1:]
+
+	remove := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/articles/"+_article.GetStringID()+"/delete", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 2. 未验证邮箱时跳转到验证提示页面，文章不会被删除
+	rec = remove()
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, route.Name2URL("auth.verify.notice"), rec.Header().Get("Location"))
+	_, err := article.Get(_article.GetStringID())
+	assert.NoError(t, err)
+
+	// 3. 验证邮箱后可以删除
+	require.NoError(t, db.Model(&author).UpdateColumn("email_verified_at", time.Now()).Error)
+	remove()
+	_, err = article.Get(_article.GetStringID())
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package tests
+
+import (
+	"goblog/pkg/scheduler"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerDisablesNonPositiveInterval(t *testing.T) {
+	called := false
+	job := func() error {
+		called = true
+		return nil
+	}
+
+	// 间隔为 0 或负数时不注册，启动后也不会因 NewTicker 而 panic
+	assert.False(t, scheduler.Every("test.zero", 0, job))
+	assert.False(t, scheduler.Every("test.negative", -1, job))
+	assert.NotPanics(t, func() {
+		scheduler.Start()
+		scheduler.Stop()
+	})
+	assert.False(t, called)
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"goblog/app/models/article"
+	"goblog/pkg/search"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchQueryTerms(t *testing.T) {
+	assert.Equal(t, []string{"go", "语言", "言入", "入门"}, search.QueryTerms("Go 语言入门"))
+	assert.Equal(t, []string{"run", "test"}, search.QueryTerms("Running the tests"))
+	assert.Equal(t, []string{"语"}, search.QueryTerms("语"))
+}
+
+func TestSearchIndex(t *testing.T) {
+	idx := search.NewIndex()
+	now := time.Now()
+
+	idx.Add(search.Document{ID: 1, Title: "Go 语言入门", Body: "介绍基础语法", Time: now})
+	idx.Add(search.Document{ID: 2, Title: "数据库优化", Body: "在 Go 语言中使用 MySQL", Time: now})
+	idx.Add(search.Document{ID: 3, Title: "前端开发", Body: "JavaScript 教程", Time: now})
+
+	// 1. 标题命中的文档排在前面
+	hits := idx.Search("go 语言")
+	assert.Len(t, hits, 2)
+	assert.Equal(t, uint64(1), hits[0].ID)
+	assert.Equal(t, uint64(2), hits[1].ID)
+
+	// 2. 更新和删除
+	idx.Add(search.Document{ID: 3, Title: "前端开发", Body: "用 Go 语言写 WebAssembly", Time: now})
+	assert.Len(t, idx.Search("语言"), 3)
+	idx.Remove(1)
+	assert.Len(t, idx.Search("语言"), 2)
+	assert.Equal(t, 2, idx.Len())
+	assert.Empty(t, idx.Search("入门"))
+}
+
+func TestSearchHighlight(t *testing.T) {
+	assert.Equal(t, "学习 <mark>Go</mark> <mark>语言</mark> &lt;入门&gt;",
+		string(search.Highlight("学习 Go 语言 <入门>", "go 语言", 0)))
+
+	snippet := string(search.Highlight("一二三四五六七八九十关键词一二三四五六七八九十", "关键词", 8))
+	assert.Equal(t, "…九十<mark>关键词</mark>一二三…", snippet)
+}
+
+func TestSearchIndexFollowsCommittedArticles(t *testing.T) {
+	db := setupDB(t)
+	author := createUser(t, "alice")
+
+	// 1. 创建并发布后加入索引
+	_article := createArticle(t, author.ID, "Go 语言入门", article.StatusPublished, nil)
+	if hits := search.Default.Search("入门"); assert.Len(t, hits, 1) {
+		assert.Equal(t, _article.ID, hits[0].ID)
+	}
+
+	// 2. 保存标签失败导致回滚时，索引保持原样
+	require.NoError(t, db.Migrator().DropTable("article_tags"))
+	_article.Title, _article.Body = "数据库优化", "索引和查询"
+	_article.SetTags([]string{"数据库"})
+	_, err := _article.Update(author.ID)
+	assert.Error(t, err)
+	assert.Len(t, search.Default.Search("入门"), 1)
+	assert.Empty(t, search.Default.Search("数据库"))
+
+	// 3. 更新成功后索引随之更新，删除后移除
+	require.NoError(t, db.AutoMigrate(&article.Article{}))
+	_, err = _article.Update(author.ID)
+	require.NoError(t, err)
+	assert.Empty(t, search.Default.Search("入门"))
+	assert.Len(t, search.Default.Search("数据库"), 1)
+
+	_, err = _article.Delete()
+	require.NoError(t, err)
+	assert.Zero(t, search.Default.Len())
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"goblog/app/models/article"
+	_ "goblog/config"
+	"goblog/pkg/config"
+	"goblog/pkg/route"
+	"goblog/pkg/seo"
+	"goblog/pkg/sitemap"
+	"goblog/routes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSEOJSONLD(t *testing.T) {
+	published := time.Date(2021, 5, 1, 8, 0, 0, 0, time.UTC)
+	m := seo.Meta{SiteName: "GoBlog", Post: &seo.BlogPosting{
+		Headline:   "</script><script>alert(1)</script>",
+		URL:        "http://localhost:3000/articles/hello",
+		AuthorName: "alice",
+		Keywords:   []string{"go", "web"},
+		Published:  published,
+		Modified:   published.Add(time.Hour),
+	}}
+
+	// 标题中的 </script> 被转义，不会提前结束脚本
+	ld := string(m.JSONLD())
+	assert.NotContains(t, ld, "</script>")
+
+	var doc map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(ld), &doc))
+	assert.Equal(t, "BlogPosting", doc["@type"])
+	assert.Equal(t, "</script><script>alert(1)</script>", doc["headline"])
+	assert.Equal(t, "2021-05-01T08:00:00Z", doc["datePublished"])
+	assert.Equal(t, "2021-05-01T09:00:00Z", doc["dateModified"])
+	assert.Equal(t, "go, web", doc["keywords"])
+	assert.Equal(t, "alice", doc["author"].(map[string]interface{})["name"])
+
+	// 非文章页面没有结构化数据
+	assert.Empty(t, seo.Meta{}.JSONLD())
+}
+
+func TestSEORobots(t *testing.T) {
+	disallow := seo.ParsePaths(" /admin, /api/ ,,")
+	assert.Equal(t, []string{"/admin", "/api/"}, disallow)
+	assert.True(t, seo.Disallowed("/admin/users", disallow))
+	assert.False(t, seo.Disallowed("/articles/hello", disallow))
+
+	assert.Equal(t, "User-agent: *\nDisallow: /admin\nDisallow: /api/\n\nSitemap: http://localhost:3000/sitemap.xml\n",
+		seo.Robots(disallow, "http://localhost:3000/sitemap.xml"))
+	assert.True(t, strings.HasPrefix(seo.Robots(nil, ""), "User-agent: *\nDisallow:\n"))
+}
+
+func TestSitemap(t *testing.T) {
+	body, err := sitemap.URLSet{
+		{Loc: "http://localhost:3000/"},
+		{Loc: "http://localhost:3000/articles/a?x=1&y=2", LastMod: time.Date(2021, 5, 1, 16, 0, 0, 0, time.FixedZone("CST", 8*3600))},
+	}.Encode()
+	assert.NoError(t, err)
+
+	var set struct {
+		XMLName xml.Name `xml:"http://www.sitemaps.org/schemas/sitemap/0.9 urlset"`
+		URLs    []struct {
+			Loc     string `xml:"loc"`
+			LastMod string `xml:"lastmod"`
+		} `xml:"url"`
+	}
+	assert.NoError(t, xml.Unmarshal(body, &set))
+	if assert.Len(t, set.URLs, 2) {
+		assert.Empty(t, set.URLs[0].LastMod)
+		assert.Equal(t, "http://localhost:3000/articles/a?x=1&y=2", set.URLs[1].Loc)
+		assert.Equal(t, "2021-05-01T08:00:00Z", set.URLs[1].LastMod)
+	}
+
+	body, err = sitemap.Index{{Loc: "http://localhost:3000/sitemaps/pages.xml"}}.Encode()
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), "<sitemapindex xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">")
+
+	// 按容量拆分
+	assert.Equal(t, 1, sitemap.Chunks(0, 100))
+	assert.Equal(t, 1, sitemap.Chunks(100, 100))
+	assert.Equal(t, 2, sitemap.Chunks(101, 100))
+	assert.Equal(t, sitemap.MaxURLs, sitemap.Size(0))
+	assert.Equal(t, sitemap.MaxURLs, sitemap.Size(sitemap.MaxURLs+1))
+}
+
+func TestSEORoutes(t *testing.T) {
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+
+	for path, name := range map[string]string{
+		"/sitemap.xml":              "seo.sitemap",
+		"/sitemaps/pages.xml":       "seo.sitemap.pages",
+		"/sitemaps/articles-12.xml": "seo.sitemap.articles",
+		"/robots.txt":               "seo.robots",
+	} {
+		var match mux.RouteMatch
+		if assert.True(t, router.Match(httptest.NewRequest("GET", path, nil), &match), path) {
+			assert.Equal(t, name, match.Route.GetName(), path)
+		}
+	}
+	assert.Equal(t, "http://localhost:3000/sitemaps/articles-2.xml", route.Name2URL("seo.sitemap.articles", "page", "2"))
+}
+
+func TestSitemapPagesListAuthorsWithPublishedArticles(t *testing.T) {
+	setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+
+	// 每段一个作者，验证分段查询不会遗漏
+	size := config.Get("seo.sitemap_size")
+	config.Viper.Set("seo.sitemap_size", 1)
+	t.Cleanup(func() { config.Viper.Set("seo.sitemap_size", size) })
+
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+	carol := createUser(t, "carol")
+	dave := createUser(t, "dave")
+	createArticle(t, alice.ID, "alice post", article.StatusPublished, nil)
+	createArticle(t, bob.ID, "bob draft", article.StatusDraft, nil)
+	createArticle(t, dave.ID, "dave post", article.StatusPublished, nil)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/sitemaps/pages.xml", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<loc>"+alice.Link()+"</loc>")
+	assert.Contains(t, body, "<loc>"+dave.Link()+"</loc>")
+	assert.NotContains(t, body, "<loc>"+bob.Link()+"</loc>")
+	assert.NotContains(t, body, "<loc>"+carol.Link()+"</loc>")
+}
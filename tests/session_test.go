@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"fmt"
+	"goblog/pkg/flash"
+	"goblog/pkg/session"
+	"io/ioutil"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSessionConcurrentRequests 不同用户的并发请求各自读写自己的会话，使用 go test -race 运行
+func TestSessionConcurrentRequests(t *testing.T) {
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		uid := r.URL.Query().Get("uid")
+		session.Put(r, "uid", uid)
+		flash.Success(r, "welcome "+uid)
+
+		// 让出调度，使其他请求在读写之间穿插执行
+		runtime.Gosched()
+
+		if got, _ := session.Get(r, "uid").(string); got != uid {
+			http.Error(w, got, http.StatusConflict)
+		}
+	})
+	mux.HandleFunc("/me", func(w http.ResponseWriter, r *http.Request) {
+		uid, _ := session.Get(r, "uid").(string)
+		fmt.Fprintf(w, "%s|%v", uid, flash.All(r)["success"])
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, session.Start(w, r))
+	}))
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	for i := 1; i <= 50; i++ {
+		wg.Add(1)
+		go func(uid string) {
+			defer wg.Done()
+
+			jar, _ := cookiejar.New(nil)
+			client := &http.Client{Jar: jar}
+
+			resp, err := client.Get(server.URL + "/login?uid=" + uid)
+			if !assert.NoError(t, err) {
+				return
+			}
+			resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode, "用户 %s 登录时读到了其他用户的会话", uid)
+
+			resp, err = client.Get(server.URL + "/me")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			body, _ := ioutil.ReadAll(resp.Body)
+			assert.Equal(t, uid+"|welcome "+uid, string(body))
+		}(fmt.Sprint(i))
+	}
+	wg.Wait()
+}
+
+func TestSessionServerStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sessions")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	handler, err := session.NewFileHandler(dir)
+	assert.NoError(t, err)
+	session.Store = session.NewServerStore(handler, time.Hour, []byte("hash-key"), []byte("0123456789abcdef0123456789abcdef"))
+
+	// 每次请求带上上一次响应的 Cookie
+	var cookies []*http.Cookie
+	do := func(handle func(r *http.Request)) {
+		req := httptest.NewRequest("GET", "/", nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		handle(session.Start(rec, req))
+		if result := rec.Result().Cookies(); len(result) > 0 {
+			cookies = result[len(result)-1:]
+		}
+	}
+
+	do(func(r *http.Request) { session.Put(r, "uid", "1") })
+	oldCookie := cookies[0]
+	assert.NotContains(t, oldCookie.Value, "uid")
+
+	// 重新生成 ID 后数据保留，旧的会话 ID 失效
+	do(func(r *http.Request) {
+		assert.Equal(t, "1", session.Get(r, "uid"))
+		session.Regenerate(r)
+	})
+	assert.NotEqual(t, oldCookie.Value, cookies[0].Value)
+	do(func(r *http.Request) { assert.Equal(t, "1", session.Get(r, "uid")) })
+
+	cookies = []*http.Cookie{oldCookie}
+	do(func(r *http.Request) { assert.Nil(t, session.Get(r, "uid")) })
+
+	// 过期的会话由 GC 删除
+	expired, err := session.NewFileHandler(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, expired.Write("EXPIRED", []byte("data"), time.Now().Add(-time.Minute)))
+	data, _ := expired.Read("EXPIRED")
+	assert.Nil(t, data)
+	deleted, err := session.GC()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+func TestSessionEncryptionKey(t *testing.T) {
+	// 1. 未设置加密密钥时由 app.key 派生，不同的 app.key 派生出不同的密钥
+	derived := session.EncryptionKey("", "app-key")
+	assert.Len(t, derived, 32)
+	assert.Equal(t, derived, session.EncryptionKey("", "app-key"))
+	assert.NotEqual(t, derived, session.EncryptionKey("", "other-key"))
+
+	// 2. 设置了加密密钥时与 app.key 无关
+	configured := session.EncryptionKey("encryption-key", "app-key")
+	assert.Len(t, configured, 32)
+	assert.Equal(t, configured, session.EncryptionKey("encryption-key", "other-key"))
+	assert.NotEqual(t, derived, configured)
+}
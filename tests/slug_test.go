@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"goblog/app/models/category"
+	"goblog/pkg/route"
+	"goblog/pkg/session"
+	"goblog/pkg/slug"
+	"goblog/routes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/sessions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlugMake(t *testing.T) {
+	assert.Equal(t, "go-yu-yan-ru-men", slug.Make("Go 语言入门"))
+	assert.Equal(t, "hello-world-2021", slug.Make("Hello, World! 2021"))
+	assert.Equal(t, "", slug.Make("！？"))
+}
+
+func TestSlugSafe(t *testing.T) {
+	assert.Equal(t, "article", slug.Safe("", "article"))
+	assert.Equal(t, "article-2021", slug.Safe("2021", "article"))
+	assert.Equal(t, "article-create", slug.Safe("create", "article", "create"))
+	assert.Equal(t, "go-yu-yan", slug.Safe("go-yu-yan", "article", "create"))
+}
+
+func TestSlugMatches(t *testing.T) {
+	assert.True(t, slug.Matches("go-yu-yan", "go-yu-yan"))
+	assert.True(t, slug.Matches("go-yu-yan-2", "go-yu-yan"))
+	assert.False(t, slug.Matches("go-yu-yan-ru-men", "go-yu-yan"))
+}
+
+func TestCategoryOldSlugRedirect(t *testing.T) {
+	setupDB(t)
+	session.Store = sessions.NewCookieStore([]byte("test-key"))
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+
+	// 分类页面需要登录后访问
+	cookies := loginCookies(t, createUser(t, "alice"))
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	news := category.Category{Name: "新闻"}
+	require.NoError(t, news.Create())
+	assert.Equal(t, "xin-wen", news.Slug)
+
+	// 1. 修改名称后生成新 slug，旧链接 301 跳转到新链接
+	news.Name = "资讯"
+	_, err := news.Update()
+	require.NoError(t, err)
+	assert.Equal(t, "zi-xun", news.Slug)
+
+	rec := get("/categories/xin-wen")
+	assert.Equal(t, http.StatusMovedPermanently, rec.Code)
+	assert.Equal(t, news.Link(), rec.Header().Get("Location"))
+
+	// 2. 旧 slug 不会被其他分类占用
+	other := category.Category{Name: "新闻"}
+	require.NoError(t, other.Create())
+	assert.Equal(t, "xin-wen-2", other.Slug)
+
+	// 3. 改回原来的名称时恢复原 slug，不再跳转
+	news.Name = "新闻"
+	_, err = news.Update()
+	require.NoError(t, err)
+	assert.Equal(t, "xin-wen", news.Slug)
+	_, err = category.GetByOldSlug("xin-wen")
+	assert.Error(t, err)
+	old, err := category.GetByOldSlug("zi-xun")
+	require.NoError(t, err)
+	assert.Equal(t, news.ID, old.ID)
+
+	// 4. 不存在的 slug 返回 404
+	assert.Equal(t, http.StatusNotFound, get("/categories/missing").Code)
+}
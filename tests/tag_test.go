@@ -0,0 +1,181 @@
+package tests
+
+import (
+	"goblog/app/models/article"
+	"goblog/app/models/tag"
+	"goblog/pkg/route"
+	"goblog/pkg/types"
+	"goblog/routes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// articleTagNames 从数据库重新读取文章的标签名称
+func articleTagNames(t *testing.T, id uint64) []string {
+	_article, err := article.Get(types.Uint64ToString(id))
+	require.NoError(t, err)
+	return tag.Names(_article.Tags)
+}
+
+// createTaggedArticle 创建一篇指定状态的文章并设置标签
+func createTaggedArticle(t *testing.T, userID uint64, title, status string, names ...string) article.Article {
+	_article := article.Article{Title: title, Body: title + " body", UserID: userID, CategoryID: 1}
+	_article.SetStatus(status, nil)
+	_article.SetTags(names)
+	require.NoError(t, _article.Create())
+	return _article
+}
+
+func TestTagParseNames(t *testing.T) {
+	assert.Equal(t, []string{"Go", "web 开发", "数据库"}, tag.ParseNames(" Go ,web   开发，go、数据库,, "))
+	assert.Empty(t, tag.ParseNames(" , ，"))
+}
+
+func TestArticleTagsSavedWithArticle(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+
+	// 1. 创建文章时保存标签，不存在的标签会被创建
+	_article := article.Article{Title: "hello", Body: "hello body", UserID: author.ID, CategoryID: 1}
+	_article.SetStatus(article.StatusPublished, nil)
+	_article.SetTags([]string{"Go", "web"})
+	require.NoError(t, _article.Create())
+	assert.Equal(t, []string{"Go", "web"}, articleTagNames(t, _article.ID))
+	for _, _tag := range _article.Tags {
+		assert.NotZero(t, _tag.ID)
+	}
+
+	// 2. 未调用 SetTags 时更新文章不改动标签
+	loaded, err := article.Get(types.Uint64ToString(_article.ID))
+	require.NoError(t, err)
+	loaded.Title = "hello again"
+	_, err = loaded.Update(author.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go", "web"}, articleTagNames(t, _article.ID))
+
+	// 3. SetTags 后更新文章会替换原有的标签，已存在的标签不会重复创建
+	loaded.SetTags([]string{"web", "数据库"})
+	_, err = loaded.Update(author.ID)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"web", "数据库"}, articleTagNames(t, _article.ID))
+
+	all, err := tag.All()
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestTagFeed(t *testing.T) {
+	setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	author := createUser(t, "alice")
+
+	tagged := article.Article{Title: "tagged", Body: "tagged body", UserID: author.ID, CategoryID: 1}
+	tagged.SetStatus(article.StatusPublished, nil)
+	tagged.SetTags([]string{"golang"})
+	require.NoError(t, tagged.Create())
+	createArticle(t, author.ID, "untagged", article.StatusPublished, nil)
+
+	// 1. 按标签筛选文章
+	articles, err := article.GetForFeed(article.CursorFilter{TagID: tagged.Tags[0].ID}, 10)
+	require.NoError(t, err)
+	if assert.Len(t, articles, 1) {
+		assert.Equal(t, tagged.ID, articles[0].ID)
+	}
+
+	// 2. 标签订阅源只包含标签下的文章
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/tags/"+tagged.Tags[0].Slug+"/feed.json", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "tagged")
+	assert.NotContains(t, rec.Body.String(), "untagged")
+
+	// 3. 不存在的标签返回 404
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", "/tags/missing/feed.json", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestTagRename(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	_article := createTaggedArticle(t, author.ID, "hello", article.StatusPublished, "golang", "web")
+
+	// 1. 名称已被其他标签使用时拒绝重命名
+	golang, web := _article.Tags[0], _article.Tags[1]
+	assert.Equal(t, tag.ErrNameTaken, golang.Rename("web"))
+
+	// 2. 重命名后 slug 随之更新，文章关联保持不变
+	require.NoError(t, golang.Rename("Go 语言"))
+	renamed, err := tag.Get(types.Uint64ToString(golang.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "Go 语言", renamed.Name)
+	assert.NotEqual(t, "golang", renamed.Slug)
+	assert.ElementsMatch(t, []string{"Go 语言", "web"}, articleTagNames(t, _article.ID))
+
+	// 3. 使用原名称重命名不算冲突
+	require.NoError(t, web.Rename("web"))
+}
+
+func TestTagMergeInto(t *testing.T) {
+	setupDB(t)
+	author := createUser(t, "alice")
+	both := createTaggedArticle(t, author.ID, "both", article.StatusPublished, "golang", "go")
+	onlySource := createTaggedArticle(t, author.ID, "only source", article.StatusPublished, "golang")
+	source, target := both.Tags[0], both.Tags[1]
+
+	// 1. 合并后文章关联转到目标标签，同时拥有两个标签的文章不会重复关联
+	require.NoError(t, source.MergeInto(target))
+	assert.Equal(t, []string{"go"}, articleTagNames(t, both.ID))
+	assert.Equal(t, []string{"go"}, articleTagNames(t, onlySource.ID))
+
+	// 2. 原标签被删除
+	_, err := tag.Get(types.Uint64ToString(source.ID))
+	assert.Error(t, err)
+	all, err := tag.All()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, tag.Names(all))
+}
+
+func TestTagCloud(t *testing.T) {
+	setupDB(t)
+	router := mux.NewRouter()
+	routes.RegisterWebRoutes(router)
+	route.SetRoute(router)
+	author := createUser(t, "alice")
+
+	createTaggedArticle(t, author.ID, "one", article.StatusPublished, "golang", "web")
+	createTaggedArticle(t, author.ID, "two", article.StatusPublished, "golang")
+	createTaggedArticle(t, author.ID, "draft", article.StatusDraft, "web", "draft-only")
+
+	// 只统计已发布的文章，按文章数量倒序排列，数量多的权重更高
+	items, err := article.TagCloud(10)
+	require.NoError(t, err)
+	if assert.Len(t, items, 2) {
+		assert.Equal(t, "golang", items[0].Name)
+		assert.Equal(t, int64(2), items[0].Count)
+		assert.Equal(t, "web", items[1].Name)
+		assert.Equal(t, int64(1), items[1].Count)
+		assert.Greater(t, items[0].Weight, items[1].Weight)
+	}
+}
+
+func TestTagErrorsAreReturned(t *testing.T) {
+	db := setupDB(t)
+	tags, err := tag.FirstOrCreate(db, []string{"Go", "web"})
+	require.NoError(t, err)
+
+	// 数据库不可用时返回错误，而不是退出进程
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	require.NoError(t, sqlDB.Close())
+	assert.Error(t, tags[1].Rename("http"))
+	_article := article.Article{Title: "hello", Body: "hello body", CategoryID: 1}
+	assert.Error(t, _article.Create())
+}
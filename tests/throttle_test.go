@@ -0,0 +1,45 @@
+package tests
+
+import (
+	"goblog/pkg/throttle"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleBackoff(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	limiter := throttle.New(throttle.NewMemoryStore(), throttle.Policy{
+		FreeAttempts:    2,
+		BaseDelay:       time.Second,
+		MaxDelay:        4 * time.Second,
+		LockoutAttempts: 6,
+		LockoutDuration: time.Minute,
+		Decay:           time.Hour,
+	})
+	limiter.Now = func() time.Time { return now }
+
+	// 前两次失败不限制，之后等待时间翻倍，最多 MaxDelay，达到 LockoutAttempts 后锁定
+	for _, want := range []time.Duration{0, 0, time.Second, 2 * time.Second, 4 * time.Second, time.Minute} {
+		wait, err := limiter.Fail("account:a@example.com")
+		assert.NoError(t, err)
+		assert.Equal(t, want, wait)
+	}
+
+	wait, _ := limiter.Wait("ip:127.0.0.1", "account:a@example.com")
+	assert.Equal(t, time.Minute, wait)
+
+	now = now.Add(time.Minute)
+	wait, _ = limiter.Wait("account:a@example.com")
+	assert.Equal(t, time.Duration(0), wait)
+
+	// 超过 Decay 后重新计数
+	now = now.Add(2 * time.Hour)
+	wait, _ = limiter.Fail("account:a@example.com")
+	assert.Equal(t, time.Duration(0), wait)
+
+	assert.NoError(t, limiter.Reset("account:a@example.com"))
+	wait, _ = limiter.Wait("account:a@example.com")
+	assert.Equal(t, time.Duration(0), wait)
+}
@@ -0,0 +1,29 @@
+package tests
+
+import (
+	"goblog/pkg/token"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenSign(t *testing.T) {
+	key := []byte("secret")
+	signature := token.Sign(key, "verify", "1", "summer@example.com", "1700000000")
+
+	assert.True(t, token.Verify(key, signature, "verify", "1", "summer@example.com", "1700000000"))
+
+	// 修改任一部分签名均失效
+	assert.False(t, token.Verify(key, signature, "verify", "1", "other@example.com", "1700000000"))
+	assert.False(t, token.Verify(key, signature, "verify", "1", "summer@example.com", "1800000000"))
+	assert.False(t, token.Verify([]byte("other"), signature, "verify", "1", "summer@example.com", "1700000000"))
+}
+
+func TestTokenGenerate(t *testing.T) {
+	a, b := token.Generate(), token.Generate()
+
+	assert.Len(t, a, 64)
+	assert.NotEqual(t, a, b)
+	assert.Equal(t, token.Hash(a), token.Hash(a))
+	assert.NotEqual(t, a, token.Hash(a))
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"goblog/pkg/encrypt"
+	"goblog/pkg/totp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RFC 4226 附录 D 的测试密钥 "12345678901234567890"
+const totpSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	for step, want := range []string{"755224", "287082", "359152", "969429"} {
+		code, err := totp.Code(totpSecret, int64(step))
+		assert.NoError(t, err)
+		assert.Equal(t, want, code)
+	}
+}
+
+func TestTOTPValidate(t *testing.T) {
+	now := time.Unix(59, 0)
+	code, _ := totp.Code(totpSecret, totp.Step(now))
+
+	step, ok := totp.Validate(totpSecret, code, now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), step)
+
+	// 前后一个时间窗口内有效
+	_, ok = totp.Validate(totpSecret, code, now.Add(30*time.Second), 0)
+	assert.True(t, ok)
+	_, ok = totp.Validate(totpSecret, code, now.Add(90*time.Second), 0)
+	assert.False(t, ok)
+
+	// 已使用过的时间窗口不能再次使用
+	_, ok = totp.Validate(totpSecret, code, now, step)
+	assert.False(t, ok)
+
+	_, ok = totp.Validate(totpSecret, "000000", now, 0)
+	assert.False(t, ok)
+}
+
+func TestEncrypt(t *testing.T) {
+	key := []byte("app-key")
+	ciphertext, err := encrypt.Encrypt(key, totpSecret)
+	assert.NoError(t, err)
+	assert.NotContains(t, ciphertext, totpSecret)
+
+	plaintext, err := encrypt.Decrypt(key, ciphertext)
+	assert.NoError(t, err)
+	assert.Equal(t, totpSecret, plaintext)
+
+	_, err = encrypt.Decrypt([]byte("other-key"), ciphertext)
+	assert.Error(t, err)
+}
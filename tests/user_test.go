@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"goblog/app/models/accesstoken"
+	"goblog/app/models/identity"
+	"goblog/app/models/role"
+	"goblog/app/models/user"
+	"goblog/app/models/usersession"
+	"goblog/pkg/oidc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestUserSetRole(t *testing.T) {
+	setupDB(t)
+	alice := createUser(t, "alice")
+	admin, err := role.GetByName(role.Admin)
+	require.NoError(t, err)
+
+	// 1. 角色不存在时不修改
+	rows, err := user.SetRole([]uint64{alice.ID}, 9999)
+	assert.Equal(t, user.ErrRoleNotFound, err)
+	assert.Zero(t, rows)
+
+	// 2. 修改为已有的角色
+	rows, err = user.SetRole([]uint64{alice.ID}, admin.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+	alice, err = user.Get(alice.GetStringID())
+	require.NoError(t, err)
+	assert.Equal(t, admin.ID, alice.RoleID)
+}
+
+func TestUserDeleteRemovesOwnedRecords(t *testing.T) {
+	db := setupDB(t)
+	alice := createUser(t, "alice")
+	bob := createUser(t, "bob")
+
+	// 1. 为两个用户创建外部登录身份、API 令牌、登录设备和「记住我」令牌
+	for i, _user := range []user.User{alice, bob} {
+		_, err := identity.Link(_user.ID, "github", oidc.Claims{Subject: _user.Name, Email: _user.Email})
+		require.NoError(t, err, i)
+		_token := accesstoken.AccessToken{UserID: _user.ID, Name: "cli", Scopes: accesstoken.ScopeRead}
+		_, err = _token.Create()
+		require.NoError(t, err)
+		deviceToken, err := usersession.CreateDevice(_user.ID, "127.0.0.1", "curl/7.0")
+		require.NoError(t, err)
+		device, err := usersession.GetDevice(deviceToken)
+		require.NoError(t, err)
+		_, err = usersession.CreateRememberToken(_user.ID, device.ID, time.Hour)
+		require.NoError(t, err)
+	}
+
+	// 2. 删除用户后，只删除该用户的记录
+	rows, err := alice.Delete()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rows)
+
+	tables := []string{"user_identities", "personal_access_tokens", "user_sessions", "remember_tokens"}
+	for _, table := range tables {
+		assert.Zero(t, countRows(t, db, table, alice.ID), table)
+		assert.Equal(t, int64(1), countRows(t, db, table, bob.ID), table)
+	}
+	_, err = user.Get(alice.GetStringID())
+	assert.Equal(t, gorm.ErrRecordNotFound, err)
+}